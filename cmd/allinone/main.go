@@ -0,0 +1,263 @@
+// Command allinone runs the frontend and the seniority, field and role
+// services in a single process.
+//
+// Every service has a trace provider of its own, so the traces look the same
+// as when the services run as separate processes. The services talk to each
+// other over gRPC, either through loopback listeners or, with -bufconn,
+// through in-memory connections. See package stack.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/johananl/otel-demo/pkg/depgraph"
+	"github.com/johananl/otel-demo/pkg/frontend/tracing"
+	"github.com/johananl/otel-demo/pkg/latency"
+	"github.com/johananl/otel-demo/pkg/metrics"
+	"github.com/johananl/otel-demo/pkg/otlp"
+	"github.com/johananl/otel-demo/pkg/resource"
+	"github.com/johananl/otel-demo/pkg/stack"
+	"github.com/johananl/otel-demo/pkg/tracestore"
+	"github.com/johananl/otel-demo/pkg/traceview"
+	titlepb "github.com/johananl/otel-demo/proto/title"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/exporter/trace/jaeger"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+)
+
+// newTraceProvider returns a trace provider which exports the spans of the
+// given service to Jaeger, or to the collector at otlpEndpoint if set, and
+// to traces if not nil. It also returns a function which sends the spans
+// still queued and closes the exporter.
+func newTraceProvider(service, otlpEndpoint string, traces traceview.Store) (*sdktrace.Provider, func() error) {
+	// Detect the attributes describing this process, e.g. its version and
+	// host. Exporters attach them to spans as the process or resource.
+	res := resource.Detect(service)
+
+	// Send spans to the collector if there is one, which decides which traces
+	// to keep, or straight to Jaeger otherwise.
+	var syncer export.SpanSyncer
+	var closeExporter func() error
+	if otlpEndpoint != "" {
+		exporter, err := otlp.NewExporter(otlpEndpoint, append([]core.KeyValue{
+			key.String("exporter", "otlp"),
+			key.String("mode", "allinone"),
+		}, res...))
+		if err != nil {
+			log.Fatal(err)
+		}
+		syncer, closeExporter = exporter, exporter.Close
+	} else {
+		// Create a Jaeger exporter.
+		exporter, err := jaeger.NewExporter(
+			jaeger.WithCollectorEndpoint("http://localhost:14268/api/traces"),
+			jaeger.WithProcess(jaeger.Process{
+				ServiceName: service,
+				Tags: append([]core.KeyValue{
+					key.String("exporter", "jaeger"),
+					key.String("mode", "allinone"),
+				}, res...),
+			}),
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+		syncer = exporter
+		closeExporter = func() error {
+			exporter.Flush()
+			return nil
+		}
+	}
+
+	tp, err := sdktrace.NewProvider(
+		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.AlwaysSample()}),
+		sdktrace.WithSyncer(syncer),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if traces != nil {
+		tp.RegisterSpanProcessor(sdktrace.NewSimpleSpanProcessor(traceview.NewSyncer(traces, res)))
+	}
+
+	return tp, closeExporter
+}
+
+// stop stops s gracefully, or right away once ctx is done, e.g. because of
+// streams which don't end.
+func stop(ctx context.Context, s *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.Stop()
+	}
+}
+
+func main() {
+	httpAddr := flag.String("http", "localhost:8080", "address of the frontend HTTP server")
+	grpcAddr := flag.String("grpc", "localhost:8081", "address of the frontend gRPC server")
+	uiDir := flag.String("ui", "ui/build", "directory of the UI")
+	inMemory := flag.Bool("bufconn", false, "connect the services in memory instead of over loopback")
+	latencySpec := flag.String("latency", latency.DefaultSpec, "latency profile applied to slow requests by the backends")
+	batchWindow := flag.Duration("batch-window", 0, "window within which fast requests for single titles are batched, 0 disables batching")
+	prefetch := flag.Int("prefetch", 0, "number of titles per locale generated ahead of time, 0 disables prefetching")
+	otlpEndpoint := flag.String("otlp-endpoint", "", "address of the collector spans are sent to over OTLP, e.g. localhost:4317; spans are sent to Jaeger if empty")
+	traceBuffer := flag.Int("trace-buffer", 10000, "number of recent spans kept for the trace viewer on /debug/traces, 0 disables it")
+	traceStore := flag.String("trace-store", "", "directory spans are stored in for the trace viewer, instead of keeping them in memory")
+	traceStoreMaxSize := flag.Int64("trace-store-max-size", 256, "maximum size of the trace store in MiB, 0 means no limit")
+	traceStoreMaxAge := flag.Duration("trace-store-max-age", 24*time.Hour, "maximum age of the spans in the trace store, 0 means no limit")
+	graphWindow := flag.Duration("graph-window", 10*time.Minute, "window of spans the service dependency graph on /debug/graph is derived from")
+	flag.Parse()
+
+	// A graph without a window would grow for as long as the server runs.
+	if *graphWindow <= 0 {
+		log.Fatal("graph window must be positive")
+	}
+
+	profile, err := latency.Parse(*latencySpec)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rand.Seed(time.Now().UTC().UnixNano())
+
+	// Every service records spans with a provider of its own, so that Jaeger
+	// shows them as separate services.
+	// The spans of all services are kept for the trace viewer of the
+	// frontend.
+	var traces traceview.Store
+	var store *tracestore.Store
+	storeCtx, stopStore := context.WithCancel(context.Background())
+	switch {
+	case *traceStore != "":
+		// Keep spans on disk, so that they survive restarts.
+		store, err = tracestore.Open(*traceStore, tracestore.Options{
+			MaxSize: *traceStoreMaxSize << 20,
+			MaxAge:  *traceStoreMaxAge,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		go store.Run(storeCtx, time.Minute)
+		traces = store
+	case *traceBuffer > 0:
+		traces = traceview.NewRing(*traceBuffer)
+	}
+	// Derive the service dependency graph from the spans kept for the trace
+	// viewer as they arrive.
+	var graph *depgraph.Graph
+	if traces != nil {
+		graph = depgraph.New(*graphWindow)
+		traces = depgraph.Record(traces, graph)
+	}
+	providers := map[string]*sdktrace.Provider{}
+	var closeExporters []func() error
+	for _, service := range stack.Services {
+		tp, closeExporter := newTraceProvider(service, *otlpEndpoint, traces)
+		providers[service] = tp
+		closeExporters = append(closeExporters, closeExporter)
+	}
+
+	// Tracers which aren't passed explicitly belong to the frontend.
+	global.SetTraceProvider(providers["frontend"])
+
+	st, err := stack.Start(stack.Config{
+		InMemory:    *inMemory,
+		Latency:     profile,
+		Provider:    func(service string) trace.Provider { return providers[service] },
+		BatchWindow: *batchWindow,
+		Prefetch:    *prefetch,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Started backends (in memory: %v)", *inMemory)
+
+	srv := st.Frontend
+	if traces != nil {
+		srv.SetTraceStore(traces)
+		srv.SetServiceGraph(graph)
+	}
+
+	// The servers report errors on errc, ending the process.
+	errc := make(chan error, 2)
+	hs := &http.Server{Addr: *httpAddr, Handler: srv.Handler(*uiDir)}
+	go func() {
+		if err := hs.ListenAndServe(); err != http.ErrServerClosed {
+			errc <- err
+		}
+	}()
+	log.Printf("Listening for HTTP requests on %s", *httpAddr)
+
+	// Serve titles over gRPC.
+	lis, err := net.Listen("tcp", *grpcAddr)
+	if err != nil {
+		log.Fatalf("cannot listen: %v", err)
+	}
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		tracing.NewUnaryServerInterceptor(st.FrontendTracer),
+		metrics.UnaryServerInterceptor,
+	))
+	titlepb.RegisterTitleServiceServer(s, srv.TitleServer())
+
+	go func() {
+		if err := s.Serve(lis); err != nil {
+			errc <- fmt.Errorf("failed to serve: %v", err)
+		}
+	}()
+	log.Printf("Listening for gRPC connections on %s", *grpcAddr)
+
+	// Shut down on interrupt or termination as well, so that the spans
+	// still queued are exported and the trace store is closed cleanly.
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	var serveErr error
+	select {
+	case serveErr = <-errc:
+		log.Print(serveErr)
+	case sg := <-sig:
+		log.Printf("Received %v, shutting down", sg)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := hs.Shutdown(ctx); err != nil {
+		hs.Close()
+	}
+	stop(ctx, s)
+	st.Close()
+	for _, closeExporter := range closeExporters {
+		if err := closeExporter(); err != nil {
+			log.Printf("Error closing span exporter: %v", err)
+		}
+	}
+	stopStore()
+	if store != nil {
+		if err := store.Close(); err != nil {
+			log.Fatalf("Error closing trace store: %v", err)
+		}
+	}
+	if serveErr != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,247 @@
+// Command collector receives spans from the services over OTLP and applies
+// tail-based sampling to them.
+//
+// The spans of every trace are buffered for a decision window. Once it has
+// passed, the trace is forwarded to the tracing backend only if it failed, was
+// slow or matches an attribute rule. Everything else is dropped, so that the
+// backend stores the interesting traces without the services having to guess
+// which ones they are when a trace starts.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/johananl/otel-demo/pkg/metrics"
+	"github.com/johananl/otel-demo/pkg/otlp"
+	"github.com/johananl/otel-demo/pkg/tailsample"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/exporter/trace/jaeger"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	"google.golang.org/grpc"
+)
+
+// rules collects repeated -keep-attr flags.
+type rules []string
+
+func (r *rules) String() string { return strings.Join(*r, ",") }
+
+func (r *rules) Set(v string) error {
+	*r = append(*r, v)
+	return nil
+}
+
+// forwarder exports kept spans to the backend. The Jaeger exporter describes
+// a single process, so an exporter is created for every service.
+type forwarder struct {
+	backend  string
+	endpoint string
+
+	mu        sync.Mutex
+	exporters map[string]export.SpanSyncer
+}
+
+// Forward exports spans.
+func (f *forwarder) Forward(spans []tailsample.Span) {
+	for _, s := range spans {
+		e, err := f.exporter(s.Resource)
+		if err != nil {
+			log.Printf("Error creating %s exporter: %v", f.backend, err)
+			continue
+		}
+		e.ExportSpan(context.Background(), s.Data)
+	}
+}
+
+// exporter returns the exporter for the service described by res.
+func (f *forwarder) exporter(res []core.KeyValue) (export.SpanSyncer, error) {
+	name := otlp.ServiceName(res)
+	if name == "" {
+		name = "unknown"
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if e, ok := f.exporters[name]; ok {
+		return e, nil
+	}
+
+	var (
+		e   export.SpanSyncer
+		err error
+	)
+	switch f.backend {
+	case "jaeger":
+		e, err = jaeger.NewExporter(
+			jaeger.WithCollectorEndpoint(f.endpoint),
+			jaeger.WithProcess(jaeger.Process{ServiceName: name, Tags: res}),
+		)
+	case "otlp":
+		e, err = otlp.NewExporter(f.endpoint, res)
+	default:
+		err = fmt.Errorf("unknown backend %q", f.backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+	f.exporters[name] = e
+
+	return e, nil
+}
+
+// statsCollector exposes the stats of the sampler as metrics.
+func statsCollector(s *tailsample.Sampler) metrics.Collector {
+	return metrics.CollectorFunc(func() []metrics.Family {
+		st := s.Stats()
+
+		decisions := metrics.Family{
+			Name: "collector_traces_total",
+			Help: "Traces decided on by the tail sampler, by decision and by the policy which kept them.",
+			Type: metrics.Counter,
+			Samples: []metrics.Sample{{
+				Labels: []metrics.Label{{Name: "decision", Value: "dropped"}, {Name: "policy", Value: ""}},
+				Value:  float64(st.Dropped),
+			}},
+		}
+		for _, p := range sortedKeys(st.Kept) {
+			decisions.Samples = append(decisions.Samples, metrics.Sample{
+				Labels: []metrics.Label{{Name: "decision", Value: "kept"}, {Name: "policy", Value: p}},
+				Value:  float64(st.Kept[p]),
+			})
+		}
+
+		return []metrics.Family{
+			{
+				Name:    "collector_spans_received_total",
+				Help:    "Spans received over OTLP.",
+				Type:    metrics.Counter,
+				Samples: []metrics.Sample{{Value: float64(st.Spans)}},
+			},
+			decisions,
+			{
+				Name:    "collector_traces_pending",
+				Help:    "Traces waiting for a sampling decision.",
+				Type:    metrics.Gauge,
+				Samples: []metrics.Sample{{Value: float64(st.Pending)}},
+			},
+			{
+				Name:    "collector_late_spans_total",
+				Help:    "Spans received after the decision on their trace.",
+				Type:    metrics.Counter,
+				Samples: []metrics.Sample{{Value: float64(st.LateSpans)}},
+			},
+		}
+	})
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// logStats logs the stats of the sampler.
+func logStats(s *tailsample.Sampler) {
+	st := s.Stats()
+
+	var kept []string
+	for _, p := range sortedKeys(st.Kept) {
+		kept = append(kept, fmt.Sprintf("%s=%d", p, st.Kept[p]))
+	}
+	log.Printf("Received %d spans, kept %d traces (%s), dropped %d, %d pending, %d late spans",
+		st.Spans, st.KeptTotal(), strings.Join(kept, " "), st.Dropped, st.Pending, st.LateSpans)
+}
+
+func main() {
+	listen := flag.String("listen", "localhost:4317", "address to receive OTLP spans on")
+	httpAddr := flag.String("http", "localhost:8888", "address to serve metrics on")
+	decisionWait := flag.Duration("decision-wait", 5*time.Second, "time the spans of a trace are buffered before deciding on it")
+	latencyThreshold := flag.Duration("latency-threshold", 500*time.Millisecond, "keep traces lasting at least this long, disabled if 0")
+	keepErrors := flag.Bool("keep-errors", true, "keep traces with failed spans")
+	backend := flag.String("backend", "jaeger", "backend kept traces are forwarded to, jaeger or otlp")
+	endpoint := flag.String("backend-endpoint", "http://localhost:14268/api/traces", "endpoint of the backend")
+	statsInterval := flag.Duration("stats-interval", 30*time.Second, "interval at which stats are logged, disabled if 0")
+	var keepAttrs rules
+	flag.Var(&keepAttrs, "keep-attr", "keep traces with a matching span or resource attribute, e.g. http.status=500 or fault; may be repeated")
+	flag.Parse()
+
+	var policies []tailsample.Policy
+	if *keepErrors {
+		policies = append(policies, tailsample.ErrorPolicy{})
+	}
+	if *latencyThreshold > 0 {
+		policies = append(policies, tailsample.LatencyPolicy{Threshold: *latencyThreshold})
+	}
+	for _, rule := range keepAttrs {
+		p, err := tailsample.ParseAttributePolicy(rule)
+		if err != nil {
+			log.Fatal(err)
+		}
+		policies = append(policies, p)
+	}
+	if *backend != "jaeger" && *backend != "otlp" {
+		log.Fatalf("unknown backend %q", *backend)
+	}
+
+	fwd := &forwarder{backend: *backend, endpoint: *endpoint, exporters: map[string]export.SpanSyncer{}}
+	sampler := tailsample.New(tailsample.Config{
+		DecisionWait: *decisionWait,
+		Policies:     policies,
+	}, fwd.Forward)
+	metrics.Default.Register(statsCollector(sampler))
+
+	go sampler.Run(context.Background(), 100*time.Millisecond)
+
+	if *statsInterval > 0 {
+		go func() {
+			for range time.Tick(*statsInterval) {
+				logStats(sampler)
+			}
+		}()
+	}
+
+	lis, err := net.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatalf("cannot listen: %v", err)
+	}
+	s := grpc.NewServer()
+	otlp.NewReceiver(func(res []core.KeyValue, data []*export.SpanData) {
+		spans := make([]tailsample.Span, len(data))
+		for i, d := range data {
+			spans[i] = tailsample.Span{Resource: res, Data: d}
+		}
+		sampler.Add(spans)
+	}).Register(s)
+
+	ch := make(chan struct{})
+	go func(ch chan struct{}) {
+		if err := s.Serve(lis); err != nil {
+			log.Fatalf("failed to serve: %v", err)
+		}
+		ch <- struct{}{}
+	}(ch)
+	log.Printf("Listening for OTLP spans on %s, forwarding kept traces to %s at %s", *listen, *backend, *endpoint)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	go func(ch chan struct{}) {
+		log.Fatal(http.ListenAndServe(*httpAddr, mux))
+		ch <- struct{}{}
+	}(ch)
+	log.Printf("Serving metrics on %s", *httpAddr)
+
+	<-ch
+}
@@ -2,113 +2,190 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"math/rand"
 	"net"
+	"net/http"
+	"os"
 	"time"
 
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/johananl/otel-demo/pkg/admin"
+	"github.com/johananl/otel-demo/pkg/fault"
+	"github.com/johananl/otel-demo/pkg/field/server"
 	"github.com/johananl/otel-demo/pkg/field/tracing"
+	"github.com/johananl/otel-demo/pkg/latency"
+	"github.com/johananl/otel-demo/pkg/logging"
+	"github.com/johananl/otel-demo/pkg/metrics"
+	"github.com/johananl/otel-demo/pkg/otlp"
+	"github.com/johananl/otel-demo/pkg/resource"
 	pb "github.com/johananl/otel-demo/proto/field"
 	"go.opentelemetry.io/otel/api/core"
 	"go.opentelemetry.io/otel/api/global"
 	"go.opentelemetry.io/otel/api/key"
-	"go.opentelemetry.io/otel/api/trace"
 	"go.opentelemetry.io/otel/exporter/trace/jaeger"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"google.golang.org/grpc"
 )
 
-var fields []string = []string{
-	"marketing",
-	"dolphin",
-	"cat",
-	"penguin",
-	"engineering",
-	"aerospace",
-	"machinery",
-	"finance",
-	"strategy",
-	"beer",
-	"coffee",
-	"whisky",
-	"laundry",
-	"socks",
-}
-
-type server struct {
-	pb.UnimplementedFieldServer
-}
-
-func (s *server) GetField(ctx context.Context, in *pb.FieldRequest) (*pb.FieldReply, error) {
-	log.Println("Received field request")
-
-	if in.Slow {
-		time.Sleep(time.Duration(rand.Intn(300)) * time.Millisecond)
-	}
-	selected := fields[rand.Intn(len(fields))]
-
-	// Get current span. The span was created within the gRPC interceptor.
-	// We are just adding data to it here.
-	span := trace.SpanFromContext(ctx)
-	span.AddEvent(ctx, "Selected field", key.New("field").String(selected))
-
-	return &pb.FieldReply{Field: selected}, nil
-}
-
-func initTraceProvider() {
-	// Create a Jaeger exporter.
-	exporter, err := jaeger.NewExporter(
-		jaeger.WithCollectorEndpoint("http://localhost:14268/api/traces"),
-		jaeger.WithProcess(jaeger.Process{
-			ServiceName: "field",
-			Tags: []core.KeyValue{
-				key.String("exporter", "jaeger"),
-			},
-		}),
-	)
-	if err != nil {
-		log.Fatal(err)
+func initTraceProvider(samplerRatio float64, otlpEndpoint, traceViewer string) *sdktrace.Provider {
+	// Detect the attributes describing this process, e.g. its version and
+	// host. Exporters attach them to spans as the process or resource.
+	res := resource.Detect("field")
+
+	// Send spans to the collector if there is one, which decides which traces
+	// to keep, or straight to Jaeger otherwise.
+	var syncer export.SpanSyncer
+	if otlpEndpoint != "" {
+		exporter, err := otlp.NewExporter(otlpEndpoint, append([]core.KeyValue{
+			key.String("exporter", "otlp"),
+		}, res...))
+		if err != nil {
+			log.Fatal(err)
+		}
+		syncer = exporter
+	} else {
+		// Create a Jaeger exporter.
+		exporter, err := jaeger.NewExporter(
+			jaeger.WithCollectorEndpoint("http://localhost:14268/api/traces"),
+			jaeger.WithProcess(jaeger.Process{
+				ServiceName: "field",
+				Tags: append([]core.KeyValue{
+					key.String("exporter", "jaeger"),
+				}, res...),
+			}),
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+		syncer = exporter
 	}
 
 	// Create a trace provider.
 	// The provider creates a tracer and plugs in the exporter to it.
 	tp, err := sdktrace.NewProvider(
-		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.AlwaysSample()}),
-		sdktrace.WithSyncer(exporter),
+		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.ProbabilitySampler(samplerRatio)}),
+		sdktrace.WithSyncer(syncer),
 	)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	// Send spans to the trace viewer of the frontend as well.
+	if traceViewer != "" {
+		viewer, err := otlp.NewExporter(traceViewer, res)
+		if err != nil {
+			log.Fatal(err)
+		}
+		tp.RegisterSpanProcessor(sdktrace.NewSimpleSpanProcessor(viewer))
+	}
+
 	// Register the trace provider.
 	global.SetTraceProvider(tp)
+
+	return tp
 }
 
 func main() {
-	initTraceProvider()
+	latencySpec := flag.String("latency", latency.DefaultSpec, "latency profile applied to slow requests")
+	faultSpec := flag.String("fault", "", "fault rules, e.g. error:unavailable:0.1;hang:0.05@GetField")
+	samplerRatio := flag.Float64("sampler-ratio", 1, "share of new traces which are sampled")
+	otlpEndpoint := flag.String("otlp-endpoint", "", "address of the collector spans are sent to over OTLP, e.g. localhost:4317; spans are sent to Jaeger if empty")
+	traceViewer := flag.String("trace-viewer", "", "address of the frontend spans are also sent to for its trace viewer, e.g. localhost:8082; disabled if empty")
+	logLevel := flag.String("log-level", "info", "minimum level of logged messages")
+	adminToken := flag.String("admin-token", os.Getenv("ADMIN_TOKEN"), "bearer token for the admin API, which is disabled if empty")
+	flag.Parse()
+
+	profile, err := latency.Parse(*latencySpec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	faults, err := fault.Parse(*faultSpec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	injector := fault.NewInjector(faults)
+	level, err := logging.ParseLevel(*logLevel)
+	if err != nil {
+		log.Fatal(err)
+	}
+	logging.SetLevel(level)
+
+	tp := initTraceProvider(*samplerRatio, *otlpEndpoint, *traceViewer)
+	lat := latency.NewValue(profile)
 
 	rand.Seed(time.Now().UTC().UnixNano())
 
 	host := "localhost"
 	port := 9091
+	httpPort := 9191
 	addr := fmt.Sprintf("%s:%d", host, port)
 
 	lis, err := net.Listen("tcp", addr)
 	if err != nil {
 		log.Fatalf("cannot listen: %v", err)
 	}
-	s := grpc.NewServer(grpc.UnaryInterceptor(tracing.UnaryServerInterceptor))
-	pb.RegisterFieldServer(s, &server{})
+	// Faults are injected within the tracing interceptors so that they are
+	// recorded on the server spans, and within the metrics interceptors so
+	// that they count towards the request latencies.
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			tracing.UnaryServerInterceptor,
+			metrics.UnaryServerInterceptor,
+			injector.UnaryServerInterceptor,
+		),
+		grpc.ChainStreamInterceptor(
+			tracing.StreamServerInterceptor,
+			metrics.StreamServerInterceptor,
+			injector.StreamServerInterceptor,
+		),
+	)
+	pb.RegisterFieldServer(s, server.New(lat))
 
 	ch := make(chan struct{})
 	go func(ch chan struct{}) {
-		if err := s.Serve(lis); err != nil {
+		if err := s.Serve(injector.Listener(lis)); err != nil {
 			log.Fatalf("failed to serve: %v", err)
 		}
 		ch <- struct{}{}
 	}(ch)
 	log.Printf("Listening for gRPC connections on port %d", port)
 
+	// Serve the REST API. The gateway translates HTTP/JSON requests into gRPC
+	// calls against the server above.
+	gwmux := runtime.NewServeMux(runtime.WithIncomingHeaderMatcher(tracing.GatewayHeaderMatcher))
+	err = pb.RegisterFieldHandlerFromEndpoint(context.Background(), gwmux, addr, []grpc.DialOption{grpc.WithInsecure()})
+	if err != nil {
+		log.Fatalf("registering REST gateway: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", gwmux)
+	mux.Handle("/metrics", metrics.Handler())
+	if *adminToken != "" {
+		a := admin.New(admin.Config{
+			Token:        *adminToken,
+			Tracer:       tp.Tracer("field"),
+			Latency:      lat,
+			Faults:       injector,
+			Provider:     tp,
+			SamplerRatio: *samplerRatio,
+		})
+		mux.Handle("/admin/", a.Handler())
+		log.Println("Serving admin API on /admin/")
+	} else {
+		log.Println("Admin API disabled, set -admin-token to enable it")
+	}
+
+	httpAddr := fmt.Sprintf("%s:%d", host, httpPort)
+	go func(ch chan struct{}) {
+		log.Fatal(http.ListenAndServe(httpAddr, mux))
+		ch <- struct{}{}
+	}(ch)
+	log.Printf("Listening for HTTP requests on port %d", httpPort)
+
 	<-ch
 }
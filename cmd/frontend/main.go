@@ -1,65 +1,164 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/johananl/otel-demo/pkg/depgraph"
+	"github.com/johananl/otel-demo/pkg/frontend/server"
 	"github.com/johananl/otel-demo/pkg/frontend/tracing"
+	"github.com/johananl/otel-demo/pkg/metrics"
+	"github.com/johananl/otel-demo/pkg/otlp"
+	"github.com/johananl/otel-demo/pkg/resource"
+	"github.com/johananl/otel-demo/pkg/tracestore"
+	"github.com/johananl/otel-demo/pkg/traceview"
 	fieldpb "github.com/johananl/otel-demo/proto/field"
 	rolepb "github.com/johananl/otel-demo/proto/role"
 	senioritypb "github.com/johananl/otel-demo/proto/seniority"
+	titlepb "github.com/johananl/otel-demo/proto/title"
 	"go.opentelemetry.io/otel/api/core"
 	"go.opentelemetry.io/otel/api/global"
 	"go.opentelemetry.io/otel/api/key"
 	"go.opentelemetry.io/otel/exporter/trace/jaeger"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"google.golang.org/grpc"
 )
 
-type Response struct {
-	Seniority string `json:"seniority"`
-	Field     string `json:"field"`
-	Role      string `json:"role"`
-}
-
-func initTraceProvider() {
-	// Create a Jaeger exporter.
-	exporter, err := jaeger.NewExporter(
-		jaeger.WithCollectorEndpoint("http://localhost:14268/api/traces"),
-		jaeger.WithProcess(jaeger.Process{
-			ServiceName: "frontend",
-			Tags: []core.KeyValue{
-				key.String("exporter", "jaeger"),
-			},
-		}),
-	)
-	if err != nil {
-		log.Fatal(err)
+// initTraceProvider registers a trace provider exporting the spans of the
+// frontend. It returns a function which sends the spans still queued and
+// closes the exporter.
+func initTraceProvider(otlpEndpoint string, traces traceview.Store) func() error {
+	// Detect the attributes describing this process, e.g. its version and
+	// host. Exporters attach them to spans as the process or resource.
+	res := resource.Detect("frontend")
+
+	// Send spans to the collector if there is one, which decides which traces
+	// to keep, or straight to Jaeger otherwise.
+	var syncer export.SpanSyncer
+	var closeExporter func() error
+	if otlpEndpoint != "" {
+		exporter, err := otlp.NewExporter(otlpEndpoint, append([]core.KeyValue{
+			key.String("exporter", "otlp"),
+		}, res...))
+		if err != nil {
+			log.Fatal(err)
+		}
+		syncer, closeExporter = exporter, exporter.Close
+	} else {
+		// Create a Jaeger exporter.
+		exporter, err := jaeger.NewExporter(
+			jaeger.WithCollectorEndpoint("http://localhost:14268/api/traces"),
+			jaeger.WithProcess(jaeger.Process{
+				ServiceName: "frontend",
+				Tags: append([]core.KeyValue{
+					key.String("exporter", "jaeger"),
+				}, res...),
+			}),
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+		syncer = exporter
+		closeExporter = func() error {
+			exporter.Flush()
+			return nil
+		}
 	}
 
 	// Create a trace provider.
 	// The provider creates a tracer and plugs in the exporter to it.
 	tp, err := sdktrace.NewProvider(
 		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.AlwaysSample()}),
-		sdktrace.WithSyncer(exporter),
+		sdktrace.WithSyncer(syncer),
 	)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	// Keep spans for the trace viewer as well.
+	if traces != nil {
+		tp.RegisterSpanProcessor(sdktrace.NewSimpleSpanProcessor(traceview.NewSyncer(traces, res)))
+	}
+
 	// Register the trace provider.
 	global.SetTraceProvider(tp)
+
+	return closeExporter
+}
+
+// stop stops s gracefully, or right away once ctx is done, e.g. because of
+// streams which don't end.
+func stop(ctx context.Context, s *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.Stop()
+	}
 }
 
 func main() {
-	initTraceProvider()
+	batchWindow := flag.Duration("batch-window", 0, "window within which fast requests for single titles are batched, 0 disables batching")
+	prefetch := flag.Int("prefetch", 0, "number of titles per locale generated ahead of time, 0 disables prefetching")
+	otlpEndpoint := flag.String("otlp-endpoint", "", "address of the collector spans are sent to over OTLP, e.g. localhost:4317; spans are sent to Jaeger if empty")
+	traceBuffer := flag.Int("trace-buffer", 10000, "number of recent spans kept for the trace viewer on /debug/traces, 0 disables it")
+	traceStore := flag.String("trace-store", "", "directory spans are stored in for the trace viewer, instead of keeping them in memory")
+	traceStoreMaxSize := flag.Int64("trace-store-max-size", 256, "maximum size of the trace store in MiB, 0 means no limit")
+	traceStoreMaxAge := flag.Duration("trace-store-max-age", 24*time.Hour, "maximum age of the spans in the trace store, 0 means no limit")
+	graphWindow := flag.Duration("graph-window", 10*time.Minute, "window of spans the service dependency graph on /debug/graph is derived from")
+	traceReceiver := flag.String("trace-receiver", "localhost:8082", "address on which spans of the backends are received over OTLP for the trace viewer")
+	flag.Parse()
+
+	// A graph without a window would grow for as long as the server runs.
+	if *graphWindow <= 0 {
+		log.Fatal("graph window must be positive")
+	}
+
+	var traces traceview.Store
+	var store *tracestore.Store
+	storeCtx, stopStore := context.WithCancel(context.Background())
+	switch {
+	case *traceStore != "":
+		// Keep spans on disk, so that they survive restarts.
+		var err error
+		store, err = tracestore.Open(*traceStore, tracestore.Options{
+			MaxSize: *traceStoreMaxSize << 20,
+			MaxAge:  *traceStoreMaxAge,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		go store.Run(storeCtx, time.Minute)
+		traces = store
+	case *traceBuffer > 0:
+		traces = traceview.NewRing(*traceBuffer)
+	}
+	// Derive the service dependency graph from the spans kept for the trace
+	// viewer as they arrive.
+	var graph *depgraph.Graph
+	if traces != nil {
+		graph = depgraph.New(*graphWindow)
+		traces = depgraph.Record(traces, graph)
+	}
+	closeExporter := initTraceProvider(*otlpEndpoint, traces)
 	tr := global.TraceProvider().Tracer("frontend")
 
 	host := "localhost"
 	port := 8080
+	grpcPort := 8081
 
 	seniorityHost := "localhost"
 	seniorityPort := 9090
@@ -73,6 +172,7 @@ func main() {
 		fmt.Sprintf("%s:%d", seniorityHost, seniorityPort),
 		grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(time.Second),
 		grpc.WithUnaryInterceptor(tracing.UnaryClientInterceptor),
+		grpc.WithStreamInterceptor(tracing.StreamClientInterceptor),
 	)
 	if err != nil {
 		log.Fatalf("connecting to seniority service: %v", err)
@@ -86,6 +186,7 @@ func main() {
 		fmt.Sprintf("%s:%d", fieldHost, fieldPort),
 		grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(time.Second),
 		grpc.WithUnaryInterceptor(tracing.UnaryClientInterceptor),
+		grpc.WithStreamInterceptor(tracing.StreamClientInterceptor),
 	)
 	if err != nil {
 		log.Fatalf("connecting to field service: %v", err)
@@ -99,6 +200,7 @@ func main() {
 		fmt.Sprintf("%s:%d", roleHost, rolePort),
 		grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(time.Second),
 		grpc.WithUnaryInterceptor(tracing.UnaryClientInterceptor),
+		grpc.WithStreamInterceptor(tracing.StreamClientInterceptor),
 	)
 	if err != nil {
 		log.Fatalf("connecting to role service: %v", err)
@@ -107,143 +209,93 @@ func main() {
 	roleClient := rolepb.NewRoleClient(rConn)
 	log.Printf("Connected to role service at %s:%d\n", roleHost, rolePort)
 
-	// API handler function.
-	apiHandler := func(w http.ResponseWriter, r *http.Request) {
-		ctx, span := tr.Start(r.Context(), "serve-http-request")
-		defer span.End()
-
-		var seniority string
-		var field string
-		var role string
-		var res Response
-
-		slow := r.URL.Query().Get("slow")
-		if slow != "" {
-			// Handle request slowly.
-
-			// Get seniority.
-			sr, err := seniorityClient.GetSeniority(ctx, &senioritypb.SeniorityRequest{Slow: true})
-			if err != nil {
-				log.Printf("getting seniority: %v", err)
-				http.Error(w, "Error from seniority service", 500)
-				return
-			}
-			seniority = sr.Seniority
-
-			// Get field.
-			fr, err := fieldClient.GetField(ctx, &fieldpb.FieldRequest{Slow: true})
-			if err != nil {
-				log.Printf("getting field: %v", err)
-				http.Error(w, "Error from field service", 500)
-				return
-			}
-			field = fr.Field
-
-			// Get role.
-			rr, err := roleClient.GetRole(ctx, &rolepb.RoleRequest{Slow: true})
-			if err != nil {
-				log.Printf("getting field: %v", err)
-				http.Error(w, "Error from field service", 500)
-				return
-			}
-			role = rr.Role
-
-			res = Response{
-				Seniority: seniority,
-				Field:     field,
-				Role:      role,
-			}
-		} else {
-			// Handle request quickly.
-
-			errChan := make(chan error)
-
-			// Get seniority.
-			sChan := make(chan *senioritypb.SeniorityReply)
-			go func(reply chan<- *senioritypb.SeniorityReply, errChan chan<- error) {
-				r, err := seniorityClient.GetSeniority(ctx, &senioritypb.SeniorityRequest{})
-				if err != nil {
-					errChan <- fmt.Errorf("getting seniority: %v", err)
-					return
-				}
-
-				reply <- r
-			}(sChan, errChan)
-
-			// Get field.
-			fChan := make(chan *fieldpb.FieldReply)
-			go func(reply chan<- *fieldpb.FieldReply, errChan chan<- error) {
-				r, err := fieldClient.GetField(ctx, &fieldpb.FieldRequest{})
-				if err != nil {
-					errChan <- fmt.Errorf("getting field: %v", err)
-					return
-				}
-
-				reply <- r
-			}(fChan, errChan)
-
-			// Get role.
-			rChan := make(chan *rolepb.RoleReply)
-			go func(reply chan<- *rolepb.RoleReply, errChan chan<- error) {
-				r, err := roleClient.GetRole(ctx, &rolepb.RoleRequest{})
-				if err != nil {
-					errChan <- fmt.Errorf("getting role: %v", err)
-					return
-				}
-
-				reply <- r
-			}(rChan, errChan)
-
-			// Wait for all gRPC calls to return.
-			for seniority == "" || field == "" || role == "" {
-				select {
-				case sr := <-sChan:
-					seniority = sr.Seniority
-				case fr := <-fChan:
-					field = fr.Field
-				case rr := <-rChan:
-					role = rr.Role
-				case err := <-errChan:
-					log.Printf("gRPC error: %v", err)
-					http.Error(w, "Error from backend service", 500)
-					return
-				}
-			}
+	srv := server.New(tr, seniorityClient, fieldClient, roleClient)
+	srv.SetBatchWindow(*batchWindow)
+	srv.SetPrefetch(*prefetch)
+	if traces != nil {
+		srv.SetTraceStore(traces)
+		srv.SetServiceGraph(graph)
+	}
 
-			res = Response{
-				Seniority: seniority,
-				Field:     field,
-				Role:      role,
-			}
+	// The servers report errors on errc, ending the process.
+	errc := make(chan error, 3)
+	hs := &http.Server{Addr: fmt.Sprintf("%s:%d", host, port), Handler: srv.Handler("ui/build")}
+	go func() {
+		if err := hs.ListenAndServe(); err != http.ErrServerClosed {
+			errc <- err
 		}
+	}()
+	log.Printf("Listening for HTTP requests on port %d", port)
 
-		j, err := json.Marshal(res)
+	// Serve titles over gRPC.
+	grpcAddr := fmt.Sprintf("%s:%d", host, grpcPort)
+	lis, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		log.Fatalf("cannot listen: %v", err)
+	}
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		tracing.UnaryServerInterceptor,
+		metrics.UnaryServerInterceptor,
+	))
+	titlepb.RegisterTitleServiceServer(s, srv.TitleServer())
+
+	go func() {
+		if err := s.Serve(lis); err != nil {
+			errc <- fmt.Errorf("failed to serve: %v", err)
+		}
+	}()
+	log.Printf("Listening for gRPC connections on port %d", grpcPort)
+
+	// Receive the spans of the backends for the trace viewer. The receiver
+	// has a server of its own without tracing interceptors, as tracing the
+	// export calls would produce spans to export in turn.
+	var rs *grpc.Server
+	if traces != nil {
+		rlis, err := net.Listen("tcp", *traceReceiver)
 		if err != nil {
-			log.Println("Error serializing to JSON")
-			http.Error(w, "Error serializing to JSON", 500)
-			return
+			log.Fatalf("cannot listen: %v", err)
 		}
-
-		span.AddEvent(ctx, "Generating response", key.New("response").String(string(j)))
-
-		// Write HTTP response.
-		w.Write(j)
+		rs = grpc.NewServer()
+		otlp.NewReceiver(traces.Add).Register(rs)
+		go func() {
+			if err := rs.Serve(rlis); err != nil {
+				errc <- fmt.Errorf("failed to serve: %v", err)
+			}
+		}()
+		log.Printf("Serving trace viewer on %s and service graph on %s, receiving spans on %s; start the backends with -trace-viewer %[3]s", traceview.Path, depgraph.Path, *traceReceiver)
 	}
 
-	// Handle static content (for UI).
-	fs := http.FileServer(http.Dir("ui/build"))
-	http.Handle("/", fs)
-
-	// Handle API.
-	http.HandleFunc("/api", apiHandler)
-
-	addr := fmt.Sprintf("%s:%d", host, port)
-	ch := make(chan struct{})
-	go func(ch chan struct{}) {
-		log.Fatal(http.ListenAndServe(addr, nil))
-		ch <- struct{}{}
-	}(ch)
-	log.Printf("Listening for HTTP requests on port %d", port)
+	// Shut down on interrupt or termination as well, so that the spans
+	// still queued are exported and the trace store is closed cleanly.
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	var serveErr error
+	select {
+	case serveErr = <-errc:
+		log.Print(serveErr)
+	case sg := <-sig:
+		log.Printf("Received %v, shutting down", sg)
+	}
 
-	<-ch
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := hs.Shutdown(ctx); err != nil {
+		hs.Close()
+	}
+	stop(ctx, s)
+	if rs != nil {
+		stop(ctx, rs)
+	}
+	if err := closeExporter(); err != nil {
+		log.Printf("Error closing span exporter: %v", err)
+	}
+	stopStore()
+	if store != nil {
+		if err := store.Close(); err != nil {
+			log.Fatalf("Error closing trace store: %v", err)
+		}
+	}
+	if serveErr != nil {
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,317 @@
+// Command loadgen generates load against the frontend or one of the backends
+// and reports latency percentiles and error rates.
+//
+// Every generated request is the root of a trace of its own, so that the
+// traces of a load run can be told apart from other traffic in Jaeger.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/johananl/otel-demo/pkg/frontend/client"
+	"github.com/johananl/otel-demo/pkg/frontend/tracing"
+	"github.com/johananl/otel-demo/pkg/otlp"
+	"github.com/johananl/otel-demo/pkg/resource"
+	fieldpb "github.com/johananl/otel-demo/proto/field"
+	rolepb "github.com/johananl/otel-demo/proto/role"
+	senioritypb "github.com/johananl/otel-demo/proto/seniority"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/exporter/trace/jaeger"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultAddrs are the gRPC addresses of the backends.
+var defaultAddrs = map[string]string{
+	"seniority": "localhost:9090",
+	"field":     "localhost:9091",
+	"role":      "localhost:9092",
+}
+
+// requestFunc sends a single request.
+type requestFunc func(ctx context.Context, slow bool) error
+
+// flusher is an exporter which buffers spans.
+type flusher interface {
+	Flush()
+}
+
+func initTraceProvider(otlpEndpoint string) flusher {
+	// Detect the attributes describing this process, e.g. its version and
+	// host. Exporters attach them to spans as the process or resource.
+	res := resource.Detect("loadgen")
+
+	// Send spans to the collector if there is one, which decides which traces
+	// to keep, or straight to Jaeger otherwise.
+	var (
+		syncer export.SpanSyncer
+		buf    flusher
+	)
+	if otlpEndpoint != "" {
+		exporter, err := otlp.NewExporter(otlpEndpoint, append([]core.KeyValue{
+			key.String("exporter", "otlp"),
+		}, res...))
+		if err != nil {
+			log.Fatal(err)
+		}
+		syncer, buf = exporter, exporter
+	} else {
+		// Create a Jaeger exporter.
+		exporter, err := jaeger.NewExporter(
+			jaeger.WithCollectorEndpoint("http://localhost:14268/api/traces"),
+			jaeger.WithProcess(jaeger.Process{
+				ServiceName: "loadgen",
+				Tags: append([]core.KeyValue{
+					key.String("exporter", "jaeger"),
+				}, res...),
+			}),
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+		syncer, buf = exporter, exporter
+	}
+
+	// Create a trace provider.
+	// The provider creates a tracer and plugs in the exporter to it.
+	tp, err := sdktrace.NewProvider(
+		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.AlwaysSample()}),
+		sdktrace.WithSyncer(syncer),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Register the trace provider.
+	global.SetTraceProvider(tp)
+
+	return buf
+}
+
+// frontendRequests returns a function which requests titles from the frontend
+// HTTP API.
+func frontendRequests(baseURL, lang string, tags []string) requestFunc {
+	c := client.New(baseURL)
+
+	return func(ctx context.Context, slow bool) error {
+		_, err := c.GetTitle(ctx, client.Options{Slow: slow, Lang: lang, Tags: tags})
+		return err
+	}
+}
+
+// backendRequests returns a function which requests words from a backend
+// directly over gRPC.
+func backendRequests(target, addr, lang string, tags []string) requestFunc {
+	conn, err := grpc.Dial(
+		addr,
+		grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(time.Second),
+		grpc.WithUnaryInterceptor(tracing.UnaryClientInterceptor),
+	)
+	if err != nil {
+		log.Fatalf("connecting to %s service: %v", target, err)
+	}
+
+	switch target {
+	case "seniority":
+		c := senioritypb.NewSeniorityClient(conn)
+		return func(ctx context.Context, slow bool) error {
+			_, err := c.GetSeniority(ctx, &senioritypb.SeniorityRequest{Slow: slow, Locale: lang, Tags: tags})
+			return err
+		}
+	case "field":
+		c := fieldpb.NewFieldClient(conn)
+		return func(ctx context.Context, slow bool) error {
+			_, err := c.GetField(ctx, &fieldpb.FieldRequest{Slow: slow, Locale: lang, Tags: tags})
+			return err
+		}
+	default:
+		c := rolepb.NewRoleClient(conn)
+		return func(ctx context.Context, slow bool) error {
+			_, err := c.GetRole(ctx, &rolepb.RoleRequest{Slow: slow, Locale: lang, Tags: tags})
+			return err
+		}
+	}
+}
+
+// traced wraps send so that every request is sent within a new root span.
+func traced(tr trace.Tracer, target string, send requestFunc) requestFunc {
+	return func(ctx context.Context, slow bool) error {
+		ctx, span := tr.Start(
+			ctx,
+			"generate-load",
+			trace.WithSpanKind(trace.SpanKindClient),
+		)
+		defer span.End()
+
+		span.SetAttributes(
+			key.New("loadgen.target").String(target),
+			key.New("slow").Bool(slow),
+		)
+
+		err := send(ctx, slow)
+		if err != nil {
+			span.SetStatus(errorCode(err))
+			span.AddEvent(ctx, "Request failed", key.New("error").String(err.Error()))
+		}
+
+		return err
+	}
+}
+
+// errorCode maps an error to the status code recorded on the span.
+func errorCode(err error) codes.Code {
+	if _, ok := err.(*client.Error); ok {
+		return codes.Unknown
+	}
+
+	return status.Code(err)
+}
+
+func main() {
+	target := flag.String("target", "frontend", "service to load: frontend, seniority, field or role")
+	baseURL := flag.String("url", client.DefaultBaseURL, "base URL of the frontend")
+	addr := flag.String("addr", "", "gRPC address of the backend target (default is the backend's standard address)")
+	rps := flag.Float64("rps", 0, "requests per second, 0 means as fast as the workers allow")
+	concurrency := flag.Int("concurrency", 10, "number of concurrent workers")
+	duration := flag.Duration("duration", 30*time.Second, "duration of the run")
+	requests := flag.Int("requests", 0, "stop after this many requests, 0 means no limit")
+	slowRatio := flag.Float64("slow-ratio", 0.1, "share of slow requests, between 0 and 1")
+	lang := flag.String("lang", "", "locale of the requested words")
+	tag := flag.String("tag", "", "comma-separated tags of the requested words")
+	timeout := flag.Duration("timeout", 10*time.Second, "time a single request may take, 0 means no limit")
+	otlpEndpoint := flag.String("otlp-endpoint", "", "address of the collector spans are sent to over OTLP, e.g. localhost:4317; spans are sent to Jaeger if empty")
+	flag.Parse()
+
+	if *concurrency < 1 {
+		log.Fatal("concurrency must be at least 1")
+	}
+	if *slowRatio < 0 || *slowRatio > 1 {
+		log.Fatal("slow ratio must be between 0 and 1")
+	}
+	// interval is the time between two requests, 0 if they aren't paced.
+	var interval time.Duration
+	if *rps < 0 {
+		log.Fatal("rps must not be negative")
+	}
+	if *rps > 0 {
+		if interval = time.Duration(float64(time.Second) / *rps); interval <= 0 {
+			log.Fatalf("rps must be at most %d", time.Second)
+		}
+	}
+	var tags []string
+	if *tag != "" {
+		tags = strings.Split(*tag, ",")
+	}
+
+	exporter := initTraceProvider(*otlpEndpoint)
+	defer exporter.Flush()
+	tr := global.TraceProvider().Tracer("loadgen")
+
+	var send requestFunc
+	switch *target {
+	case "frontend":
+		send = frontendRequests(*baseURL, *lang, tags)
+	case "seniority", "field", "role":
+		a := *addr
+		if a == "" {
+			a = defaultAddrs[*target]
+		}
+		send = backendRequests(*target, a, *lang, tags)
+	default:
+		log.Fatalf("unknown target %q", *target)
+	}
+	send = traced(tr, *target, send)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *duration)
+	defer cancel()
+
+	// Requests in flight are allowed to finish after the run ended, so they
+	// get a context of their own, which is only canceled on interrupt.
+	reqCtx, abort := context.WithCancel(context.Background())
+	defer abort()
+
+	// Stop early on interrupt but still print the report.
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		cancel()
+		abort()
+	}()
+
+	// Dispatch requests to the workers, either paced by a ticker or as fast
+	// as the workers pick them up.
+	jobs := make(chan bool)
+	go func() {
+		defer close(jobs)
+
+		var tick <-chan time.Time
+		if interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		for n := 0; *requests == 0 || n < *requests; n++ {
+			if tick != nil {
+				select {
+				case <-ctx.Done():
+					return
+				case <-tick:
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- rand.Float64() < *slowRatio:
+			}
+		}
+	}()
+
+	log.Printf("Generating load against %s with %d workers for %v", *target, *concurrency, *duration)
+
+	var st stats
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < *concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for slow := range jobs {
+				ctx, cancel := reqCtx, context.CancelFunc(func() {})
+				if *timeout > 0 {
+					ctx, cancel = context.WithTimeout(reqCtx, *timeout)
+				}
+				t := time.Now()
+				err := send(ctx, slow)
+				d := time.Since(t)
+				cancel()
+				// Requests canceled by an interrupt didn't fail.
+				if reqCtx.Err() != nil {
+					return
+				}
+				st.add(result{slow: slow, duration: d, err: err})
+			}
+		}()
+	}
+	wg.Wait()
+
+	fmt.Println()
+	st.report(os.Stdout, time.Since(start))
+}
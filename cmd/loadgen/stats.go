@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// result is the outcome of a single generated request.
+type result struct {
+	slow     bool
+	duration time.Duration
+	err      error
+}
+
+// stats aggregates the results of a load run.
+type stats struct {
+	mu      sync.Mutex
+	results []result
+}
+
+func (s *stats) add(r result) {
+	s.mu.Lock()
+	s.results = append(s.results, r)
+	s.mu.Unlock()
+}
+
+// report prints latency percentiles and error rates of all results as well as
+// separately for slow and fast requests.
+func (s *stats) report(w io.Writer, elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fmt.Fprintf(w, "Sent %d requests in %v (%.1f req/s)\n\n",
+		len(s.results), elapsed.Round(time.Millisecond), float64(len(s.results))/elapsed.Seconds())
+
+	fmt.Fprintf(w, "%-6s %8s %8s %8s %8s %8s %8s %8s\n",
+		"", "count", "errors", "p50", "p90", "p95", "p99", "max")
+	s.line(w, "all", func(result) bool { return true })
+	s.line(w, "fast", func(r result) bool { return !r.slow })
+	s.line(w, "slow", func(r result) bool { return r.slow })
+
+	// Group errors by message so that the most common failures stand out.
+	errs := map[string]int{}
+	for _, r := range s.results {
+		if r.err != nil {
+			errs[r.err.Error()]++
+		}
+	}
+	if len(errs) == 0 {
+		return
+	}
+
+	msgs := make([]string, 0, len(errs))
+	for msg := range errs {
+		msgs = append(msgs, msg)
+	}
+	sort.Slice(msgs, func(i, j int) bool { return errs[msgs[i]] > errs[msgs[j]] })
+
+	fmt.Fprintln(w, "\nErrors:")
+	for _, msg := range msgs {
+		fmt.Fprintf(w, "%8d  %s\n", errs[msg], msg)
+	}
+}
+
+func (s *stats) line(w io.Writer, name string, include func(result) bool) {
+	var durations []time.Duration
+	errors := 0
+	for _, r := range s.results {
+		if !include(r) {
+			continue
+		}
+		if r.err != nil {
+			errors++
+			continue
+		}
+		durations = append(durations, r.duration)
+	}
+
+	total := len(durations) + errors
+	if total == 0 {
+		return
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	fmt.Fprintf(w, "%-6s %8d %7.1f%% %8v %8v %8v %8v %8v\n",
+		name, total, 100*float64(errors)/float64(total),
+		percentile(durations, 50), percentile(durations, 90), percentile(durations, 95),
+		percentile(durations, 99), percentile(durations, 100))
+}
+
+// percentile returns the p-th percentile of sorted durations using the
+// nearest-rank method.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+
+	return sorted[rank-1].Round(100 * time.Microsecond)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/johananl/otel-demo/pkg/critpath"
+	"github.com/johananl/otel-demo/pkg/traceview"
+)
+
+// analyze returns the latency breakdown of a trace, starting from the span
+// named op if it isn't empty. It returns false if the trace has no such span,
+// or no root span to start from, which is skipped rather than failing the
+// whole query.
+func analyze(spans []traceview.Span, op string) (critpath.Breakdown, bool, error) {
+	if op != "" {
+		spans = critpath.Subtree(spans, op)
+		if spans == nil {
+			return critpath.Breakdown{}, false, nil
+		}
+	}
+	b, err := critpath.Analyze(spans)
+	if err == critpath.ErrNoRoot {
+		log.Printf("Skipping trace %x: %v", spans[0].Data.SpanContext.TraceID, err)
+		return b, false, nil
+	}
+
+	return b, err == nil, err
+}
+
+// writeCriticalPath writes the critical path, self time and gaps of a trace.
+func writeCriticalPath(w io.Writer, sum traceview.Summary, b critpath.Breakdown) error {
+	root := b.Root.Data
+	fmt.Fprintf(w, "Trace %x: %s: %s, %s, parallelism %.2f\n", sum.ID, b.Root.Service(), root.Name, round(b.Duration), b.Parallelism)
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "  Critical path:")
+	for _, seg := range b.CriticalPath {
+		d := seg.Duration()
+		fmt.Fprintf(tw, "  +%s\t%s\t|%s|\t%s: %s\n",
+			round(seg.Start.Sub(root.StartTime)), round(d), bar(seg.Start.Sub(root.StartTime), d, b.Duration), seg.Span.Service(), seg.Span.Data.Name)
+	}
+	if gaps := b.Gaps; len(gaps) > 0 {
+		fmt.Fprintln(tw, "  Gaps:")
+		for _, g := range gaps {
+			d := g.Duration()
+			fmt.Fprintf(tw, "  +%s\t%s\t|%s|\t%s: %s\n",
+				round(g.Start.Sub(root.StartTime)), round(d), bar(g.Start.Sub(root.StartTime), d, b.Duration), g.Span.Service(), g.Span.Data.Name)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if err := writeServices(w, b.PathTime, b.SelfTime); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+
+	return err
+}
+
+// writeServices writes a table of the time every service spends on the
+// critical path and working.
+func writeServices(w io.Writer, pathTime, selfTime map[string]time.Duration) error {
+	var services []string
+	for svc := range selfTime {
+		services = append(services, svc)
+	}
+	sort.Strings(services)
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "  SERVICE\tCRITICAL PATH\tSELF TIME")
+	for _, svc := range services {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", svc, round(pathTime[svc]), round(selfTime[svc]))
+	}
+
+	return tw.Flush()
+}
+
+// mode returns "slow" or "fast" depending on the slow attribute of the spans
+// of a title, or "unknown" if no span has it.
+func mode(spans []traceview.Span) string {
+	for _, s := range spans {
+		for _, kv := range s.Data.Attributes {
+			if kv.Key == "slow" {
+				if kv.Value.AsBool() {
+					return "slow"
+				}
+				return "fast"
+			}
+		}
+	}
+
+	return "unknown"
+}
+
+// writeComparison writes the aggregated breakdowns of groups of traces side
+// by side.
+func writeComparison(w io.Writer, groups []critpath.Stats) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprint(tw, "\t")
+	for _, g := range groups {
+		fmt.Fprintf(tw, "%s\t", g.Name)
+	}
+	fmt.Fprintln(tw)
+
+	row := func(name string, value func(critpath.Stats) string) {
+		fmt.Fprintf(tw, "%s\t", name)
+		for _, g := range groups {
+			fmt.Fprintf(tw, "%s\t", value(g))
+		}
+		fmt.Fprintln(tw)
+	}
+	row("traces", func(g critpath.Stats) string { return fmt.Sprint(g.Traces) })
+	row("mean", func(g critpath.Stats) string { return round(g.Mean).String() })
+	row("p50", func(g critpath.Stats) string { return round(g.P50).String() })
+	row("p95", func(g critpath.Stats) string { return round(g.P95).String() })
+	row("parallelism", func(g critpath.Stats) string { return fmt.Sprintf("%.2f", g.Parallelism) })
+	row("gaps", func(g critpath.Stats) string { return round(g.GapTime).String() })
+
+	services := map[string]bool{}
+	for _, g := range groups {
+		for svc := range g.SelfTime {
+			services[svc] = true
+		}
+	}
+	var sorted []string
+	for svc := range services {
+		sorted = append(sorted, svc)
+	}
+	sort.Strings(sorted)
+	for _, svc := range sorted {
+		svc := svc
+		row(svc+" critical path", func(g critpath.Stats) string { return round(g.PathTime[svc]).String() })
+		row(svc+" self time", func(g critpath.Stats) string { return round(g.SelfTime[svc]).String() })
+	}
+
+	return tw.Flush()
+}
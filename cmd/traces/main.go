@@ -0,0 +1,325 @@
+// Command traces queries stored traces, prints them as span trees in the
+// terminal and exports them as OTLP JSON or protobuf, e.g. to attach them to
+// bug reports.
+//
+// Traces are read from a trace store directory written by the frontend with
+// -trace-store (see package tracestore) or from the trace viewer of a running
+// frontend.
+//
+// The critical-path format breaks down the latency of every trace (see
+// package critpath) and the compare format compares the titles generated in
+// slow and fast mode across all traces found. The graph-json, graph-dot and
+// graph-mermaid formats write the dependency graph of the services derived
+// from the traces found (see package depgraph); the frontend serves the
+// graph of its recent spans on /debug/graph.
+//
+//	traces -service role -min 500ms -format tree
+//	traces -store /tmp/traces -trace-id 4bf92f35 -format json -o trace.json
+//	traces -operation serve-http-request -limit 500 -format compare
+//	traces -limit 1000 -format graph-dot | dot -Tsvg > graph.svg
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/johananl/otel-demo/pkg/critpath"
+	"github.com/johananl/otel-demo/pkg/depgraph"
+	"github.com/johananl/otel-demo/pkg/frontend/client"
+	"github.com/johananl/otel-demo/pkg/tracestore"
+	"github.com/johananl/otel-demo/pkg/traceview"
+	"go.opentelemetry.io/otel/api/core"
+	"google.golang.org/grpc/codes"
+)
+
+// barWidth is the width of the bars of span trees.
+const barWidth = 40
+
+// source is where traces are read from.
+type source interface {
+	Find(q traceview.Query) ([]traceview.Summary, error)
+	Trace(id core.TraceID) ([]traceview.Span, error)
+}
+
+// storeSource reads traces from a local trace store.
+type storeSource struct {
+	store *tracestore.Store
+}
+
+func (s storeSource) Find(q traceview.Query) ([]traceview.Summary, error) {
+	return s.store.Find(q), nil
+}
+
+func (s storeSource) Trace(id core.TraceID) ([]traceview.Span, error) {
+	return s.store.Trace(id), nil
+}
+
+// formats are the output formats.
+var formats = map[string]bool{
+	"list": true, "tree": true, "critical-path": true, "compare": true,
+	"graph-json": true, "graph-dot": true, "graph-mermaid": true,
+	"json": true, "otlp": true,
+}
+
+// attrs collects repeated -attr flags.
+type attrs []traceview.Attribute
+
+func (a *attrs) String() string {
+	var ss []string
+	for _, attr := range *a {
+		ss = append(ss, attr.String())
+	}
+	return strings.Join(ss, ",")
+}
+
+func (a *attrs) Set(v string) error {
+	attr, err := traceview.ParseAttribute(v)
+	if err != nil {
+		return err
+	}
+	*a = append(*a, attr)
+	return nil
+}
+
+func main() {
+	dir := flag.String("store", "", "trace store directory to read, instead of querying the frontend")
+	baseURL := flag.String("url", client.DefaultBaseURL, "base URL of the frontend whose trace viewer is queried")
+	traceID := flag.String("trace-id", "", "trace ID or a prefix of it")
+	service := flag.String("service", "", "only traces with spans of this service")
+	operation := flag.String("operation", "", "only traces with spans of this name, e.g. serve-http-request")
+	min := flag.Duration("min", 0, "minimum trace duration")
+	max := flag.Duration("max", 0, "maximum trace duration")
+	errors := flag.Bool("errors", false, "only traces with failed spans")
+	limit := flag.Int("limit", 20, "maximum number of traces")
+	format := flag.String("format", "list", "output format: list, tree, critical-path, compare, graph-json, graph-dot, graph-mermaid, json (OTLP JSON) or otlp (OTLP protobuf)")
+	out := flag.String("o", "", "file to write to instead of standard output")
+	var attributes attrs
+	flag.Var(&attributes, "attr", "only traces with a span or resource attribute matching key=value or key; may be repeated")
+	flag.Parse()
+
+	// Check the format before the output file is created, so that an
+	// existing file isn't truncated for nothing.
+	if !formats[*format] {
+		log.Fatalf("unknown format %q", *format)
+	}
+
+	var src source
+	if *dir != "" {
+		store, err := tracestore.Open(*dir, tracestore.Options{ReadOnly: true})
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer store.Close()
+		src = storeSource{store}
+	} else {
+		src = traceview.NewClient(*baseURL)
+	}
+
+	found, err := src.Find(traceview.Query{
+		TraceID:     *traceID,
+		Service:     *service,
+		Operation:   *operation,
+		Attributes:  attributes,
+		MinDuration: *min,
+		MaxDuration: *max,
+		Errors:      *errors,
+		Limit:       *limit,
+	})
+	if err != nil {
+		log.Fatalf("querying traces: %v", err)
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	var f *os.File
+	if *out != "" {
+		if f, err = os.Create(*out); err != nil {
+			log.Fatal(err)
+		}
+		w = bufio.NewWriter(f)
+	}
+
+	switch *format {
+	case "list":
+		err = writeList(w, found)
+	case "tree":
+		err = forEachTrace(src, found, func(sum traceview.Summary, spans []traceview.Span) error {
+			return writeTree(w, sum, spans)
+		})
+	case "critical-path":
+		err = forEachTrace(src, found, func(sum traceview.Summary, spans []traceview.Span) error {
+			b, ok, err := analyze(spans, *operation)
+			if !ok {
+				return err
+			}
+			return writeCriticalPath(w, sum, b)
+		})
+	case "compare":
+		byMode := map[string][]critpath.Breakdown{}
+		err = forEachTrace(src, found, func(_ traceview.Summary, spans []traceview.Span) error {
+			b, ok, err := analyze(spans, *operation)
+			if ok {
+				m := mode(spans)
+				byMode[m] = append(byMode[m], b)
+			}
+			return err
+		})
+		if err == nil {
+			var groups []critpath.Stats
+			for _, m := range []string{"slow", "fast", "unknown"} {
+				if bs := byMode[m]; len(bs) > 0 {
+					groups = append(groups, critpath.Aggregate(m, bs))
+				}
+			}
+			err = writeComparison(w, groups)
+		}
+	case "graph-json", "graph-dot", "graph-mermaid":
+		g := depgraph.New(0)
+		err = forEachTrace(src, found, func(_ traceview.Summary, spans []traceview.Span) error {
+			g.AddSpans(spans)
+			return nil
+		})
+		if err == nil {
+			err = depgraph.Write(w, g.Snapshot(), strings.TrimPrefix(*format, "graph-"))
+		}
+	case "json", "otlp":
+		var all []traceview.Span
+		err = forEachTrace(src, found, func(_ traceview.Summary, spans []traceview.Span) error {
+			all = append(all, spans...)
+			return nil
+		})
+		if err == nil {
+			err = writeExport(w, *format, all)
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if f != nil {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *out != "" {
+		log.Printf("Wrote %d traces to %s", len(found), *out)
+	}
+}
+
+// forEachTrace reads the traces in found and calls fn for each of them.
+func forEachTrace(src source, found []traceview.Summary, fn func(traceview.Summary, []traceview.Span) error) error {
+	for _, sum := range found {
+		spans, err := src.Trace(sum.ID)
+		if err != nil {
+			return fmt.Errorf("reading trace %x: %v", sum.ID, err)
+		}
+		if len(spans) == 0 {
+			// Evicted since it was found.
+			continue
+		}
+		if err := fn(sum, spans); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeList writes a table of traces.
+func writeList(w io.Writer, found []traceview.Summary) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "START\tDURATION\tSPANS\tERRORS\tROOT\tTRACE ID")
+	for _, s := range found {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s: %s\t%x\n",
+			s.Start.Format("2006-01-02 15:04:05.000"), round(s.Duration), s.Spans, s.Errors, s.Service, s.Root, s.ID)
+	}
+
+	return tw.Flush()
+}
+
+// writeTree writes the spans of a trace as a tree, with a bar showing when
+// every span ran.
+func writeTree(w io.Writer, sum traceview.Summary, spans []traceview.Span) error {
+	fmt.Fprintf(w, "Trace %x: %s: %s, %s, %d spans, %d errors\n", sum.ID, sum.Service, sum.Root, round(sum.Duration), sum.Spans, sum.Errors)
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, r := range traceview.Waterfall(spans) {
+		d := r.Data.EndTime.Sub(r.Data.StartTime)
+		status := ""
+		if r.Data.Status != codes.OK {
+			status = " [" + r.Data.Status.String() + "]"
+		}
+		fmt.Fprintf(tw, "  +%s\t%s\t|%s|\t%s%s: %s%s\n",
+			round(r.Offset), round(d), bar(r.Offset, d, sum.Duration), strings.Repeat("  ", r.Depth), r.Service(), r.Data.Name, status)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+
+	return err
+}
+
+// bar draws the part of a trace lasting total which a span starting at offset
+// and lasting d took up.
+func bar(offset, d, total time.Duration) string {
+	if total <= 0 {
+		return strings.Repeat("#", barWidth)
+	}
+	// Spans may lie outside of the trace or end before they start, e.g. if
+	// their end time is missing, so the bar is clamped to the trace.
+	from := clampBar(int64(barWidth)*int64(offset)/int64(total), 0)
+	to := clampBar(int64(barWidth)*int64(offset+d)/int64(total), from)
+	if to == from && from < barWidth {
+		to = from + 1
+	}
+
+	return strings.Repeat(" ", from) + strings.Repeat("#", to-from) + strings.Repeat(" ", barWidth-to)
+}
+
+// clampBar clamps a position on a bar to [min, barWidth].
+func clampBar(pos int64, min int) int {
+	switch {
+	case pos < int64(min):
+		return min
+	case pos > barWidth:
+		return barWidth
+	default:
+		return int(pos)
+	}
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(10 * time.Microsecond)
+}
+
+// writeExport writes spans as an OTLP export request.
+func writeExport(w io.Writer, format string, spans []traceview.Span) error {
+	req := traceview.Export(spans)
+	if format == "json" {
+		m := jsonpb.Marshaler{Indent: "  "}
+		if err := m.Marshal(w, req); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintln(w)
+		return err
+	}
+
+	b, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+
+	return err
+}
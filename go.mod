@@ -3,8 +3,12 @@ module github.com/johananl/otel-demo
 go 1.13
 
 require (
-	github.com/golang/protobuf v1.3.2
+	github.com/golang/protobuf v1.4.3
+	github.com/grpc-ecosystem/grpc-gateway v1.16.0
 	go.opentelemetry.io/otel v0.2.2-0.20200111012159-d85178b63b15
 	go.opentelemetry.io/otel/exporter/trace/jaeger v0.2.2-0.20200111012159-d85178b63b15
-	google.golang.org/grpc v1.24.0
+	go.opentelemetry.io/proto/otlp v0.7.0
+	golang.org/x/net v0.0.0-20200822124328-c89045814202
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013
+	google.golang.org/grpc v1.36.0
 )
@@ -0,0 +1,235 @@
+// Package admin serves an HTTP API for changing the behaviour of a backend
+// service at runtime: its latency profile, its fault rules, its sampler ratio
+// and its log level.
+//
+// Requests must carry the configured token as a bearer token. Every change is
+// logged and recorded in a span, so that traces show when conditions changed.
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/johananl/otel-demo/pkg/fault"
+	"github.com/johananl/otel-demo/pkg/latency"
+	"github.com/johananl/otel-demo/pkg/logging"
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/plugin/httptrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/codes"
+)
+
+// Settings are the runtime settings of a service. In updates, nil fields are
+// left unchanged.
+type Settings struct {
+	// Latency is a latency profile spec applied to slow requests.
+	Latency *string `json:"latency,omitempty"`
+	// Faults replaces all fault rules.
+	Faults *[]fault.Rule `json:"faults,omitempty"`
+	// SamplerRatio is the share of new traces which are sampled. Requests
+	// continuing a sampled trace are always sampled.
+	SamplerRatio *float64 `json:"sampler_ratio,omitempty"`
+	// LogLevel is the minimum level of logged messages.
+	LogLevel *string `json:"log_level,omitempty"`
+}
+
+// Config configures a Server.
+type Config struct {
+	// Token is required as bearer token by all requests.
+	Token string
+	// Tracer records the changes.
+	Tracer trace.Tracer
+	// Latency holds the latency profile of the service.
+	Latency *latency.Value
+	// Faults injects the faults of the service.
+	Faults *fault.Injector
+	// Provider is the trace provider whose sampler is adjusted.
+	Provider *sdktrace.Provider
+	// SamplerRatio is the ratio the provider was configured with.
+	SamplerRatio float64
+}
+
+// Server serves the admin API.
+type Server struct {
+	cfg Config
+
+	// mu serializes updates and guards samplerRatio.
+	mu           sync.Mutex
+	samplerRatio float64
+}
+
+// New returns an admin server.
+func New(cfg Config) *Server {
+	return &Server{cfg: cfg, samplerRatio: cfg.SamplerRatio}
+}
+
+// Handler returns the handler of the admin API. GET /admin/config returns the
+// current settings and PATCH /admin/config updates them.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/config", s.serveConfig)
+
+	return s.authenticate(mux)
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		token := strings.TrimPrefix(auth, "Bearer ")
+		if s.cfg.Token == "" || token == auth || subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.Token)) != 1 {
+			log.Printf("Rejected admin request from %s", r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", 401)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Current returns the current settings.
+func (s *Server) Current() Settings {
+	s.mu.Lock()
+	ratio := s.samplerRatio
+	s.mu.Unlock()
+
+	spec := latency.SpecOf(s.cfg.Latency.Load())
+	rules := s.cfg.Faults.Rules()
+	if rules == nil {
+		rules = []fault.Rule{}
+	}
+	level := logging.CurrentLevel().String()
+
+	return Settings{
+		Latency:      &spec,
+		Faults:       &rules,
+		SamplerRatio: &ratio,
+		LogLevel:     &level,
+	}
+}
+
+func (s *Server) serveConfig(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+	case "PATCH":
+		var update Settings
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			http.Error(w, "Invalid settings: "+err.Error(), 400)
+			return
+		}
+		if err := s.update(r, update); err != nil {
+			http.Error(w, "Invalid settings: "+err.Error(), 400)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PATCH")
+		http.Error(w, "Method not allowed", 405)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.Current())
+}
+
+// update validates and applies an update requested by r. Either all changes
+// are applied or none.
+func (s *Server) update(r *http.Request, update Settings) error {
+	// Validate everything before changing anything.
+	var profile latency.Profile
+	var level logging.Level
+	var err error
+	if update.Latency != nil {
+		if profile, err = latency.Parse(*update.Latency); err != nil {
+			return err
+		}
+	}
+	if update.Faults != nil {
+		for _, rule := range *update.Faults {
+			if err := rule.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	if update.SamplerRatio != nil && (*update.SamplerRatio < 0 || *update.SamplerRatio > 1) {
+		return fmt.Errorf("sampler ratio must be between 0 and 1")
+	}
+	if update.LogLevel != nil {
+		if level, err = logging.ParseLevel(*update.LogLevel); err != nil {
+			return err
+		}
+	}
+
+	_, _, spanCtx := httptrace.Extract(r.Context(), r)
+	ctx, span := s.cfg.Tracer.Start(
+		r.Context(),
+		"update-admin-config",
+		trace.ChildOf(spanCtx),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+	span.SetAttributes(key.New("admin.remote_addr").String(r.RemoteAddr))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// change logs a change and records it on the span.
+	change := func(setting, from, to string) {
+		log.Printf("Admin: changed %s from %s to %s", setting, from, to)
+		span.AddEvent(ctx, "Changed "+setting,
+			key.New("admin.setting").String(setting),
+			key.New("admin.old").String(from),
+			key.New("admin.new").String(to),
+		)
+	}
+
+	if update.Latency != nil {
+		old := latency.SpecOf(s.cfg.Latency.Load())
+		s.cfg.Latency.Store(profile)
+		change("latency", old, latency.SpecOf(profile))
+	}
+	if update.Faults != nil {
+		old := rulesString(s.cfg.Faults.Rules())
+		s.cfg.Faults.SetRules(*update.Faults)
+		change("faults", old, rulesString(*update.Faults))
+	}
+	if update.SamplerRatio != nil {
+		old := s.samplerRatio
+		s.samplerRatio = *update.SamplerRatio
+		s.cfg.Provider.ApplyConfig(sdktrace.Config{
+			DefaultSampler: sdktrace.ProbabilitySampler(s.samplerRatio),
+		})
+		change("sampler_ratio", formatFloat(old), formatFloat(s.samplerRatio))
+	}
+	if update.LogLevel != nil {
+		old := logging.CurrentLevel()
+		logging.SetLevel(level)
+		change("log_level", old.String(), level.String())
+	}
+
+	span.SetStatus(codes.OK)
+	return nil
+}
+
+func rulesString(rules []fault.Rule) string {
+	if len(rules) == 0 {
+		return "none"
+	}
+
+	s := make([]string, len(rules))
+	for i, r := range rules {
+		s[i] = r.String()
+	}
+
+	return strings.Join(s, ";")
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,39 @@
+package admin
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/johananl/otel-demo/pkg/fault"
+	"github.com/johananl/otel-demo/pkg/latency"
+)
+
+func TestAuthenticate(t *testing.T) {
+	h := New(Config{
+		Token:   "secret",
+		Latency: latency.NewValue(nil),
+		Faults:  fault.NewInjector(nil),
+	}).Handler()
+
+	for _, tc := range []struct {
+		auth   string
+		status int
+	}{
+		{"Bearer secret", 200},
+		{"", 401},
+		{"secret", 401},
+		{"Basic secret", 401},
+		{"Bearer other", 401},
+		{"bearer secret", 401},
+	} {
+		req := httptest.NewRequest("GET", "/admin/config", nil)
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tc.status {
+			t.Errorf("%q: got status %d, want %d", tc.auth, rec.Code, tc.status)
+		}
+	}
+}
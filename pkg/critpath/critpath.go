@@ -0,0 +1,308 @@
+// Package critpath breaks down the latency of traces: which spans the
+// duration of a trace is spent in (its critical path), how long every service
+// works rather than waits, and when spans wait for nothing at all.
+//
+// The critical path is the chain of spans which determines the duration of a
+// trace: shortening any span off the path doesn't make the trace faster. For
+// a title generated in slow mode the backends are called one after another,
+// so all of them are on the path. In fast mode they are called in parallel
+// and only the slowest one is.
+package critpath
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johananl/otel-demo/pkg/traceview"
+	"go.opentelemetry.io/otel/api/core"
+)
+
+// Segment is a part of the critical path during which a span was working
+// rather than waiting for one of its children.
+type Segment struct {
+	Span       traceview.Span
+	Start, End time.Time
+}
+
+// Duration returns the length of the segment.
+func (s Segment) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Gap is an interval during which a span with children wasn't waiting for
+// any of them, e.g. before the first call, between sequential calls or after
+// the last one.
+type Gap struct {
+	Span       traceview.Span
+	Start, End time.Time
+}
+
+// Duration returns the length of the gap.
+func (g Gap) Duration() time.Duration {
+	return g.End.Sub(g.Start)
+}
+
+// Breakdown is the latency breakdown of a trace.
+type Breakdown struct {
+	// Root is the span the breakdown starts from.
+	Root     traceview.Span
+	Duration time.Duration
+	// CriticalPath is ordered by time. Its segments add up to Duration.
+	CriticalPath []Segment
+	// PathTime maps services to the time they spend on the critical path.
+	PathTime map[string]time.Duration
+	// SelfTime maps services to the time their spans spend working rather
+	// than waiting for a child span, on the critical path or not.
+	SelfTime map[string]time.Duration
+	// Gaps are ordered by time.
+	Gaps []Gap
+	// Parallelism is the summed duration of the children of the root
+	// divided by the time during which at least one of them ran. It is 1 if
+	// they ran one after another and more the more they overlapped.
+	Parallelism float64
+}
+
+// GapTime returns the total duration of the gaps.
+func (b Breakdown) GapTime() time.Duration {
+	var d time.Duration
+	for _, g := range b.Gaps {
+		d += g.Duration()
+	}
+
+	return d
+}
+
+var (
+	// ErrNoSpans is returned when analyzing a trace without spans.
+	ErrNoSpans = errors.New("trace has no spans")
+	// ErrNoRoot is returned when analyzing a trace in which every span has a
+	// parent among the spans, e.g. because of a cycle or spans without IDs.
+	ErrNoRoot = errors.New("trace has no root span")
+)
+
+// tree is a trace as a tree of spans.
+type tree struct {
+	children map[core.SpanID][]traceview.Span
+	roots    []traceview.Span
+}
+
+// newTree arranges spans in a tree. Only the first of several spans with the
+// same ID is kept, so that the spans reachable from the roots can't form a
+// cycle.
+func newTree(spans []traceview.Span) tree {
+	ids := make(map[core.SpanID]bool, len(spans))
+	unique := make([]traceview.Span, 0, len(spans))
+	for _, s := range spans {
+		if id := s.Data.SpanContext.SpanID; !ids[id] {
+			ids[id] = true
+			unique = append(unique, s)
+		}
+	}
+
+	t := tree{children: map[core.SpanID][]traceview.Span{}}
+	for _, s := range unique {
+		if p := s.Data.ParentSpanID; ids[p] {
+			t.children[p] = append(t.children[p], s)
+		} else {
+			t.roots = append(t.roots, s)
+		}
+	}
+	sort.SliceStable(t.roots, func(i, j int) bool { return t.roots[i].Data.StartTime.Before(t.roots[j].Data.StartTime) })
+
+	return t
+}
+
+func (t tree) childrenOf(s traceview.Span) []traceview.Span {
+	return t.children[s.Data.SpanContext.SpanID]
+}
+
+// Subtree returns the spans of the earliest span named op and of its
+// descendants, or nil if there is no such span. The name of the tracer, e.g.
+// "frontend/", may be left out of op.
+func Subtree(spans []traceview.Span, op string) []traceview.Span {
+	var root *traceview.Span
+	for i, s := range spans {
+		if s.Data.Name != op && !strings.HasSuffix(s.Data.Name, "/"+op) {
+			continue
+		}
+		if root == nil || s.Data.StartTime.Before(root.Data.StartTime) {
+			root = &spans[i]
+		}
+	}
+	if root == nil {
+		return nil
+	}
+
+	t := newTree(spans)
+	var sub []traceview.Span
+	// The span may be part of a cycle rather than below a root.
+	seen := map[core.SpanID]bool{}
+	var add func(s traceview.Span)
+	add = func(s traceview.Span) {
+		if seen[s.Data.SpanContext.SpanID] {
+			return
+		}
+		seen[s.Data.SpanContext.SpanID] = true
+		sub = append(sub, s)
+		for _, c := range t.childrenOf(s) {
+			add(c)
+		}
+	}
+	add(*root)
+
+	return sub
+}
+
+// Analyze breaks down the latency of a trace. The earliest span without a
+// known parent is the root of the breakdown; other parentless spans, e.g.
+// of processes whose spans are missing, count towards SelfTime and Gaps only.
+func Analyze(spans []traceview.Span) (Breakdown, error) {
+	if len(spans) == 0 {
+		return Breakdown{}, ErrNoSpans
+	}
+
+	t := newTree(spans)
+	if len(t.roots) == 0 {
+		return Breakdown{}, ErrNoRoot
+	}
+	root := t.roots[0]
+	b := Breakdown{
+		Root:     root,
+		Duration: root.Data.EndTime.Sub(root.Data.StartTime),
+		PathTime: map[string]time.Duration{},
+		SelfTime: map[string]time.Duration{},
+	}
+
+	t.walk(root, root.Data.EndTime, &b.CriticalPath)
+	// The path was collected backwards.
+	for i, j := 0, len(b.CriticalPath)-1; i < j; i, j = i+1, j-1 {
+		b.CriticalPath[i], b.CriticalPath[j] = b.CriticalPath[j], b.CriticalPath[i]
+	}
+	for _, seg := range b.CriticalPath {
+		b.PathTime[seg.Span.Service()] += seg.Duration()
+	}
+
+	for _, s := range spans {
+		children := t.childrenOf(s)
+		if len(children) == 0 {
+			b.SelfTime[s.Service()] += s.Data.EndTime.Sub(s.Data.StartTime)
+			continue
+		}
+		for _, g := range gaps(s, children) {
+			b.SelfTime[s.Service()] += g.Duration()
+			b.Gaps = append(b.Gaps, g)
+		}
+	}
+	sort.SliceStable(b.Gaps, func(i, j int) bool { return b.Gaps[i].Start.Before(b.Gaps[j].Start) })
+
+	if children := t.childrenOf(root); len(children) > 0 {
+		var sum, busy time.Duration
+		for _, c := range children {
+			sum += c.Data.EndTime.Sub(c.Data.StartTime)
+		}
+		for _, iv := range union(root, children) {
+			busy += iv.end.Sub(iv.start)
+		}
+		if busy > 0 {
+			b.Parallelism = float64(sum) / float64(busy)
+		}
+	}
+
+	return b, nil
+}
+
+// walk appends the critical path of s up to end to path, latest segment
+// first. Starting at end, it repeatedly descends into the child which ended
+// last before the current time and continues from where that child started.
+// The time in between belongs to s itself.
+func (t tree) walk(s traceview.Span, end time.Time, path *[]Segment) {
+	start := s.Data.StartTime
+	cursor := end
+	for cursor.After(start) {
+		var next *traceview.Span
+		var nextEnd time.Time
+		children := t.childrenOf(s)
+		for i, c := range children {
+			if !c.Data.StartTime.Before(cursor) {
+				continue
+			}
+			// Children ending after the cursor, e.g. those which outlive
+			// their parent, are cut off.
+			ce := c.Data.EndTime
+			if ce.After(cursor) {
+				ce = cursor
+			}
+			if next == nil || ce.After(nextEnd) {
+				next, nextEnd = &children[i], ce
+			}
+		}
+		if next == nil {
+			break
+		}
+
+		if nextEnd.Before(cursor) {
+			*path = append(*path, Segment{Span: s, Start: nextEnd, End: cursor})
+		}
+		t.walk(*next, nextEnd, path)
+		cursor = next.Data.StartTime
+	}
+	if cursor.After(start) {
+		*path = append(*path, Segment{Span: s, Start: start, End: cursor})
+	}
+}
+
+// interval is a time interval.
+type interval struct {
+	start, end time.Time
+}
+
+// union returns the intervals during which at least one of children ran,
+// clipped to s and ordered by time.
+func union(s traceview.Span, children []traceview.Span) []interval {
+	ivs := make([]interval, 0, len(children))
+	for _, c := range children {
+		iv := interval{c.Data.StartTime, c.Data.EndTime}
+		if iv.start.Before(s.Data.StartTime) {
+			iv.start = s.Data.StartTime
+		}
+		if iv.end.After(s.Data.EndTime) {
+			iv.end = s.Data.EndTime
+		}
+		if iv.end.After(iv.start) {
+			ivs = append(ivs, iv)
+		}
+	}
+	sort.Slice(ivs, func(i, j int) bool { return ivs[i].start.Before(ivs[j].start) })
+
+	var merged []interval
+	for _, iv := range ivs {
+		if n := len(merged); n > 0 && !iv.start.After(merged[n-1].end) {
+			if iv.end.After(merged[n-1].end) {
+				merged[n-1].end = iv.end
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+
+	return merged
+}
+
+// gaps returns the intervals of s during which none of children ran.
+func gaps(s traceview.Span, children []traceview.Span) []Gap {
+	var out []Gap
+	cursor := s.Data.StartTime
+	for _, iv := range union(s, children) {
+		if iv.start.After(cursor) {
+			out = append(out, Gap{Span: s, Start: cursor, End: iv.start})
+		}
+		cursor = iv.end
+	}
+	if s.Data.EndTime.After(cursor) {
+		out = append(out, Gap{Span: s, Start: cursor, End: s.Data.EndTime})
+	}
+
+	return out
+}
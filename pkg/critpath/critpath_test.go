@@ -0,0 +1,179 @@
+package critpath
+
+import (
+	"testing"
+	"time"
+
+	"github.com/johananl/otel-demo/pkg/traceview"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/key"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+var start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// span returns a span of service with the given ID and parent ID running
+// from from to to milliseconds after start.
+func span(service string, id, parent byte, name string, from, to int) traceview.Span {
+	return traceview.Span{
+		Resource: []core.KeyValue{key.String("service.name", service)},
+		Data: &export.SpanData{
+			SpanContext:  core.SpanContext{SpanID: core.SpanID{id}},
+			ParentSpanID: core.SpanID{parent},
+			Name:         service + "/" + name,
+			StartTime:    start.Add(time.Duration(from) * time.Millisecond),
+			EndTime:      start.Add(time.Duration(to) * time.Millisecond),
+		},
+	}
+}
+
+// title returns the spans of a title generated by calling the three backends
+// either one after another or in parallel.
+func title(slow bool) []traceview.Span {
+	if slow {
+		return []traceview.Span{
+			span("frontend", 1, 0, "serve-http-request", 0, 100),
+			span("seniority", 2, 1, "get-seniority", 5, 25),
+			span("field", 3, 1, "get-field", 30, 60),
+			span("role", 4, 1, "get-role", 60, 90),
+		}
+	}
+
+	return []traceview.Span{
+		span("frontend", 1, 0, "serve-http-request", 0, 40),
+		span("seniority", 2, 1, "get-seniority", 5, 25),
+		span("field", 3, 1, "get-field", 5, 35),
+		span("role", 4, 1, "get-role", 6, 36),
+	}
+}
+
+func ms(n int) time.Duration {
+	return time.Duration(n) * time.Millisecond
+}
+
+func TestAnalyze(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		slow        bool
+		path        []string
+		pathTime    map[string]time.Duration
+		selfTime    map[string]time.Duration
+		gaps        int
+		parallelism float64
+	}{
+		{
+			name:        "sequential",
+			slow:        true,
+			path:        []string{"frontend", "seniority", "frontend", "field", "role", "frontend"},
+			pathTime:    map[string]time.Duration{"frontend": ms(20), "seniority": ms(20), "field": ms(30), "role": ms(30)},
+			selfTime:    map[string]time.Duration{"frontend": ms(20), "seniority": ms(20), "field": ms(30), "role": ms(30)},
+			gaps:        3,
+			parallelism: 1,
+		},
+		{
+			name: "fan-out",
+			// Seniority is cut off when role starts.
+			path:        []string{"frontend", "seniority", "role", "frontend"},
+			pathTime:    map[string]time.Duration{"frontend": ms(9), "seniority": ms(1), "role": ms(30)},
+			selfTime:    map[string]time.Duration{"frontend": ms(9), "seniority": ms(20), "field": ms(30), "role": ms(30)},
+			gaps:        2,
+			parallelism: 80.0 / 31,
+		},
+	} {
+		b, err := Analyze(title(tc.slow))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var path []string
+		var total time.Duration
+		for _, seg := range b.CriticalPath {
+			path = append(path, seg.Span.Service())
+			total += seg.Duration()
+		}
+		if len(path) != len(tc.path) {
+			t.Errorf("%s: got critical path %v, want %v", tc.name, path, tc.path)
+		} else {
+			for i := range path {
+				if path[i] != tc.path[i] {
+					t.Errorf("%s: got critical path %v, want %v", tc.name, path, tc.path)
+					break
+				}
+			}
+		}
+		if total != b.Duration {
+			t.Errorf("%s: critical path adds up to %s, want %s", tc.name, total, b.Duration)
+		}
+		for svc, want := range tc.pathTime {
+			if got := b.PathTime[svc]; got != want {
+				t.Errorf("%s: got %s of %s on the critical path, want %s", tc.name, got, svc, want)
+			}
+		}
+		for svc, want := range tc.selfTime {
+			if got := b.SelfTime[svc]; got != want {
+				t.Errorf("%s: got self time %s of %s, want %s", tc.name, got, svc, want)
+			}
+		}
+		if len(b.Gaps) != tc.gaps {
+			t.Errorf("%s: got %d gaps, want %d", tc.name, len(b.Gaps), tc.gaps)
+		}
+		if d := b.Parallelism - tc.parallelism; d > 0.001 || d < -0.001 {
+			t.Errorf("%s: got parallelism %.3f, want %.3f", tc.name, b.Parallelism, tc.parallelism)
+		}
+	}
+
+	if _, err := Analyze(nil); err != ErrNoSpans {
+		t.Errorf("got %v for no spans, want %v", err, ErrNoSpans)
+	}
+}
+
+func TestAnalyzeWithoutRoot(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		spans []traceview.Span
+	}{
+		{"own parent", []traceview.Span{span("frontend", 1, 1, "title", 0, 10)}},
+		{"cycle", []traceview.Span{
+			span("frontend", 1, 2, "title", 0, 10),
+			span("role", 2, 1, "get-role", 2, 8),
+		}},
+		// Spans without IDs have the zero span ID as their parent.
+		{"no IDs", []traceview.Span{
+			span("frontend", 0, 0, "title", 0, 10),
+			span("role", 0, 0, "get-role", 2, 8),
+		}},
+	} {
+		if _, err := Analyze(tc.spans); err != ErrNoRoot {
+			t.Errorf("%s: got %v, want %v", tc.name, err, ErrNoRoot)
+		}
+		// Cycles end the subtree rather than recursing forever.
+		if sub := Subtree(tc.spans, "title"); len(sub) == 0 || len(sub) > len(tc.spans) {
+			t.Errorf("%s: got %d spans below the title span", tc.name, len(sub))
+		}
+	}
+}
+
+func TestSubtreeAndAggregate(t *testing.T) {
+	spans := append([]traceview.Span{span("loadgen", 9, 0, "generate", 0, 200)}, title(true)...)
+	spans[1].Data.ParentSpanID = core.SpanID{9}
+	sub := Subtree(spans, "serve-http-request")
+	if len(sub) != 4 || sub[0].Service() != "frontend" {
+		t.Fatalf("got subtree of %d spans, want the 4 spans of the frontend", len(sub))
+	}
+	if Subtree(spans, "get-title") != nil {
+		t.Error("got a subtree of an unknown operation")
+	}
+
+	var breakdowns []Breakdown
+	for _, slow := range []bool{true, false, false} {
+		b, err := Analyze(title(slow))
+		if err != nil {
+			t.Fatal(err)
+		}
+		breakdowns = append(breakdowns, b)
+	}
+	st := Aggregate("all", breakdowns)
+	if st.Traces != 3 || st.Mean != ms(60) || st.P50 != ms(40) || st.P95 != ms(100) || st.PathTime["role"] != ms(30) {
+		t.Errorf("got stats %+v", st)
+	}
+}
@@ -0,0 +1,81 @@
+package critpath
+
+import (
+	"sort"
+	"time"
+)
+
+// Stats summarizes the breakdowns of a group of traces, e.g. of the titles
+// generated in slow mode.
+type Stats struct {
+	Name   string
+	Traces int
+	// Mean, P50 and P95 are of the durations of the traces.
+	Mean, P50, P95 time.Duration
+	// PathTime and SelfTime map services to their mean time on the critical
+	// path and mean self time per trace.
+	PathTime map[string]time.Duration
+	SelfTime map[string]time.Duration
+	// GapTime is the mean time per trace during which spans waited for
+	// none of their children.
+	GapTime     time.Duration
+	Parallelism float64
+}
+
+// Aggregate summarizes breakdowns as the group name.
+func Aggregate(name string, breakdowns []Breakdown) Stats {
+	st := Stats{
+		Name:     name,
+		Traces:   len(breakdowns),
+		PathTime: map[string]time.Duration{},
+		SelfTime: map[string]time.Duration{},
+	}
+	if len(breakdowns) == 0 {
+		return st
+	}
+
+	durations := make([]time.Duration, len(breakdowns))
+	var total, gaps time.Duration
+	for i, b := range breakdowns {
+		durations[i] = b.Duration
+		total += b.Duration
+		gaps += b.GapTime()
+		st.Parallelism += b.Parallelism
+		for svc, d := range b.PathTime {
+			st.PathTime[svc] += d
+		}
+		for svc, d := range b.SelfTime {
+			st.SelfTime[svc] += d
+		}
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	n := time.Duration(len(breakdowns))
+	st.Mean = total / n
+	st.P50 = percentile(durations, 0.5)
+	st.P95 = percentile(durations, 0.95)
+	st.GapTime = gaps / n
+	st.Parallelism /= float64(len(breakdowns))
+	for svc := range st.PathTime {
+		st.PathTime[svc] /= n
+	}
+	for svc := range st.SelfTime {
+		st.SelfTime[svc] /= n
+	}
+
+	return st
+}
+
+// percentile returns the p-th percentile of sorted durations using the
+// nearest-rank method.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(p*float64(len(sorted))+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+
+	return sorted[i]
+}
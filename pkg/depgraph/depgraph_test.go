@@ -0,0 +1,105 @@
+package depgraph
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/key"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	"google.golang.org/grpc/codes"
+)
+
+var now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func resource(service string) []core.KeyValue {
+	return []core.KeyValue{key.String("service.name", service)}
+}
+
+// title returns the spans of the frontend and of the backends which served a
+// title, the calls of the backends lasting d.
+func title(n byte, end time.Time, d time.Duration, status codes.Code) (frontend []*export.SpanData, backends map[string]*export.SpanData) {
+	root := &export.SpanData{
+		SpanContext: core.SpanContext{SpanID: core.SpanID{n}},
+		StartTime:   end.Add(-2 * d),
+		EndTime:     end,
+	}
+	backends = map[string]*export.SpanData{}
+	for i, svc := range []string{"seniority", "field", "role"} {
+		backends[svc] = &export.SpanData{
+			SpanContext:  core.SpanContext{SpanID: core.SpanID{n, byte(i + 1)}},
+			ParentSpanID: root.SpanContext.SpanID,
+			StartTime:    end.Add(-d - time.Millisecond),
+			EndTime:      end.Add(-time.Millisecond),
+			Status:       status,
+		}
+	}
+
+	return []*export.SpanData{root}, backends
+}
+
+func TestGraph(t *testing.T) {
+	g := New(time.Minute)
+	g.now = func() time.Time { return now }
+
+	for i := 1; i <= 10; i++ {
+		status := codes.OK
+		if i == 10 {
+			status = codes.Unavailable
+		}
+		frontend, backends := title(byte(i), now, time.Duration(i)*time.Millisecond, status)
+		// The spans of the backends usually arrive first, except for role's.
+		g.Add(resource("seniority"), []*export.SpanData{backends["seniority"]})
+		g.Add(resource("field"), []*export.SpanData{backends["field"]})
+		g.Add(resource("frontend"), frontend)
+		g.Add(resource("role"), []*export.SpanData{backends["role"]})
+	}
+	// Calls which ended before the window are left out.
+	frontend, backends := title(11, now.Add(-2*time.Minute), time.Second, codes.OK)
+	g.Add(resource("frontend"), frontend)
+	g.Add(resource("role"), []*export.SpanData{backends["role"]})
+
+	snap := g.Snapshot()
+	if strings.Join(snap.Services, ",") != "field,frontend,role,seniority" {
+		t.Errorf("got services %v", snap.Services)
+	}
+	if len(snap.Edges) != 3 {
+		t.Fatalf("got %d edges, want 3", len(snap.Edges))
+	}
+	for i, want := range []string{"field", "role", "seniority"} {
+		e := snap.Edges[i]
+		if e.From != "frontend" || e.To != want {
+			t.Errorf("got edge %s -> %s, want frontend -> %s", e.From, e.To, want)
+		}
+		if e.Calls != 10 || e.Errors != 1 || e.ErrorRate() != 0.1 {
+			t.Errorf("%s: got %d calls, %d errors", want, e.Calls, e.Errors)
+		}
+		if e.P50 != 5*time.Millisecond || e.P95 != 10*time.Millisecond || e.P99 != 10*time.Millisecond {
+			t.Errorf("%s: got percentiles %s, %s, %s", want, e.P50, e.P95, e.P99)
+		}
+	}
+
+	h := Handler(g)
+	for _, tc := range []struct {
+		format string
+		status int
+		want   string
+	}{
+		{"", http.StatusOK, `"from": "frontend"`},
+		{"dot", http.StatusOK, `"frontend" -> "role" [label="10 calls, 10.0% errors, p50 5ms, p95 10ms, p99 10ms", color=red];`},
+		{"mermaid", http.StatusOK, `s1 -->|"10 calls, 10.0% errors, p50 5ms, p95 10ms, p99 10ms"| s2`},
+		{"svg", http.StatusBadRequest, "unknown graph format"},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", Path+"?format="+tc.format, nil))
+		if rec.Code != tc.status {
+			t.Errorf("%q: got status %d, want %d", tc.format, rec.Code, tc.status)
+		}
+		if body := rec.Body.String(); !strings.Contains(body, tc.want) {
+			t.Errorf("%q: body doesn't contain %q:\n%s", tc.format, tc.want, body)
+		}
+	}
+}
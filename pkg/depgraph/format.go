@@ -0,0 +1,121 @@
+package depgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Formats are the formats graphs can be written in.
+var Formats = []string{"json", "dot", "mermaid"}
+
+// Write writes snap in format, one of Formats.
+func Write(w io.Writer, snap Snapshot, format string) error {
+	switch format {
+	case "json":
+		return writeJSON(w, snap)
+	case "dot":
+		return writeDOT(w, snap)
+	case "mermaid":
+		return writeMermaid(w, snap)
+	}
+
+	return fmt.Errorf("unknown graph format %q, want one of %s", format, strings.Join(Formats, ", "))
+}
+
+// edgeJSON is the JSON representation of EdgeStats.
+type edgeJSON struct {
+	From      string  `json:"from"`
+	To        string  `json:"to"`
+	Calls     int     `json:"calls"`
+	Errors    int     `json:"errors"`
+	ErrorRate float64 `json:"error_rate"`
+	P50       string  `json:"p50"`
+	P95       string  `json:"p95"`
+	P99       string  `json:"p99"`
+}
+
+func writeJSON(w io.Writer, snap Snapshot) error {
+	out := struct {
+		Services []string   `json:"services"`
+		Edges    []edgeJSON `json:"edges"`
+	}{
+		Services: snap.Services,
+		Edges:    make([]edgeJSON, len(snap.Edges)),
+	}
+	if out.Services == nil {
+		out.Services = []string{}
+	}
+	for i, e := range snap.Edges {
+		out.Edges[i] = edgeJSON{
+			From:      e.From,
+			To:        e.To,
+			Calls:     e.Calls,
+			Errors:    e.Errors,
+			ErrorRate: e.ErrorRate(),
+			P50:       e.P50.String(),
+			P95:       e.P95.String(),
+			P99:       e.P99.String(),
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(out)
+}
+
+// label describes the calls along an edge in one line.
+func label(e EdgeStats) string {
+	return fmt.Sprintf("%d calls, %.1f%% errors, p50 %s, p95 %s, p99 %s",
+		e.Calls, 100*e.ErrorRate(), round(e.P50), round(e.P95), round(e.P99))
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(10 * time.Microsecond)
+}
+
+func writeDOT(w io.Writer, snap Snapshot) error {
+	var b strings.Builder
+	b.WriteString("digraph services {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	for _, svc := range snap.Services {
+		fmt.Fprintf(&b, "\t%q;\n", svc)
+	}
+	for _, e := range snap.Edges {
+		attrs := ""
+		if e.Errors > 0 {
+			attrs = ", color=red"
+		}
+		fmt.Fprintf(&b, "\t%q -> %q [label=%q%s];\n", e.From, e.To, label(e), attrs)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func writeMermaid(w io.Writer, snap Snapshot) error {
+	// Mermaid node IDs can't contain all the characters service names can,
+	// so nodes are numbered and labeled with the names.
+	ids := map[string]string{}
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for i, svc := range snap.Services {
+		ids[svc] = fmt.Sprintf("s%d", i)
+		fmt.Fprintf(&b, "    %s[%q]\n", ids[svc], svc)
+	}
+	for _, e := range snap.Edges {
+		// Edges of services whose own spans fell out of the window.
+		for _, svc := range []string{e.From, e.To} {
+			if _, ok := ids[svc]; !ok {
+				ids[svc] = fmt.Sprintf("s%d", len(ids))
+				fmt.Fprintf(&b, "    %s[%q]\n", ids[svc], svc)
+			}
+		}
+		fmt.Fprintf(&b, "    %s -->|%q| %s\n", ids[e.From], label(e), ids[e.To])
+	}
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
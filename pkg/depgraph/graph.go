@@ -0,0 +1,287 @@
+// Package depgraph derives the dependency graph of the services from their
+// spans: which service calls which, how often, how often the calls fail and
+// how long they take.
+//
+// A call is a span whose parent span was recorded by another service, e.g.
+// the handle-grpc-request span of seniority under the serve-http-request
+// span of the frontend. As the spans of a trace arrive from different
+// processes in no particular order, spans whose parent hasn't arrived yet are
+// kept until it does.
+package depgraph
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/johananl/otel-demo/pkg/traceview"
+	"go.opentelemetry.io/otel/api/core"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	"google.golang.org/grpc/codes"
+)
+
+// Edge is a caller and a callee.
+type Edge struct {
+	From, To string
+}
+
+// EdgeStats describes the calls along an edge.
+type EdgeStats struct {
+	Edge
+	Calls  int
+	Errors int
+	// P50, P95 and P99 are percentiles of the durations of the calls as
+	// seen by the callee.
+	P50, P95, P99 time.Duration
+}
+
+// ErrorRate returns the fraction of the calls which failed.
+func (e EdgeStats) ErrorRate() float64 {
+	if e.Calls == 0 {
+		return 0
+	}
+
+	return float64(e.Errors) / float64(e.Calls)
+}
+
+// Snapshot is the dependency graph at some point in time.
+type Snapshot struct {
+	// Services are sorted by name and include services without calls.
+	Services []string
+	// Edges are sorted by caller and callee.
+	Edges []EdgeStats
+}
+
+// call is a span of a callee.
+type call struct {
+	to       string
+	end      time.Time
+	duration time.Duration
+	failed   bool
+}
+
+// span is what the graph remembers about a span to attribute the calls of
+// its children.
+type span struct {
+	service string
+	end     time.Time
+}
+
+// Graph is a dependency graph which is updated as spans are added. It only
+// considers spans which ended within a sliding window. It is safe for
+// concurrent use.
+type Graph struct {
+	mu     sync.Mutex
+	window time.Duration
+	now    func() time.Time
+	// spans maps span IDs to the services which recorded them.
+	spans map[core.SpanID]span
+	// pending maps the IDs of spans which haven't arrived yet to the calls
+	// of their children.
+	pending  map[core.SpanID][]call
+	services map[string]time.Time
+	calls    map[Edge][]call
+	pruned   time.Time
+}
+
+// New returns a graph of the spans which ended within window. Zero keeps all
+// spans, e.g. to build the graph of a fixed set of traces. As nothing is ever
+// dropped then, it isn't suited to graphs fed for as long as a server runs.
+func New(window time.Duration) *Graph {
+	return &Graph{
+		window:   window,
+		now:      time.Now,
+		spans:    map[core.SpanID]span{},
+		pending:  map[core.SpanID][]call{},
+		services: map[string]time.Time{},
+		calls:    map[Edge][]call{},
+	}
+}
+
+// Add adds spans recorded by the process described by resource. It has the
+// signature of traceview.Store.Add, so the graph can be fed alongside a
+// store.
+func (g *Graph) Add(resource []core.KeyValue, spans []*export.SpanData) {
+	service := traceview.Span{Resource: resource}.Service()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, d := range spans {
+		id := d.SpanContext.SpanID
+		g.spans[id] = span{service: service, end: d.EndTime}
+		if d.EndTime.After(g.services[service]) {
+			g.services[service] = d.EndTime
+		}
+
+		// The calls of children which arrived first.
+		for _, c := range g.pending[id] {
+			g.record(service, c)
+		}
+		delete(g.pending, id)
+
+		if !d.ParentSpanID.IsValid() {
+			continue
+		}
+		c := call{
+			to:       service,
+			end:      d.EndTime,
+			duration: d.EndTime.Sub(d.StartTime),
+			failed:   d.Status != codes.OK,
+		}
+		if parent, ok := g.spans[d.ParentSpanID]; ok {
+			g.record(parent.service, c)
+		} else {
+			g.pending[d.ParentSpanID] = append(g.pending[d.ParentSpanID], c)
+		}
+	}
+
+	g.prune()
+}
+
+// AddSpans adds the spans of traces, e.g. as read from a store.
+func (g *Graph) AddSpans(spans []traceview.Span) {
+	for _, s := range spans {
+		g.Add(s.Resource, []*export.SpanData{s.Data})
+	}
+}
+
+// record records c if it was made by another service than the callee's.
+func (g *Graph) record(from string, c call) {
+	if from == c.to {
+		return
+	}
+	e := Edge{From: from, To: c.to}
+	g.calls[e] = append(g.calls[e], c)
+}
+
+// prune forgets what ended before the window, at most ten times per window.
+func (g *Graph) prune() {
+	if g.window <= 0 {
+		return
+	}
+	now := g.now()
+	if now.Sub(g.pruned) < g.window/10 {
+		return
+	}
+	g.pruned = now
+	cutoff := now.Add(-g.window)
+
+	for id, s := range g.spans {
+		if s.end.Before(cutoff) {
+			delete(g.spans, id)
+		}
+	}
+	for id, cs := range g.pending {
+		if cs = recent(cs, cutoff); len(cs) == 0 {
+			delete(g.pending, id)
+		} else {
+			g.pending[id] = cs
+		}
+	}
+	for svc, end := range g.services {
+		if end.Before(cutoff) {
+			delete(g.services, svc)
+		}
+	}
+	for e, cs := range g.calls {
+		if cs = recent(cs, cutoff); len(cs) == 0 {
+			delete(g.calls, e)
+		} else {
+			g.calls[e] = cs
+		}
+	}
+}
+
+// recent returns the calls in cs which ended at or after cutoff.
+func recent(cs []call, cutoff time.Time) []call {
+	kept := cs[:0]
+	for _, c := range cs {
+		if !c.end.Before(cutoff) {
+			kept = append(kept, c)
+		}
+	}
+
+	return kept
+}
+
+// Snapshot returns the current graph.
+func (g *Graph) Snapshot() Snapshot {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var cutoff time.Time
+	if g.window > 0 {
+		cutoff = g.now().Add(-g.window)
+	}
+
+	var snap Snapshot
+	for svc, end := range g.services {
+		if !end.Before(cutoff) {
+			snap.Services = append(snap.Services, svc)
+		}
+	}
+	sort.Strings(snap.Services)
+
+	for e, cs := range g.calls {
+		var durations []time.Duration
+		st := EdgeStats{Edge: e}
+		for _, c := range cs {
+			if c.end.Before(cutoff) {
+				continue
+			}
+			st.Calls++
+			if c.failed {
+				st.Errors++
+			}
+			durations = append(durations, c.duration)
+		}
+		if st.Calls == 0 {
+			continue
+		}
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		st.P50 = percentile(durations, 0.5)
+		st.P95 = percentile(durations, 0.95)
+		st.P99 = percentile(durations, 0.99)
+		snap.Edges = append(snap.Edges, st)
+	}
+	sort.Slice(snap.Edges, func(i, j int) bool {
+		a, b := snap.Edges[i], snap.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+
+	return snap
+}
+
+// percentile returns the p-th percentile of sorted durations using the
+// nearest-rank method.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(p*float64(len(sorted))+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+
+	return sorted[i]
+}
+
+// recorder is a store which also adds spans to a graph.
+type recorder struct {
+	traceview.Store
+	graph *Graph
+}
+
+// Record returns a store which adds spans to both st and g.
+func Record(st traceview.Store, g *Graph) traceview.Store {
+	return recorder{Store: st, graph: g}
+}
+
+func (r recorder) Add(resource []core.KeyValue, spans []*export.SpanData) {
+	r.Store.Add(resource, spans)
+	r.graph.Add(resource, spans)
+}
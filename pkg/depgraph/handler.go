@@ -0,0 +1,35 @@
+package depgraph
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// Path is the path the handler expects to be mounted on.
+const Path = "/debug/graph"
+
+// contentTypes maps formats to the content types they are served as.
+var contentTypes = map[string]string{
+	"json":    "application/json",
+	"dot":     "text/vnd.graphviz; charset=utf-8",
+	"mermaid": "text/plain; charset=utf-8",
+}
+
+// Handler serves the current graph of g as JSON, or in the format given by
+// the query parameter format, one of Formats.
+func Handler(g *Graph) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "json"
+		}
+
+		var b bytes.Buffer
+		if err := Write(&b, g.Snapshot(), format); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", contentTypes[format])
+		w.Write(b.Bytes())
+	})
+}
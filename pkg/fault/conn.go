@@ -0,0 +1,77 @@
+package fault
+
+import (
+	"net"
+	"sync"
+)
+
+// connTracker keeps track of the open connections of a listener by their
+// remote address, so that drop rules can close the connection of an RPC.
+type connTracker struct {
+	mu    sync.Mutex
+	conns map[string]net.Conn
+}
+
+func newConnTracker() *connTracker {
+	return &connTracker{conns: make(map[string]net.Conn)}
+}
+
+func (t *connTracker) add(c net.Conn) {
+	t.mu.Lock()
+	t.conns[c.RemoteAddr().String()] = c
+	t.mu.Unlock()
+}
+
+func (t *connTracker) remove(c net.Conn) {
+	t.mu.Lock()
+	if t.conns[c.RemoteAddr().String()] == c {
+		delete(t.conns, c.RemoteAddr().String())
+	}
+	t.mu.Unlock()
+}
+
+// close closes the connection from addr, if it is tracked.
+func (t *connTracker) close(addr net.Addr) {
+	t.mu.Lock()
+	c, ok := t.conns[addr.String()]
+	t.mu.Unlock()
+
+	if ok {
+		c.Close()
+	}
+}
+
+// Listener wraps l so that drop rules can close the connections it accepts.
+// Without it drop rules only fail the RPC with codes.Unavailable.
+func (inj *Injector) Listener(l net.Listener) net.Listener {
+	return &listener{Listener: l, conns: inj.conns}
+}
+
+type listener struct {
+	net.Listener
+	conns *connTracker
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	tc := &trackedConn{Conn: c, conns: l.conns}
+	l.conns.add(tc)
+
+	return tc, nil
+}
+
+// trackedConn removes itself from its tracker once closed.
+type trackedConn struct {
+	net.Conn
+	conns *connTracker
+	once  sync.Once
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(func() { c.conns.remove(c) })
+	return c.Conn.Close()
+}
@@ -0,0 +1,277 @@
+// Package fault injects failures into gRPC servers, so that the error paths
+// of their clients show up in traces.
+//
+// Faults are described by rules. A rule fires for a share of the requests
+// given by its rate and either returns an error status, drops the client
+// connection or hangs until the request's deadline is exceeded. For streaming
+// RPCs error rules are evaluated for every sent message, so that streams fail
+// partway through rather than up front.
+package fault
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Mode is the kind of a fault.
+type Mode string
+
+const (
+	// ModeError makes the RPC fail with a status code.
+	ModeError Mode = "error"
+	// ModeDrop closes the connection of the client.
+	ModeDrop Mode = "drop"
+	// ModeHang blocks the RPC until its context is done.
+	ModeHang Mode = "hang"
+)
+
+// Rule describes a fault.
+type Rule struct {
+	Mode Mode `json:"mode"`
+	// Code is the status code returned by error rules. It is ignored by all
+	// other modes.
+	Code string `json:"code,omitempty"`
+	// Rate is the probability with which the rule fires, between 0 and 1.
+	Rate float64 `json:"rate"`
+	// Method restricts the rule to RPCs whose full method name ends with
+	// Method, e.g. "GetSeniority". An empty method matches all RPCs.
+	Method string `json:"method,omitempty"`
+}
+
+// String returns the rule in the format accepted by Parse.
+func (r Rule) String() string {
+	s := string(r.Mode)
+	if r.Mode == ModeError {
+		s += ":" + r.Code
+	}
+	s += ":" + strconv.FormatFloat(r.Rate, 'g', -1, 64)
+	if r.Method != "" {
+		s += "@" + r.Method
+	}
+
+	return s
+}
+
+// Validate checks that the rule is well-formed.
+func (r Rule) Validate() error {
+	switch r.Mode {
+	case ModeError:
+		if _, err := parseCode(r.Code); err != nil {
+			return err
+		}
+	case ModeDrop, ModeHang:
+	default:
+		return fmt.Errorf("unknown fault mode %q", r.Mode)
+	}
+	if r.Rate < 0 || r.Rate > 1 {
+		return fmt.Errorf("rate must be between 0 and 1")
+	}
+
+	return nil
+}
+
+func (r Rule) matches(method string) bool {
+	return r.Method == "" || strings.HasSuffix(method, r.Method)
+}
+
+// parseCode parses a status code name such as "UNAVAILABLE" or "Unavailable".
+func parseCode(s string) (codes.Code, error) {
+	var c codes.Code
+	if err := c.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(s)))); err != nil {
+		return 0, fmt.Errorf("unknown status code %q", s)
+	}
+	if c == codes.OK {
+		return 0, fmt.Errorf("status code must not be OK")
+	}
+
+	return c, nil
+}
+
+// Parse parses a list of rules separated by semicolons. Every rule has the
+// form "<mode>[:<code>]:<rate>[@<method>]":
+//
+//	error:unavailable:0.1
+//	drop:0.01@GetSeniorities
+//	hang:0.05;error:internal:0.2@StreamSeniorities
+//
+// An empty spec yields no rules.
+func Parse(spec string) ([]Rule, error) {
+	var rules []Rule
+	for _, s := range strings.Split(spec, ";") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		var r Rule
+		if i := strings.Index(s, "@"); i >= 0 {
+			r.Method = s[i+1:]
+			s = s[:i]
+		}
+		parts := strings.Split(s, ":")
+		r.Mode = Mode(parts[0])
+
+		rate := ""
+		switch {
+		case r.Mode == ModeError && len(parts) == 3:
+			r.Code, rate = parts[1], parts[2]
+		case r.Mode != ModeError && len(parts) == 2:
+			rate = parts[1]
+		default:
+			return nil, fmt.Errorf("parsing fault rule %q: wrong number of arguments", s)
+		}
+
+		var err error
+		if r.Rate, err = strconv.ParseFloat(rate, 64); err != nil {
+			return nil, fmt.Errorf("parsing fault rule %q: %v", s, err)
+		}
+		if err := r.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid fault rule %q: %v", s, err)
+		}
+
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+// Injector injects the faults described by its rules. It is safe for
+// concurrent use and its rules can be replaced while serving.
+type Injector struct {
+	mu    sync.RWMutex
+	rules []Rule
+
+	conns *connTracker
+}
+
+// NewInjector returns an injector with the given rules.
+func NewInjector(rules []Rule) *Injector {
+	return &Injector{rules: rules, conns: newConnTracker()}
+}
+
+// Rules returns the current rules.
+func (inj *Injector) Rules() []Rule {
+	inj.mu.RLock()
+	defer inj.mu.RUnlock()
+
+	return append([]Rule(nil), inj.rules...)
+}
+
+// SetRules replaces the current rules.
+func (inj *Injector) SetRules(rules []Rule) error {
+	for _, r := range rules {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+	}
+
+	inj.mu.Lock()
+	inj.rules = append([]Rule(nil), rules...)
+	inj.mu.Unlock()
+
+	return nil
+}
+
+// pick returns the first rule of one of the given modes which matches method
+// and fires, if any.
+func (inj *Injector) pick(method string, modes ...Mode) (Rule, bool) {
+	inj.mu.RLock()
+	defer inj.mu.RUnlock()
+
+	for _, r := range inj.rules {
+		if !r.matches(method) || !hasMode(modes, r.Mode) {
+			continue
+		}
+		if rand.Float64() < r.Rate {
+			return r, true
+		}
+	}
+
+	return Rule{}, false
+}
+
+func hasMode(modes []Mode, m Mode) bool {
+	for _, mode := range modes {
+		if mode == m {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inject applies r to the RPC of ctx and returns the resulting error. The
+// fault is recorded on the current span.
+func (inj *Injector) inject(ctx context.Context, method string, r Rule) error {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		key.New("fault.injected").Bool(true),
+		key.New("fault.mode").String(string(r.Mode)),
+	)
+	span.AddEvent(ctx, "Injected fault",
+		key.New("fault.rule").String(r.String()),
+		key.New("method").String(method),
+	)
+
+	switch r.Mode {
+	case ModeDrop:
+		if p, ok := peer.FromContext(ctx); ok {
+			inj.conns.close(p.Addr)
+		}
+		return status.Error(codes.Unavailable, "connection dropped by fault injection")
+	case ModeHang:
+		<-ctx.Done()
+		return status.FromContextError(ctx.Err()).Err()
+	default:
+		code, _ := parseCode(r.Code)
+		span.SetAttributes(key.New("fault.code").String(code.String()))
+		return status.Errorf(code, "fault injected into %s", method)
+	}
+}
+
+// UnaryServerInterceptor injects faults into unary RPCs.
+func (inj *Injector) UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if r, ok := inj.pick(info.FullMethod, ModeError, ModeDrop, ModeHang); ok {
+		return nil, inj.inject(ctx, info.FullMethod, r)
+	}
+
+	return handler(ctx, req)
+}
+
+// StreamServerInterceptor injects faults into streaming RPCs. Drop and hang
+// rules are evaluated when the stream starts, error rules before every sent
+// message.
+func (inj *Injector) StreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if r, ok := inj.pick(info.FullMethod, ModeDrop, ModeHang); ok {
+		return inj.inject(ss.Context(), info.FullMethod, r)
+	}
+
+	return handler(srv, &serverStream{ServerStream: ss, inj: inj, method: info.FullMethod})
+}
+
+// serverStream fails sending messages according to the error rules of an
+// injector.
+type serverStream struct {
+	grpc.ServerStream
+	inj    *Injector
+	method string
+}
+
+func (s *serverStream) SendMsg(m interface{}) error {
+	if r, ok := s.inj.pick(s.method, ModeError); ok {
+		return s.inj.inject(s.Context(), s.method, r)
+	}
+
+	return s.ServerStream.SendMsg(m)
+}
@@ -0,0 +1,166 @@
+// Package server implements the field service.
+package server
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/johananl/otel-demo/pkg/latency"
+	"github.com/johananl/otel-demo/pkg/logging"
+	"github.com/johananl/otel-demo/pkg/words"
+	pb "github.com/johananl/otel-demo/proto/field"
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxBatchSize is the maximum number of words which can be requested at once.
+const maxBatchSize = 100
+
+const (
+	// defaultStreamInterval is the delay between two streamed words if the
+	// client doesn't specify one.
+	defaultStreamInterval = time.Second
+	// minStreamInterval is the shortest allowed delay between two streamed
+	// words.
+	minStreamInterval = 10 * time.Millisecond
+)
+
+// Server implements the field service.
+type Server struct {
+	pb.UnimplementedFieldServer
+	// latency is the profile applied to slow requests. It can be changed at
+	// runtime through the admin API.
+	latency *latency.Value
+}
+
+// New returns a field server which applies the latency profile held by lat to
+// slow requests.
+func New(lat *latency.Value) *Server {
+	return &Server{latency: lat}
+}
+
+func (s *Server) GetField(ctx context.Context, in *pb.FieldRequest) (*pb.FieldReply, error) {
+	logging.Infof("Received field request")
+
+	profile, err := latency.ForRequest(in.Slow, in.Latency, s.latency.Load())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := latency.Inject(ctx, profile); err != nil {
+		return nil, status.Errorf(status.FromContextError(err).Code(), "injecting delay: %v", err)
+	}
+
+	// A seeded request always yields the same word for the same locale and tags.
+	var rnd *rand.Rand
+	if in.Seed != 0 {
+		rnd = rand.New(rand.NewSource(in.Seed))
+	}
+
+	locale, list := fields.ForLocale(in.Locale)
+	selected, err := words.PickRand(rnd, list, in.Tags)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "selecting field: %v", err)
+	}
+
+	// Get current span. The span was created within the gRPC interceptor.
+	// We are just adding data to it here.
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		key.New("field.weight").Int(selected.Weight),
+		key.New("field.tags").String(strings.Join(selected.Tags, ",")),
+		key.New("seed").Int64(in.Seed),
+	)
+	span.AddEvent(ctx, "Selected field",
+		key.New("field").String(selected.Text),
+		key.New("locale").String(locale),
+	)
+
+	logging.Debugf("Selected field %q for locale %s", selected.Text, locale)
+
+	return &pb.FieldReply{Field: selected.Text}, nil
+}
+
+func (s *Server) GetFields(ctx context.Context, in *pb.FieldsRequest) (*pb.FieldsReply, error) {
+	logging.Infof("Received fields request for %d fields", in.Count)
+
+	if in.Count < 1 || in.Count > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "count must be between 1 and %d", maxBatchSize)
+	}
+
+	profile, err := latency.ForRequest(in.Slow, in.Latency, s.latency.Load())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := latency.Inject(ctx, profile); err != nil {
+		return nil, status.Errorf(status.FromContextError(err).Code(), "injecting delay: %v", err)
+	}
+
+	locale, list := fields.ForLocale(in.Locale)
+	selected := make([]string, 0, in.Count)
+	for i := int32(0); i < in.Count; i++ {
+		w, err := words.Pick(list, in.Tags)
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound, "selecting field: %v", err)
+		}
+		selected = append(selected, w.Text)
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(key.New("batch.size").Int(int(in.Count)))
+	span.AddEvent(ctx, "Selected fields",
+		key.New("fields").String(strings.Join(selected, ",")),
+		key.New("locale").String(locale),
+	)
+
+	return &pb.FieldsReply{Fields: selected}, nil
+}
+
+func (s *Server) StreamFields(in *pb.FieldStreamRequest, stream pb.Field_StreamFieldsServer) error {
+	logging.Infof("Received field stream request")
+
+	interval := time.Duration(in.IntervalMs) * time.Millisecond
+	if interval == 0 {
+		interval = defaultStreamInterval
+	}
+	if interval < minStreamInterval {
+		return status.Errorf(codes.InvalidArgument, "interval must be at least %v", minStreamInterval)
+	}
+
+	ctx := stream.Context()
+	locale, list := fields.ForLocale(in.Locale)
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		key.New("stream.interval_ms").Int64(interval.Nanoseconds()/1e6),
+		key.New("locale").String(locale),
+	)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for sent := int32(0); in.Count == 0 || sent < in.Count; sent++ {
+		if sent > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+
+		w, err := words.Pick(list, in.Tags)
+		if err != nil {
+			return status.Errorf(codes.NotFound, "selecting field: %v", err)
+		}
+		if err := stream.Send(&pb.FieldReply{Field: w.Text}); err != nil {
+			return err
+		}
+
+		span.AddEvent(ctx, "Sent field", key.New("field").String(w.Text))
+	}
+
+	return nil
+}
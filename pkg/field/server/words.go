@@ -0,0 +1,72 @@
+package server
+
+import "github.com/johananl/otel-demo/pkg/words"
+
+// fields holds the available fields per locale. Weights are relative within
+// a locale and tags allow clients to narrow down the selection.
+var fields = words.List{
+	"en": {
+		{Text: "marketing", Weight: 4, Tags: []string{"serious"}},
+		{Text: "dolphin", Weight: 1, Tags: []string{"silly"}},
+		{Text: "cat", Weight: 1, Tags: []string{"silly"}},
+		{Text: "penguin", Weight: 1, Tags: []string{"silly"}},
+		{Text: "engineering", Weight: 4, Tags: []string{"serious", "tech"}},
+		{Text: "aerospace", Weight: 2, Tags: []string{"serious", "tech"}},
+		{Text: "machinery", Weight: 2, Tags: []string{"tech"}},
+		{Text: "finance", Weight: 3, Tags: []string{"serious"}},
+		{Text: "strategy", Weight: 3, Tags: []string{"serious"}},
+		{Text: "beer", Weight: 1, Tags: []string{"silly"}},
+		{Text: "coffee", Weight: 2, Tags: []string{"silly"}},
+		{Text: "whisky", Weight: 1, Tags: []string{"silly"}},
+		{Text: "laundry", Weight: 1, Tags: []string{"silly"}},
+		{Text: "socks", Weight: 1, Tags: []string{"silly"}},
+	},
+	"de": {
+		{Text: "marketing", Weight: 4, Tags: []string{"serious"}},
+		{Text: "delfin", Weight: 1, Tags: []string{"silly"}},
+		{Text: "katzen", Weight: 1, Tags: []string{"silly"}},
+		{Text: "pinguin", Weight: 1, Tags: []string{"silly"}},
+		{Text: "technik", Weight: 4, Tags: []string{"serious", "tech"}},
+		{Text: "raumfahrt", Weight: 2, Tags: []string{"serious", "tech"}},
+		{Text: "maschinen", Weight: 2, Tags: []string{"tech"}},
+		{Text: "finanz", Weight: 3, Tags: []string{"serious"}},
+		{Text: "strategie", Weight: 3, Tags: []string{"serious"}},
+		{Text: "bier", Weight: 1, Tags: []string{"silly"}},
+		{Text: "kaffee", Weight: 2, Tags: []string{"silly"}},
+		{Text: "whisky", Weight: 1, Tags: []string{"silly"}},
+		{Text: "wäsche", Weight: 1, Tags: []string{"silly"}},
+		{Text: "socken", Weight: 1, Tags: []string{"silly"}},
+	},
+	"es": {
+		{Text: "marketing", Weight: 4, Tags: []string{"serious"}},
+		{Text: "delfines", Weight: 1, Tags: []string{"silly"}},
+		{Text: "gatos", Weight: 1, Tags: []string{"silly"}},
+		{Text: "pingüinos", Weight: 1, Tags: []string{"silly"}},
+		{Text: "ingeniería", Weight: 4, Tags: []string{"serious", "tech"}},
+		{Text: "naves espaciales", Weight: 2, Tags: []string{"serious", "tech"}},
+		{Text: "maquinaria", Weight: 2, Tags: []string{"tech"}},
+		{Text: "finanzas", Weight: 3, Tags: []string{"serious"}},
+		{Text: "estrategia", Weight: 3, Tags: []string{"serious"}},
+		{Text: "cerveza", Weight: 1, Tags: []string{"silly"}},
+		{Text: "café", Weight: 2, Tags: []string{"silly"}},
+		{Text: "whisky", Weight: 1, Tags: []string{"silly"}},
+		{Text: "lavandería", Weight: 1, Tags: []string{"silly"}},
+		{Text: "calcetines", Weight: 1, Tags: []string{"silly"}},
+	},
+	"fr": {
+		{Text: "marketing", Weight: 4, Tags: []string{"serious"}},
+		{Text: "des dauphins", Weight: 1, Tags: []string{"silly"}},
+		{Text: "des chats", Weight: 1, Tags: []string{"silly"}},
+		{Text: "des manchots", Weight: 1, Tags: []string{"silly"}},
+		{Text: "de l'ingénierie", Weight: 4, Tags: []string{"serious", "tech"}},
+		{Text: "aérospatial", Weight: 2, Tags: []string{"serious", "tech"}},
+		{Text: "des machines", Weight: 2, Tags: []string{"tech"}},
+		{Text: "financier", Weight: 3, Tags: []string{"serious"}},
+		{Text: "stratégique", Weight: 3, Tags: []string{"serious"}},
+		{Text: "de la bière", Weight: 1, Tags: []string{"silly"}},
+		{Text: "du café", Weight: 2, Tags: []string{"silly"}},
+		{Text: "du whisky", Weight: 1, Tags: []string{"silly"}},
+		{Text: "de la blanchisserie", Weight: 1, Tags: []string{"silly"}},
+		{Text: "des chaussettes", Weight: 1, Tags: []string{"silly"}},
+	},
+}
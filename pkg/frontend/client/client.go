@@ -0,0 +1,315 @@
+// Package client is a typed Go client for the frontend HTTP API described by
+// the OpenAPI specification served at /api/openapi.json. It covers every
+// operation of the specification.
+//
+// Every call is traced in a client span and the trace context is propagated
+// to the frontend, so that the backend spans join the caller's trace.
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/plugin/httptrace"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// DefaultBaseURL is the address of a locally running frontend.
+	DefaultBaseURL = "http://localhost:8080"
+	// MinStreamInterval is the shortest interval between streamed titles
+	// the API accepts.
+	MinStreamInterval = 10 * time.Millisecond
+)
+
+// Title is a generated title.
+type Title struct {
+	Seniority string `json:"seniority"`
+	Field     string `json:"field"`
+	Role      string `json:"role"`
+	Locale    string `json:"locale"`
+	Title     string `json:"title"`
+}
+
+// Options controls how titles are generated.
+type Options struct {
+	// Slow makes the frontend call the backends sequentially and ask them to
+	// respond slowly.
+	Slow bool
+	// Lang selects the locale of the title.
+	Lang string
+	// Tags restricts the words to those carrying at least one of the tags.
+	Tags []string
+	// Latency is a latency profile spec applied by the backends.
+	Latency string
+}
+
+// Error is returned when the API responds with a non-200 status.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("frontend API returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// Client calls the frontend HTTP API.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	tracer     trace.Tracer
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests. http.DefaultClient is
+// used by default.
+func WithHTTPClient(c *http.Client) Option {
+	return func(cl *Client) {
+		cl.httpClient = c
+	}
+}
+
+// WithTracer sets the tracer used for client spans. By default a tracer of the
+// global trace provider is used.
+func WithTracer(tr trace.Tracer) Option {
+	return func(cl *Client) {
+		cl.tracer = tr
+	}
+}
+
+// New returns a client for the frontend at baseURL.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.tracer == nil {
+		c.tracer = global.TraceProvider().Tracer("frontend-client")
+	}
+
+	return c
+}
+
+// GetTitle generates a single title.
+func (c *Client) GetTitle(ctx context.Context, opts Options) (*Title, error) {
+	var t Title
+	if err := c.get(ctx, "getTitle", "/api", opts.query(), &t); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// GetTitles generates count titles in a single request.
+func (c *Client) GetTitles(ctx context.Context, count int, opts Options) ([]Title, error) {
+	q := opts.query()
+	q.Set("count", strconv.Itoa(count))
+
+	var ts []Title
+	if err := c.get(ctx, "getTitles", "/api/titles", q, &ts); err != nil {
+		return nil, err
+	}
+
+	return ts, nil
+}
+
+// GetSpec returns the OpenAPI specification of the API.
+func (c *Client) GetSpec(ctx context.Context) (json.RawMessage, error) {
+	var spec json.RawMessage
+	if err := c.get(ctx, "getSpec", "/api/openapi.json", nil, &spec); err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+// StreamOptions controls a stream of titles.
+type StreamOptions struct {
+	// Interval is the delay between two titles in whole milliseconds, at
+	// least MinStreamInterval. Zero means the default of one second.
+	Interval time.Duration
+	// Count is the number of titles to stream. Zero means unlimited.
+	Count int
+	// Lang selects the locale of the titles.
+	Lang string
+	// Tags restricts the words to those carrying at least one of the tags.
+	Tags []string
+}
+
+// StreamTitles streams titles and calls fn for every one of them until
+// opts.Count titles were sent, the stream fails, ctx is done or fn returns an
+// error, which StreamTitles then returns.
+func (c *Client) StreamTitles(ctx context.Context, opts StreamOptions, fn func(Title) error) error {
+	if opts.Interval != 0 && opts.Interval < MinStreamInterval {
+		return fmt.Errorf("stream interval %v is shorter than the minimum of %v", opts.Interval, MinStreamInterval)
+	}
+
+	ctx, span := c.tracer.Start(ctx, "streamTitles", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	q := url.Values{}
+	if opts.Interval > 0 {
+		q.Set("interval", strconv.FormatInt(int64(opts.Interval/time.Millisecond), 10))
+	}
+	if opts.Count > 0 {
+		q.Set("count", strconv.Itoa(opts.Count))
+	}
+	if opts.Lang != "" {
+		q.Set("lang", opts.Lang)
+	}
+	for _, t := range opts.Tags {
+		q.Add("tag", t)
+	}
+
+	resp, err := c.do(ctx, span, "streamTitles", "/api/stream", q)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Events are lines of fields terminated by an empty line.
+	var event, data string
+	titles := 0
+	defer func() {
+		span.SetAttributes(key.New("stream.titles").Int(titles))
+	}()
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+			continue
+		case strings.HasPrefix(line, "data:"):
+			data = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			continue
+		case line != "":
+			continue
+		case event == "" && data == "":
+			continue
+		}
+
+		switch event {
+		case "end":
+			return nil
+		case "error":
+			span.SetStatus(codes.Unknown)
+			var e struct {
+				Error string `json:"error"`
+			}
+			json.Unmarshal([]byte(data), &e)
+			return fmt.Errorf("stream failed: %s", e.Error)
+		}
+		var t Title
+		if err := json.Unmarshal([]byte(data), &t); err != nil {
+			span.SetStatus(codes.Internal)
+			return fmt.Errorf("decoding title: %v", err)
+		}
+		titles++
+		if err := fn(t); err != nil {
+			return err
+		}
+		event, data = "", ""
+	}
+	if err := sc.Err(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		span.SetStatus(codes.Unavailable)
+		return fmt.Errorf("reading stream: %v", err)
+	}
+
+	// The frontend always ends finite streams with an end event.
+	span.SetStatus(codes.Unavailable)
+	return errors.New("stream closed unexpectedly")
+}
+
+// get performs a traced GET request and decodes the JSON response into v.
+func (c *Client) get(ctx context.Context, operation, path string, q url.Values, v interface{}) error {
+	ctx, span := c.tracer.Start(ctx, operation, trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	resp, err := c.do(ctx, span, operation, path, q)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		span.SetStatus(codes.Internal)
+		return fmt.Errorf("decoding response: %v", err)
+	}
+
+	return nil
+}
+
+// do performs a GET request within span. It returns the response if its
+// status is 200.
+func (c *Client) do(ctx context.Context, span trace.Span, operation, path string, q url.Values) (*http.Response, error) {
+	u := c.baseURL + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	span.SetAttributes(httptrace.URLKey.String(u))
+
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		span.SetStatus(codes.Internal)
+		return nil, fmt.Errorf("creating request: %v", err)
+	}
+	req = req.WithContext(ctx)
+	httptrace.Inject(ctx, req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		span.SetStatus(codes.Unavailable)
+		return nil, fmt.Errorf("calling %s: %v", operation, err)
+	}
+
+	span.SetAttributes(key.New("http.status_code").Int(resp.StatusCode))
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		span.SetStatus(codes.Unknown)
+		b, _ := ioutil.ReadAll(resp.Body)
+		return nil, &Error{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(b))}
+	}
+
+	return resp, nil
+}
+
+func (o Options) query() url.Values {
+	q := url.Values{}
+	if o.Slow {
+		q.Set("slow", "true")
+	}
+	if o.Lang != "" {
+		q.Set("lang", o.Lang)
+	}
+	for _, t := range o.Tags {
+		q.Add("tag", t)
+	}
+	if o.Latency != "" {
+		q.Set("latency", o.Latency)
+	}
+
+	return q
+}
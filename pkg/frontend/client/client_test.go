@@ -0,0 +1,155 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johananl/otel-demo/pkg/frontend/client"
+	"github.com/johananl/otel-demo/pkg/frontend/openapi"
+	"github.com/johananl/otel-demo/pkg/tracetest"
+)
+
+func TestClient(t *testing.T) {
+	env := tracetest.Start(t)
+	defer env.Close()
+	srv := httptest.NewServer(env.Stack().Frontend.Handler(""))
+	defer srv.Close()
+
+	c := client.New(srv.URL)
+	ctx := context.Background()
+
+	if title, err := c.GetTitle(ctx, client.Options{Lang: "en"}); err != nil || title.Title == "" {
+		t.Errorf("getTitle: got %+v, %v", title, err)
+	}
+	if titles, err := c.GetTitles(ctx, 3, client.Options{Slow: true}); err != nil || len(titles) != 3 {
+		t.Errorf("getTitles: got %d titles, %v, want 3", len(titles), err)
+	}
+
+	var streamed []client.Title
+	err := c.StreamTitles(ctx, client.StreamOptions{Interval: 10 * time.Millisecond, Count: 3}, func(title client.Title) error {
+		streamed = append(streamed, title)
+		return nil
+	})
+	if err != nil || len(streamed) != 3 || streamed[2].Title == "" {
+		t.Errorf("streamTitles: got %+v, %v, want 3 titles", streamed, err)
+	}
+	for _, d := range []time.Duration{time.Microsecond, time.Millisecond, -time.Second} {
+		err = c.StreamTitles(ctx, client.StreamOptions{Interval: d}, func(client.Title) error { return nil })
+		if _, ok := err.(*client.Error); err == nil || ok {
+			t.Errorf("streamTitles: got %v for an interval of %v, want it rejected before the request", err, d)
+		}
+	}
+
+	if spec, err := c.GetSpec(ctx); err != nil || !json.Valid(spec) {
+		t.Errorf("getSpec: got %d bytes, %v", len(spec), err)
+	}
+}
+
+// operation is an operation of the OpenAPI specification.
+type operation struct {
+	OperationID string `json:"operationId"`
+	Parameters  []struct {
+		Ref      string `json:"$ref"`
+		Name     string `json:"name"`
+		In       string `json:"in"`
+		Required bool   `json:"required"`
+	} `json:"parameters"`
+}
+
+// TestSpec checks that the client calls every operation of the specification
+// with its paths and parameters.
+func TestSpec(t *testing.T) {
+	var doc struct {
+		Paths      map[string]map[string]operation `json:"paths"`
+		Components struct {
+			Parameters map[string]struct {
+				Name string `json:"name"`
+				In   string `json:"in"`
+			} `json:"parameters"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal([]byte(openapi.Spec), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	type request struct {
+		method, path string
+		query        url.Values
+	}
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, request{r.Method, r.URL.Path, r.URL.Query()})
+		switch r.URL.Path {
+		case "/api/titles":
+			fmt.Fprint(w, "[]")
+		case "/api/stream":
+			fmt.Fprint(w, "event: end\n\n")
+		default:
+			fmt.Fprint(w, "{}")
+		}
+	}))
+	defer srv.Close()
+
+	// Every option is set, so that every parameter is sent.
+	c := client.New(srv.URL)
+	ctx := context.Background()
+	opts := client.Options{Slow: true, Lang: "en", Tags: []string{"tech"}, Latency: "fixed:1ms"}
+	calls := []error{
+		func() error { _, err := c.GetTitle(ctx, opts); return err }(),
+		func() error { _, err := c.GetTitles(ctx, 2, opts); return err }(),
+		c.StreamTitles(ctx, client.StreamOptions{Interval: 20 * time.Millisecond, Count: 2, Lang: "en", Tags: []string{"tech"}}, func(client.Title) error { return nil }),
+		func() error { _, err := c.GetSpec(ctx); return err }(),
+	}
+	for _, err := range calls {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	called := map[string]bool{}
+	for _, r := range requests {
+		op, ok := doc.Paths[r.path][strings.ToLower(r.method)]
+		if !ok {
+			t.Errorf("%s %s isn't in the specification", r.method, r.path)
+			continue
+		}
+		called[op.OperationID] = true
+
+		declared := map[string]bool{}
+		for _, p := range op.Parameters {
+			if p.Ref != "" {
+				ref := doc.Components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]
+				p.Name, p.In = ref.Name, ref.In
+			}
+			if p.In != "query" {
+				continue
+			}
+			declared[p.Name] = true
+			if p.Required && r.query.Get(p.Name) == "" {
+				t.Errorf("%s: required parameter %s isn't sent", op.OperationID, p.Name)
+			}
+		}
+		for name := range r.query {
+			if !declared[name] {
+				t.Errorf("%s: parameter %s isn't in the specification", op.OperationID, name)
+			}
+		}
+		if len(declared) != len(r.query) {
+			t.Errorf("%s: sent parameters %v, specification declares %v", op.OperationID, r.query, declared)
+		}
+	}
+	for path, ops := range doc.Paths {
+		for method, op := range ops {
+			if !called[op.OperationID] {
+				t.Errorf("operation %s of %s %s isn't covered by the client", op.OperationID, method, path)
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package i18n
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultLocale is used when the client doesn't ask for a supported locale.
+const DefaultLocale = "en"
+
+// layout describes how a title is assembled in a given locale.
+type layout struct {
+	// format is the title template. The placeholders {seniority}, {field} and
+	// {role} are replaced with the words returned by the backend services.
+	format string
+	// capitalizeAll capitalizes every placeholder rather than only the first
+	// letter of the title.
+	capitalizeAll bool
+}
+
+// layouts holds the title layout for every supported locale. English and
+// German put the seniority before the nouns, whereas Spanish and French are
+// noun-adjective languages in which the seniority follows the role.
+var layouts = map[string]layout{
+	"en": {format: "{seniority} {field} {role}", capitalizeAll: true},
+	"de": {format: "{seniority} {field}-{role}", capitalizeAll: true},
+	"es": {format: "{role} de {field} {seniority}"},
+	"fr": {format: "{role} {field} {seniority}"},
+}
+
+// Supported returns true if titles can be assembled for the given locale.
+func Supported(locale string) bool {
+	_, ok := layouts[locale]
+	return ok
+}
+
+// Locale picks the locale for an HTTP request. An explicit "lang" query
+// parameter takes precedence over the Accept-Language header. Regional
+// variants are matched by their base language. DefaultLocale is returned if
+// no supported locale was requested.
+func Locale(r *http.Request) string {
+	if l := Match(r.URL.Query().Get("lang")); l != "" {
+		return l
+	}
+
+	for _, tag := range parseAcceptLanguage(r.Header.Get("Accept-Language")) {
+		if l := Match(tag); l != "" {
+			return l
+		}
+	}
+
+	return DefaultLocale
+}
+
+// Title assembles a title from the given words using the word order of the
+// given locale.
+func Title(locale, seniority, field, role string) string {
+	l, ok := layouts[locale]
+	if !ok {
+		l = layouts[DefaultLocale]
+	}
+
+	if l.capitalizeAll {
+		seniority = capitalize(seniority)
+		field = capitalize(field)
+		role = capitalize(role)
+	}
+
+	title := strings.NewReplacer(
+		"{seniority}", seniority,
+		"{field}", field,
+		"{role}", role,
+	).Replace(l.format)
+
+	return capitalize(title)
+}
+
+// Match returns the supported locale matching the given language tag or an
+// empty string.
+func Match(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return ""
+	}
+	if Supported(tag) {
+		return tag
+	}
+	if i := strings.IndexAny(tag, "-_"); i > 0 && Supported(tag[:i]) {
+		return tag[:i]
+	}
+
+	return ""
+}
+
+// parseAcceptLanguage returns the language tags of an Accept-Language header
+// ordered by their quality value.
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+				q = v
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	res := make([]string, len(tags))
+	for i, t := range tags {
+		res[i] = t.tag
+	}
+
+	return res
+}
+
+// capitalize upper-cases the first letter of s.
+func capitalize(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+
+	return string(unicode.ToUpper(r)) + s[n:]
+}
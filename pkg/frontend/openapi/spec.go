@@ -0,0 +1,188 @@
+package openapi
+
+// Spec is the OpenAPI 3 specification of the frontend HTTP API.
+const Spec = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "Fake title generator",
+    "description": "Generates fake job titles from the words returned by the seniority, field and role services.",
+    "version": "1.0.0"
+  },
+  "servers": [
+    {"url": "http://localhost:8080"}
+  ],
+  "paths": {
+    "/api": {
+      "get": {
+        "operationId": "getTitle",
+        "summary": "Generate a single title.",
+        "parameters": [
+          {"$ref": "#/components/parameters/slow"},
+          {"$ref": "#/components/parameters/lang"},
+          {"$ref": "#/components/parameters/tag"},
+          {"$ref": "#/components/parameters/latency"}
+        ],
+        "responses": {
+          "200": {
+            "description": "A generated title.",
+            "content": {
+              "application/json": {
+                "schema": {"$ref": "#/components/schemas/Title"}
+              }
+            }
+          },
+          "400": {"$ref": "#/components/responses/BadRequest"},
+          "500": {"$ref": "#/components/responses/BackendError"}
+        }
+      }
+    },
+    "/api/titles": {
+      "get": {
+        "operationId": "getTitles",
+        "summary": "Generate several titles using one batch call per backend.",
+        "parameters": [
+          {
+            "name": "count",
+            "in": "query",
+            "required": true,
+            "description": "Number of titles to generate.",
+            "schema": {"type": "integer", "minimum": 1, "maximum": 100}
+          },
+          {"$ref": "#/components/parameters/slow"},
+          {"$ref": "#/components/parameters/lang"},
+          {"$ref": "#/components/parameters/tag"},
+          {"$ref": "#/components/parameters/latency"}
+        ],
+        "responses": {
+          "200": {
+            "description": "The generated titles.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "array",
+                  "items": {"$ref": "#/components/schemas/Title"}
+                }
+              }
+            }
+          },
+          "400": {"$ref": "#/components/responses/BadRequest"},
+          "500": {"$ref": "#/components/responses/BackendError"}
+        }
+      }
+    },
+    "/api/stream": {
+      "get": {
+        "operationId": "streamTitles",
+        "summary": "Stream titles as Server-Sent Events.",
+        "description": "Every generated title is sent as a data event containing a Title object. An end event is sent once count titles were sent and an error event is sent if a backend fails.",
+        "parameters": [
+          {
+            "name": "interval",
+            "in": "query",
+            "description": "Delay between two titles in milliseconds, at least 10. 0 means the default of one second.",
+            "schema": {"type": "integer", "minimum": 0}
+          },
+          {
+            "name": "count",
+            "in": "query",
+            "description": "Number of titles to send. 0 means unlimited.",
+            "schema": {"type": "integer", "minimum": 0}
+          },
+          {"$ref": "#/components/parameters/lang"},
+          {"$ref": "#/components/parameters/tag"}
+        ],
+        "responses": {
+          "200": {
+            "description": "A stream of titles.",
+            "content": {
+              "text/event-stream": {
+                "schema": {"type": "string"}
+              }
+            }
+          },
+          "400": {"$ref": "#/components/responses/BadRequest"},
+          "500": {"$ref": "#/components/responses/BackendError"}
+        }
+      }
+    },
+    "/api/openapi.json": {
+      "get": {
+        "operationId": "getSpec",
+        "summary": "Get this specification.",
+        "responses": {
+          "200": {
+            "description": "The OpenAPI specification of the API.",
+            "content": {
+              "application/json": {
+                "schema": {"type": "object"}
+              }
+            }
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "parameters": {
+      "slow": {
+        "name": "slow",
+        "in": "query",
+        "description": "Call the backends sequentially and ask them to respond slowly.",
+        "schema": {"type": "boolean"}
+      },
+      "lang": {
+        "name": "lang",
+        "in": "query",
+        "description": "Locale of the title. Takes precedence over the Accept-Language header.",
+        "schema": {"type": "string"}
+      },
+      "latency": {
+        "name": "latency",
+        "in": "query",
+        "description": "Latency profile applied by the backends, e.g. fixed:100ms, uniform:10ms,300ms, normal:100ms,20ms, pareto:20ms,1.5 or bimodal:20ms,400ms,0.1. Overrides the default profile of slow requests.",
+        "schema": {"type": "string"}
+      },
+      "tag": {
+        "name": "tag",
+        "in": "query",
+        "description": "Only use words carrying at least one of the given tags.",
+        "schema": {
+          "type": "array",
+          "items": {"type": "string"}
+        }
+      }
+    },
+    "responses": {
+      "BadRequest": {
+        "description": "The request is invalid.",
+        "content": {
+          "text/plain": {
+            "schema": {"type": "string"}
+          }
+        }
+      },
+      "BackendError": {
+        "description": "A backend service failed.",
+        "content": {
+          "text/plain": {
+            "schema": {"type": "string"}
+          }
+        }
+      }
+    },
+    "schemas": {
+      "Title": {
+        "type": "object",
+        "required": ["seniority", "field", "role", "locale", "title"],
+        "properties": {
+          "seniority": {"type": "string"},
+          "field": {"type": "string"},
+          "role": {"type": "string"},
+          "locale": {"type": "string"},
+          "title": {"type": "string"}
+        }
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,374 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// document holds the parts of an OpenAPI document which are needed for
+// validating requests and responses.
+type document struct {
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components struct {
+		Parameters map[string]*parameter `json:"parameters"`
+		Responses  map[string]*response  `json:"responses"`
+		Schemas    map[string]*schema    `json:"schemas"`
+	} `json:"components"`
+}
+
+type operation struct {
+	Parameters []*parameter         `json:"parameters"`
+	Responses  map[string]*response `json:"responses"`
+}
+
+type parameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *schema `json:"schema"`
+}
+
+type response struct {
+	Ref     string `json:"$ref"`
+	Content map[string]struct {
+		Schema *schema `json:"schema"`
+	} `json:"content"`
+}
+
+type schema struct {
+	Ref        string             `json:"$ref"`
+	Type       string             `json:"type"`
+	Minimum    *float64           `json:"minimum"`
+	Maximum    *float64           `json:"maximum"`
+	Items      *schema            `json:"items"`
+	Properties map[string]*schema `json:"properties"`
+	Required   []string           `json:"required"`
+}
+
+// ValidationError describes why a request or response doesn't match the
+// specification.
+type ValidationError struct {
+	msg string
+}
+
+func (e *ValidationError) Error() string {
+	return e.msg
+}
+
+func validationErrorf(format string, a ...interface{}) error {
+	return &ValidationError{msg: fmt.Sprintf(format, a...)}
+}
+
+var doc = mustParse(Spec)
+
+// mustParse parses an OpenAPI document and resolves all local references.
+func mustParse(spec string) *document {
+	var d document
+	if err := json.Unmarshal([]byte(spec), &d); err != nil {
+		panic(fmt.Sprintf("parsing OpenAPI spec: %v", err))
+	}
+
+	for _, methods := range d.Paths {
+		for _, op := range methods {
+			for i, p := range op.Parameters {
+				if p.Ref != "" {
+					op.Parameters[i] = d.Components.Parameters[refName(p.Ref, "parameters")]
+				}
+				d.resolveSchema(&op.Parameters[i].Schema)
+			}
+			for code, r := range op.Responses {
+				if r.Ref != "" {
+					op.Responses[code] = d.Components.Responses[refName(r.Ref, "responses")]
+				}
+				for mt, c := range op.Responses[code].Content {
+					d.resolveSchema(&c.Schema)
+					op.Responses[code].Content[mt] = c
+				}
+			}
+		}
+	}
+
+	return &d
+}
+
+// resolveSchema replaces a schema reference with the referenced schema and
+// resolves the references of nested schemas.
+func (d *document) resolveSchema(s **schema) {
+	if *s == nil {
+		return
+	}
+	if (*s).Ref != "" {
+		resolved, ok := d.Components.Schemas[refName((*s).Ref, "schemas")]
+		if !ok {
+			panic(fmt.Sprintf("unknown schema reference %q", (*s).Ref))
+		}
+		*s = resolved
+	}
+
+	d.resolveSchema(&(*s).Items)
+	for name := range (*s).Properties {
+		p := (*s).Properties[name]
+		d.resolveSchema(&p)
+		(*s).Properties[name] = p
+	}
+}
+
+func refName(ref, kind string) string {
+	prefix := "#/components/" + kind + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		panic(fmt.Sprintf("unsupported reference %q", ref))
+	}
+
+	return strings.TrimPrefix(ref, prefix)
+}
+
+// lookup returns the operation handling r or nil if the specification doesn't
+// describe it.
+func lookup(r *http.Request) *operation {
+	methods, ok := doc.Paths[r.URL.Path]
+	if !ok {
+		return nil
+	}
+
+	return methods[strings.ToLower(r.Method)]
+}
+
+// ValidateRequest checks the query parameters of r against the specification.
+// Requests for operations which aren't part of the specification are valid.
+func ValidateRequest(r *http.Request) error {
+	op := lookup(r)
+	if op == nil {
+		return nil
+	}
+
+	query := r.URL.Query()
+	for _, p := range op.Parameters {
+		if p.In != "query" {
+			continue
+		}
+
+		var values []string
+		for _, v := range query[p.Name] {
+			if v != "" {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			if p.Required {
+				return validationErrorf("missing required parameter %q", p.Name)
+			}
+			continue
+		}
+
+		if err := validateParameter(p.Name, values, p.Schema); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateParameter(name string, values []string, s *schema) error {
+	if s.Type == "array" {
+		for _, v := range values {
+			if err := validateParameter(name, []string{v}, s.Items); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(values) > 1 {
+		return validationErrorf("parameter %q must not be repeated", name)
+	}
+	v := values[0]
+
+	switch s.Type {
+	case "integer":
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return validationErrorf("parameter %q must be an integer", name)
+		}
+		return checkRange("parameter "+strconv.Quote(name), float64(n), s)
+	case "number":
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return validationErrorf("parameter %q must be a number", name)
+		}
+		return checkRange("parameter "+strconv.Quote(name), n, s)
+	case "boolean":
+		if _, err := strconv.ParseBool(v); err != nil {
+			return validationErrorf("parameter %q must be a boolean", name)
+		}
+	}
+
+	return nil
+}
+
+func checkRange(what string, n float64, s *schema) error {
+	if s.Minimum != nil && n < *s.Minimum {
+		return validationErrorf("%s must be at least %v", what, *s.Minimum)
+	}
+	if s.Maximum != nil && n > *s.Maximum {
+		return validationErrorf("%s must be at most %v", what, *s.Maximum)
+	}
+
+	return nil
+}
+
+// responseSchema returns the schema of the JSON body of a response to r with
+// the given status code or nil if there is none.
+func responseSchema(r *http.Request, status int) *schema {
+	op := lookup(r)
+	if op == nil {
+		return nil
+	}
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		return nil
+	}
+	content, ok := resp.Content["application/json"]
+	if !ok {
+		return nil
+	}
+
+	return content.Schema
+}
+
+// ValidateResponse checks a JSON response body to r against the
+// specification. Responses without a JSON schema are valid.
+func ValidateResponse(r *http.Request, status int, body []byte) error {
+	s := responseSchema(r, status)
+	if s == nil {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return validationErrorf("response is not valid JSON: %v", err)
+	}
+
+	return validateValue("response", v, s)
+}
+
+func validateValue(path string, v interface{}, s *schema) error {
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return validationErrorf("%s must be an object", path)
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				return validationErrorf("%s is missing property %q", path, name)
+			}
+		}
+		for name, prop := range s.Properties {
+			if pv, ok := obj[name]; ok {
+				if err := validateValue(path+"."+name, pv, prop); err != nil {
+					return err
+				}
+			}
+		}
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			return validationErrorf("%s must be an array", path)
+		}
+		for i, item := range arr {
+			if err := validateValue(fmt.Sprintf("%s[%d]", path, i), item, s.Items); err != nil {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			return validationErrorf("%s must be a string", path)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return validationErrorf("%s must be a boolean", path)
+		}
+	case "integer", "number":
+		n, ok := v.(json.Number)
+		if !ok {
+			return validationErrorf("%s must be a number", path)
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return validationErrorf("%s must be a number", path)
+		}
+		if s.Type == "integer" {
+			if _, err := n.Int64(); err != nil {
+				return validationErrorf("%s must be an integer", path)
+			}
+		}
+		return checkRange(path, f, s)
+	}
+
+	return nil
+}
+
+// Middleware validates requests and JSON responses of the operations in the
+// specification. Invalid requests are rejected with status 400. Responses
+// which don't match the specification are replaced with a status 500 error,
+// so that contract violations are caught rather than sent to clients.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := ValidateRequest(r); err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+
+		// Only buffer responses which have a JSON schema. Everything else,
+		// e.g. event streams, is passed through untouched.
+		if responseSchema(r, 200) == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &recorder{header: w.Header(), status: 200}
+		next.ServeHTTP(rec, r)
+
+		if err := ValidateResponse(r, rec.status, rec.body.Bytes()); err != nil {
+			log.Printf("Invalid response for %s: %v", r.URL.Path, err)
+			http.Error(w, "Response does not match API specification", 500)
+			return
+		}
+
+		w.WriteHeader(rec.status)
+		w.Write(rec.body.Bytes())
+	})
+}
+
+// ServeSpec serves the specification.
+func ServeSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(Spec))
+}
+
+// recorder buffers a response so that it can be validated before it is sent.
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/johananl/otel-demo/pkg/spanlink"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/trace"
+)
+
+// batcher coalesces concurrent requests for single titles into batch calls
+// to the backends.
+//
+// A batch serves several requests, so its spans can't be children of all of
+// them. Every batch starts a new trace instead whose spans, down to the
+// backend calls, are linked to the spans of the requests in the batch.
+type batcher struct {
+	tr     trace.Tracer
+	gen    *titleGenerator
+	window time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*batch
+}
+
+// batch is a set of requests for titles with the same parameters.
+type batch struct {
+	req     titleRequest
+	links   []core.SpanContext
+	waiters []chan<- batchResult
+}
+
+// batchResult is the title generated for a request in a batch.
+type batchResult struct {
+	res Response
+	err error
+	// batch is the span context of the batch.
+	batch core.SpanContext
+	size  int
+}
+
+func newBatcher(tr trace.Tracer, gen *titleGenerator, window time.Duration) *batcher {
+	return &batcher{tr: tr, gen: gen, window: window, pending: map[string]*batch{}}
+}
+
+// batchKey identifies the requests which can be served by the same batch.
+func batchKey(req titleRequest) string {
+	return strings.Join([]string{req.Locale, strings.Join(req.Tags, ","), req.Latency}, "\xff")
+}
+
+// Generate returns a title generated by a batch call. Requests wait for up
+// to the batch window for other requests to join their batch. The span in
+// ctx is linked from the batch and gets an event pointing at the batch trace.
+func (b *batcher) Generate(ctx context.Context, req titleRequest) (Response, error) {
+	span := trace.SpanFromContext(ctx)
+	ch := make(chan batchResult, 1)
+	k := batchKey(req)
+
+	b.mu.Lock()
+	bt, ok := b.pending[k]
+	if !ok {
+		bt = &batch{req: req}
+		b.pending[k] = bt
+		time.AfterFunc(b.window, func() { b.flush(k, bt) })
+	}
+	bt.links = append(bt.links, span.SpanContext())
+	bt.waiters = append(bt.waiters, ch)
+	full := len(bt.waiters) == maxBatchSize
+	if full {
+		delete(b.pending, k)
+	}
+	b.mu.Unlock()
+
+	if full {
+		go b.run(bt)
+	}
+
+	select {
+	case r := <-ch:
+		span.AddEvent(ctx, "Generated in batch",
+			key.New("batch.trace_id").String(r.batch.TraceIDString()),
+			key.New("batch.span_id").String(r.batch.SpanIDString()),
+			key.New("batch.size").Int(r.size),
+		)
+		return r.res, r.err
+	case <-ctx.Done():
+		return Response{}, ctx.Err()
+	}
+}
+
+// flush runs bt once its window has passed unless it was already run because
+// it was full.
+func (b *batcher) flush(k string, bt *batch) {
+	b.mu.Lock()
+	if b.pending[k] != bt {
+		b.mu.Unlock()
+		return
+	}
+	delete(b.pending, k)
+	b.mu.Unlock()
+
+	b.run(bt)
+}
+
+// run generates the titles of a batch in a new trace linked to the requests.
+func (b *batcher) run(bt *batch) {
+	opts := []trace.StartOption{trace.WithSpanKind(trace.SpanKindInternal)}
+	for _, l := range bt.links {
+		opts = append(opts, trace.LinkedTo(l))
+	}
+	ctx, span := b.tr.Start(context.Background(), "generate-batch", opts...)
+	defer span.End()
+
+	n := len(bt.waiters)
+	span.SetAttributes(key.New("batch.size").Int(n))
+
+	// The backend calls are linked to the requests as well.
+	ctx = spanlink.NewContext(ctx, bt.links...)
+	res, err := b.gen.GenerateBatch(ctx, bt.req, n)
+	if err != nil {
+		recordError(ctx, span, err)
+	}
+
+	for i, ch := range bt.waiters {
+		r := batchResult{err: err, batch: span.SpanContext(), size: n}
+		if err == nil {
+			r.res = res[i]
+		}
+		ch <- r
+	}
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/trace"
+)
+
+// titleCache keeps titles generated ahead of time, so that requests without
+// parameters other than the locale can be served without calling the
+// backends.
+//
+// The cache of a locale is refilled in the background once it runs low. The
+// refresh isn't part of the request which triggered it, so it starts a new
+// trace linked to the triggering request.
+type titleCache struct {
+	tr   trace.Tracer
+	gen  *titleGenerator
+	size int
+
+	mu         sync.Mutex
+	titles     map[string][]Response
+	refreshing map[string]bool
+}
+
+func newTitleCache(tr trace.Tracer, gen *titleGenerator, size int) *titleCache {
+	if size > maxBatchSize {
+		size = maxBatchSize
+	}
+
+	return &titleCache{
+		tr:         tr,
+		gen:        gen,
+		size:       size,
+		titles:     map[string][]Response{},
+		refreshing: map[string]bool{},
+	}
+}
+
+// Get takes a title of the given locale from the cache. It reports false if
+// there is none. Get starts a refresh if the cache of the locale is at most
+// half full and no refresh is running.
+func (c *titleCache) Get(ctx context.Context, locale string) (Response, bool) {
+	span := trace.SpanFromContext(ctx)
+
+	c.mu.Lock()
+	titles := c.titles[locale]
+	var res Response
+	ok := len(titles) > 0
+	if ok {
+		res = titles[len(titles)-1]
+		titles = titles[:len(titles)-1]
+		c.titles[locale] = titles
+	}
+	refresh := len(titles) <= c.size/2 && !c.refreshing[locale]
+	if refresh {
+		c.refreshing[locale] = true
+	}
+	c.mu.Unlock()
+
+	if ok {
+		span.AddEvent(ctx, "Served from cache")
+	}
+	if refresh {
+		rctx, rspan := c.tr.Start(context.Background(), "refresh-title-cache",
+			trace.WithSpanKind(trace.SpanKindInternal),
+			trace.LinkedTo(span.SpanContext()),
+		)
+		span.AddEvent(ctx, "Triggered cache refresh",
+			key.New("refresh.trace_id").String(rspan.SpanContext().TraceIDString()),
+			key.New("refresh.span_id").String(rspan.SpanContext().SpanIDString()),
+		)
+		go c.refresh(rctx, rspan, locale)
+	}
+
+	return res, ok
+}
+
+// refresh fills the cache of a locale using a batch call to the backends and
+// ends span once done.
+func (c *titleCache) refresh(ctx context.Context, span trace.Span, locale string) {
+	defer span.End()
+
+	c.mu.Lock()
+	n := c.size - len(c.titles[locale])
+	c.mu.Unlock()
+
+	span.SetAttributes(
+		key.New("locale").String(locale),
+		key.New("cache.refill").Int(n),
+	)
+
+	res, err := c.gen.GenerateBatch(ctx, titleRequest{Locale: locale}, n)
+	if err != nil {
+		log.Printf("Error refreshing title cache: %v", err)
+		recordError(ctx, span, err)
+	}
+
+	c.mu.Lock()
+	c.titles[locale] = append(c.titles[locale], res...)
+	c.refreshing[locale] = false
+	c.mu.Unlock()
+}
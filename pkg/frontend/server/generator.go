@@ -0,0 +1,244 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/johananl/otel-demo/pkg/frontend/i18n"
+	fieldpb "github.com/johananl/otel-demo/proto/field"
+	rolepb "github.com/johananl/otel-demo/proto/role"
+	senioritypb "github.com/johananl/otel-demo/proto/seniority"
+)
+
+// backendError is returned when one of the backend services fails.
+type backendError struct {
+	service string
+	err     error
+}
+
+func (e *backendError) Error() string {
+	return fmt.Sprintf("getting %s: %v", e.service, e.err)
+}
+
+// titleRequest holds the parameters for generating titles.
+type titleRequest struct {
+	Slow   bool
+	Locale string
+	Tags   []string
+	// Seed makes the word selection reproducible. 0 means random.
+	Seed int64
+	// Latency is a latency profile spec passed to the backends.
+	Latency string
+}
+
+// DefaultBackendTimeout bounds the time spent waiting for the backends, so
+// that a hanging backend fails the request instead of blocking it forever.
+const DefaultBackendTimeout = 10 * time.Second
+
+// titleGenerator assembles titles from the words returned by the backend
+// services.
+type titleGenerator struct {
+	seniorityClient senioritypb.SeniorityClient
+	fieldClient     fieldpb.FieldClient
+	roleClient      rolepb.RoleClient
+	// timeout bounds the backend calls of a request.
+	timeout time.Duration
+}
+
+// Generate returns a single title. Slow requests call the backends one after
+// the other whereas fast requests call them in parallel.
+func (g *titleGenerator) Generate(ctx context.Context, req titleRequest) (Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+
+	var seniority string
+	var field string
+	var role string
+
+	if req.Slow {
+		// Handle request slowly.
+
+		// Get seniority.
+		sr, err := g.seniorityClient.GetSeniority(ctx, &senioritypb.SeniorityRequest{
+			Slow: true, Locale: req.Locale, Tags: req.Tags, Seed: req.Seed, Latency: req.Latency,
+		})
+		if err != nil {
+			return Response{}, &backendError{"seniority", err}
+		}
+		seniority = sr.Seniority
+
+		// Get field.
+		fr, err := g.fieldClient.GetField(ctx, &fieldpb.FieldRequest{
+			Slow: true, Locale: req.Locale, Tags: req.Tags, Seed: req.Seed, Latency: req.Latency,
+		})
+		if err != nil {
+			return Response{}, &backendError{"field", err}
+		}
+		field = fr.Field
+
+		// Get role.
+		rr, err := g.roleClient.GetRole(ctx, &rolepb.RoleRequest{
+			Slow: true, Locale: req.Locale, Tags: req.Tags, Seed: req.Seed, Latency: req.Latency,
+		})
+		if err != nil {
+			return Response{}, &backendError{"role", err}
+		}
+		role = rr.Role
+	} else {
+		// Handle request quickly.
+
+		// The channels are buffered so that the remaining goroutines don't
+		// block forever once the first error was returned.
+		errChan := make(chan error, 3)
+
+		// Get seniority.
+		sChan := make(chan *senioritypb.SeniorityReply, 1)
+		go func(reply chan<- *senioritypb.SeniorityReply, errChan chan<- error) {
+			r, err := g.seniorityClient.GetSeniority(ctx, &senioritypb.SeniorityRequest{
+				Locale: req.Locale, Tags: req.Tags, Seed: req.Seed, Latency: req.Latency,
+			})
+			if err != nil {
+				errChan <- &backendError{"seniority", err}
+				return
+			}
+
+			reply <- r
+		}(sChan, errChan)
+
+		// Get field.
+		fChan := make(chan *fieldpb.FieldReply, 1)
+		go func(reply chan<- *fieldpb.FieldReply, errChan chan<- error) {
+			r, err := g.fieldClient.GetField(ctx, &fieldpb.FieldRequest{
+				Locale: req.Locale, Tags: req.Tags, Seed: req.Seed, Latency: req.Latency,
+			})
+			if err != nil {
+				errChan <- &backendError{"field", err}
+				return
+			}
+
+			reply <- r
+		}(fChan, errChan)
+
+		// Get role.
+		rChan := make(chan *rolepb.RoleReply, 1)
+		go func(reply chan<- *rolepb.RoleReply, errChan chan<- error) {
+			r, err := g.roleClient.GetRole(ctx, &rolepb.RoleRequest{
+				Locale: req.Locale, Tags: req.Tags, Seed: req.Seed, Latency: req.Latency,
+			})
+			if err != nil {
+				errChan <- &backendError{"role", err}
+				return
+			}
+
+			reply <- r
+		}(rChan, errChan)
+
+		// Wait for all gRPC calls to return.
+		for seniority == "" || field == "" || role == "" {
+			select {
+			case sr := <-sChan:
+				seniority = sr.Seniority
+			case fr := <-fChan:
+				field = fr.Field
+			case rr := <-rChan:
+				role = rr.Role
+			case err := <-errChan:
+				return Response{}, err
+			}
+		}
+	}
+
+	return Response{
+		Seniority: seniority,
+		Field:     field,
+		Role:      role,
+		Locale:    req.Locale,
+		Title:     i18n.Title(req.Locale, seniority, field, role),
+	}, nil
+}
+
+// GenerateBatch returns count titles using a single batch RPC per backend.
+func (g *titleGenerator) GenerateBatch(ctx context.Context, req titleRequest, count int) ([]Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+
+	var seniorities []string
+	var fields []string
+	var roles []string
+
+	getSeniorities := func() error {
+		r, err := g.seniorityClient.GetSeniorities(ctx, &senioritypb.SenioritiesRequest{
+			Slow: req.Slow, Locale: req.Locale, Tags: req.Tags, Count: int32(count), Latency: req.Latency,
+		})
+		if err != nil {
+			return &backendError{"seniority", err}
+		}
+		seniorities = r.Seniorities
+		return nil
+	}
+	getFields := func() error {
+		r, err := g.fieldClient.GetFields(ctx, &fieldpb.FieldsRequest{
+			Slow: req.Slow, Locale: req.Locale, Tags: req.Tags, Count: int32(count), Latency: req.Latency,
+		})
+		if err != nil {
+			return &backendError{"field", err}
+		}
+		fields = r.Fields
+		return nil
+	}
+	getRoles := func() error {
+		r, err := g.roleClient.GetRoles(ctx, &rolepb.RolesRequest{
+			Slow: req.Slow, Locale: req.Locale, Tags: req.Tags, Count: int32(count), Latency: req.Latency,
+		})
+		if err != nil {
+			return &backendError{"role", err}
+		}
+		roles = r.Roles
+		return nil
+	}
+
+	calls := []func() error{getSeniorities, getFields, getRoles}
+	if req.Slow {
+		// Handle request slowly.
+		for _, call := range calls {
+			if err := call(); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		// Handle request quickly. The channel is buffered so that no
+		// goroutine is left blocked if we return early on an error.
+		errChan := make(chan error, len(calls))
+		for _, call := range calls {
+			go func(call func() error) {
+				errChan <- call()
+			}(call)
+		}
+
+		// Wait for all gRPC calls to return.
+		for range calls {
+			if err := <-errChan; err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(seniorities) != count || len(fields) != count || len(roles) != count {
+		return nil, fmt.Errorf("unexpected batch sizes: %d seniorities, %d fields, %d roles",
+			len(seniorities), len(fields), len(roles))
+	}
+
+	res := make([]Response, count)
+	for i := range res {
+		res[i] = Response{
+			Seniority: seniorities[i],
+			Field:     fields[i],
+			Role:      roles[i],
+			Locale:    req.Locale,
+			Title:     i18n.Title(req.Locale, seniorities[i], fields[i], roles[i]),
+		}
+	}
+
+	return res, nil
+}
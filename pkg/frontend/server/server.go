@@ -0,0 +1,492 @@
+// Package server implements the frontend service, which assembles titles
+// from the words returned by the backend services and serves them over HTTP,
+// WebSocket and gRPC.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/johananl/otel-demo/pkg/depgraph"
+	"github.com/johananl/otel-demo/pkg/frontend/i18n"
+	"github.com/johananl/otel-demo/pkg/frontend/openapi"
+	"github.com/johananl/otel-demo/pkg/frontend/tracing"
+	"github.com/johananl/otel-demo/pkg/latency"
+	"github.com/johananl/otel-demo/pkg/metrics"
+	"github.com/johananl/otel-demo/pkg/traceview"
+	fieldpb "github.com/johananl/otel-demo/proto/field"
+	rolepb "github.com/johananl/otel-demo/proto/role"
+	senioritypb "github.com/johananl/otel-demo/proto/seniority"
+	titlepb "github.com/johananl/otel-demo/proto/title"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/trace"
+	"google.golang.org/grpc/status"
+)
+
+// maxBatchSize is the maximum number of titles which can be requested at once.
+const maxBatchSize = 100
+
+// minStreamInterval is the shortest delay between two streamed titles the
+// backends accept.
+const minStreamInterval = 10
+
+// Response is a generated title.
+type Response struct {
+	Seniority string `json:"seniority"`
+	Field     string `json:"field"`
+	Role      string `json:"role"`
+	Locale    string `json:"locale"`
+	Title     string `json:"title"`
+}
+
+// httpDuration records the time spent serving HTTP API requests by path.
+var httpDuration = metrics.NewHistogramVec(
+	"http_server_request_duration_seconds",
+	"Time spent serving HTTP API requests.",
+	metrics.LatencyBuckets,
+	"path",
+)
+
+func init() {
+	metrics.Default.Register(httpDuration)
+}
+
+// observeDuration records the duration of a request to path which started
+// at start. ctx must carry the server span, which becomes the exemplar.
+func observeDuration(ctx context.Context, path string, start time.Time) {
+	httpDuration.Observe(ctx, time.Since(start).Seconds(), path)
+}
+
+// requestTags returns the word tags requested by the client. Tags may be
+// passed as repeated "tag" query parameters, as a comma-separated list or both.
+func requestTags(r *http.Request) []string {
+	var tags []string
+	for _, v := range r.URL.Query()["tag"] {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				tags = append(tags, t)
+			}
+		}
+	}
+
+	return tags
+}
+
+// recordError marks span as failed. Backend errors carry the status code
+// returned by the backend.
+func recordError(ctx context.Context, span trace.Span, err error) {
+	code := status.Code(err)
+	attrs := []core.KeyValue{key.New("error").String(err.Error())}
+	if be, ok := err.(*backendError); ok {
+		code = status.Code(be.err)
+		attrs = append(attrs, key.New("service").String(be.service))
+	}
+
+	span.SetStatus(code)
+	span.AddEvent(ctx, "Error generating title", attrs...)
+}
+
+// errorMessage returns the message reported to HTTP clients for err.
+func errorMessage(err error) string {
+	if be, ok := err.(*backendError); ok {
+		return fmt.Sprintf("Error from %s service", be.service)
+	}
+
+	return "Error from backend service"
+}
+
+// Server serves titles generated from the words of the backend services.
+type Server struct {
+	tr  trace.Tracer
+	gen *titleGenerator
+	// batcher, cache, traces and graph are nil unless enabled.
+	batcher *batcher
+	cache   *titleCache
+	traces  traceview.Store
+	graph   *depgraph.Graph
+}
+
+// New returns a frontend server which records spans using tr and gets words
+// from the given backend clients.
+func New(tr trace.Tracer, seniorityClient senioritypb.SeniorityClient, fieldClient fieldpb.FieldClient, roleClient rolepb.RoleClient) *Server {
+	return &Server{
+		tr: tr,
+		gen: &titleGenerator{
+			seniorityClient: seniorityClient,
+			fieldClient:     fieldClient,
+			roleClient:      roleClient,
+			timeout:         DefaultBackendTimeout,
+		},
+	}
+}
+
+// SetBackendTimeout changes the time a request may spend waiting for the
+// backends. It must be called before serving requests.
+func (s *Server) SetBackendTimeout(d time.Duration) {
+	s.gen.timeout = d
+}
+
+// SetBatchWindow makes fast requests for single titles with the same
+// parameters which arrive within d of each other share batch calls to the
+// backends. Zero disables batching. It must be called before serving
+// requests.
+func (s *Server) SetBatchWindow(d time.Duration) {
+	s.batcher = nil
+	if d > 0 {
+		s.batcher = newBatcher(s.tr, s.gen, d)
+	}
+}
+
+// SetPrefetch makes the server keep up to n titles per locale generated
+// ahead of time for fast requests without tags or latency profile. Zero
+// disables prefetching. It must be called before serving requests.
+func (s *Server) SetPrefetch(n int) {
+	s.cache = nil
+	if n > 0 {
+		s.cache = newTitleCache(s.tr, s.gen, n)
+	}
+}
+
+// SetTraceStore makes the server serve the traces in st on /debug/traces.
+// It must be called before Handler.
+func (s *Server) SetTraceStore(st traceview.Store) {
+	s.traces = st
+}
+
+// SetServiceGraph makes the server serve the service dependency graph g on
+// /debug/graph. It must be called before Handler.
+func (s *Server) SetServiceGraph(g *depgraph.Graph) {
+	s.graph = g
+}
+
+// Handler returns the HTTP handler of the frontend. It serves the UI from
+// uiDir, the HTTP API, the WebSocket endpoint, metrics and, if enabled, the
+// trace viewer and the service dependency graph.
+func (s *Server) Handler(uiDir string) http.Handler {
+	mux := http.NewServeMux()
+
+	// Handle static content (for UI).
+	fs := http.FileServer(http.Dir(uiDir))
+	mux.Handle("/", fs)
+
+	// Handle API. Requests and responses are validated against the OpenAPI
+	// specification which is served alongside the API.
+	mux.Handle("/api", openapi.Middleware(http.HandlerFunc(s.serveTitle)))
+	mux.Handle("/api/titles", openapi.Middleware(http.HandlerFunc(s.serveTitles)))
+	mux.Handle("/api/stream", openapi.Middleware(http.HandlerFunc(s.serveStream)))
+	mux.HandleFunc("/api/openapi.json", openapi.ServeSpec)
+	mux.Handle("/ws", websocketHandler(s.tr, s.gen))
+
+	// Handle runtime and process metrics.
+	mux.Handle("/metrics", metrics.Handler())
+
+	// Handle the trace viewer.
+	if s.traces != nil {
+		h := traceview.Handler(s.traces)
+		mux.Handle(traceview.Path, h)
+		mux.Handle(traceview.Path+"/", h)
+	}
+	if s.graph != nil {
+		mux.Handle(depgraph.Path, depgraph.Handler(s.graph))
+	}
+
+	return mux
+}
+
+// TitleServer returns the gRPC TitleService of the frontend.
+func (s *Server) TitleServer() titlepb.TitleServiceServer {
+	return &titleServer{gen: s.gen}
+}
+
+// serveTitle serves a single title.
+func (s *Server) serveTitle(w http.ResponseWriter, r *http.Request) {
+	ctx, parent := tracing.ExtractHTTP(r)
+	ctx, span := s.tr.Start(ctx, "serve-http-request", parent, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+	defer observeDuration(ctx, "/api", time.Now())
+
+	slow, err := parseSlow(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	req := titleRequest{
+		Slow:    slow,
+		Locale:  i18n.Locale(r),
+		Tags:    requestTags(r),
+		Latency: r.URL.Query().Get("latency"),
+	}
+	if req.Latency != "" {
+		if _, err := latency.Parse(req.Latency); err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+	}
+	span.SetAttributes(
+		key.New("slow").Bool(req.Slow),
+		key.New("locale").String(req.Locale),
+		key.New("tags").String(strings.Join(req.Tags, ",")),
+		key.New("latency").String(req.Latency),
+	)
+
+	res, err := s.generate(ctx, req)
+	if err != nil {
+		log.Printf("gRPC error: %v", err)
+		recordError(ctx, span, err)
+		http.Error(w, errorMessage(err), 500)
+		return
+	}
+
+	j, err := json.Marshal(res)
+	if err != nil {
+		log.Println("Error serializing to JSON")
+		http.Error(w, "Error serializing to JSON", 500)
+		return
+	}
+
+	span.AddEvent(ctx, "Generating response", key.New("response").String(string(j)))
+
+	// Write HTTP response.
+	w.Write(j)
+}
+
+// generate returns a single title, from the cache or a batch if enabled and
+// applicable to req.
+func (s *Server) generate(ctx context.Context, req titleRequest) (Response, error) {
+	if s.cache != nil && !req.Slow && len(req.Tags) == 0 && req.Latency == "" {
+		if res, ok := s.cache.Get(ctx, req.Locale); ok {
+			return res, nil
+		}
+	}
+	if s.batcher != nil && !req.Slow {
+		return s.batcher.Generate(ctx, req)
+	}
+
+	return s.gen.Generate(ctx, req)
+}
+
+// serveTitles serves several titles using one batch call per backend.
+func (s *Server) serveTitles(w http.ResponseWriter, r *http.Request) {
+	ctx, parent := tracing.ExtractHTTP(r)
+	ctx, span := s.tr.Start(ctx, "serve-http-request", parent, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+	defer observeDuration(ctx, "/api/titles", time.Now())
+
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+	if err != nil || count < 1 || count > maxBatchSize {
+		http.Error(w, fmt.Sprintf("count must be between 1 and %d", maxBatchSize), 400)
+		return
+	}
+
+	slow, err := parseSlow(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	req := titleRequest{
+		Slow:    slow,
+		Locale:  i18n.Locale(r),
+		Tags:    requestTags(r),
+		Latency: r.URL.Query().Get("latency"),
+	}
+	if req.Latency != "" {
+		if _, err := latency.Parse(req.Latency); err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+	}
+	span.SetAttributes(
+		key.New("batch.size").Int(count),
+		key.New("slow").Bool(req.Slow),
+		key.New("locale").String(req.Locale),
+		key.New("tags").String(strings.Join(req.Tags, ",")),
+		key.New("latency").String(req.Latency),
+	)
+
+	res, err := s.gen.GenerateBatch(ctx, req, count)
+	if err != nil {
+		log.Printf("gRPC error: %v", err)
+		recordError(ctx, span, err)
+		http.Error(w, errorMessage(err), 500)
+		return
+	}
+
+	j, err := json.Marshal(res)
+	if err != nil {
+		log.Println("Error serializing to JSON")
+		http.Error(w, "Error serializing to JSON", 500)
+		return
+	}
+
+	span.AddEvent(ctx, "Generating response", key.New("titles").Int(len(res)))
+
+	// Write HTTP response.
+	w.Write(j)
+}
+
+// parseSlow parses the boolean query parameter slow, which defaults to
+// false.
+func parseSlow(r *http.Request) (bool, error) {
+	v := r.URL.Query().Get("slow")
+	if v == "" {
+		return false, nil
+	}
+	slow, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, errors.New("slow must be a boolean")
+	}
+
+	return slow, nil
+}
+
+// serveStream streams titles. Titles are pushed to the client using
+// Server-Sent Events until either side closes the stream.
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", 500)
+		return
+	}
+
+	var interval int
+	var count int
+	var err error
+	if v := r.URL.Query().Get("interval"); v != "" {
+		// Zero means the default interval of the backends.
+		if interval, err = strconv.Atoi(v); err != nil || interval < 0 || (interval > 0 && interval < minStreamInterval) {
+			http.Error(w, fmt.Sprintf("interval must be 0 or at least %d milliseconds", minStreamInterval), 400)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("count"); v != "" {
+		if count, err = strconv.Atoi(v); err != nil || count < 0 {
+			http.Error(w, "count must be a non-negative number", 400)
+			return
+		}
+	}
+
+	ctx, parent := tracing.ExtractHTTP(r)
+	ctx, span := s.tr.Start(ctx, "serve-sse-stream", parent, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	// Make sure the backend streams are closed when the client goes away.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	locale := i18n.Locale(r)
+	tags := requestTags(r)
+	span.SetAttributes(
+		key.New("stream.interval_ms").Int(interval),
+		key.New("locale").String(locale),
+		key.New("tags").String(strings.Join(tags, ",")),
+	)
+
+	sStream, err := s.gen.seniorityClient.StreamSeniorities(ctx, &senioritypb.SeniorityStreamRequest{
+		Locale: locale, Tags: tags, IntervalMs: int32(interval), Count: int32(count),
+	})
+	if err != nil {
+		log.Printf("streaming seniorities: %v", err)
+		span.SetStatus(status.Code(err))
+		http.Error(w, "Error from seniority service", 500)
+		return
+	}
+	fStream, err := s.gen.fieldClient.StreamFields(ctx, &fieldpb.FieldStreamRequest{
+		Locale: locale, Tags: tags, IntervalMs: int32(interval), Count: int32(count),
+	})
+	if err != nil {
+		log.Printf("streaming fields: %v", err)
+		span.SetStatus(status.Code(err))
+		http.Error(w, "Error from field service", 500)
+		return
+	}
+	rStream, err := s.gen.roleClient.StreamRoles(ctx, &rolepb.RoleStreamRequest{
+		Locale: locale, Tags: tags, IntervalMs: int32(interval), Count: int32(count),
+	})
+	if err != nil {
+		log.Printf("streaming roles: %v", err)
+		span.SetStatus(status.Code(err))
+		http.Error(w, "Error from role service", 500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(200)
+	flusher.Flush()
+
+	titles := 0
+	defer func() {
+		span.SetAttributes(key.New("stream.titles").Int(titles))
+	}()
+
+	// nextTitle combines the next word of every backend stream into a title.
+	nextTitle := func() (Response, error) {
+		sr, err := sStream.Recv()
+		if err != nil {
+			return Response{}, err
+		}
+		fr, err := fStream.Recv()
+		if err != nil {
+			return Response{}, err
+		}
+		rr, err := rStream.Recv()
+		if err != nil {
+			return Response{}, err
+		}
+
+		return Response{
+			Seniority: sr.Seniority,
+			Field:     fr.Field,
+			Role:      rr.Role,
+			Locale:    locale,
+			Title:     i18n.Title(locale, sr.Seniority, fr.Field, rr.Role),
+		}, nil
+	}
+
+	for {
+		res, err := nextTitle()
+		switch {
+		case err == io.EOF:
+			// The backends closed their streams after the requested count.
+			fmt.Fprint(w, "event: end\ndata: {}\n\n")
+			flusher.Flush()
+			return
+		case err != nil && r.Context().Err() != nil:
+			// The client went away.
+			span.AddEvent(ctx, "Client disconnected")
+			return
+		case err != nil:
+			log.Printf("gRPC stream error: %v", err)
+			span.AddEvent(ctx, "Stream error", key.New("error").String(err.Error()))
+			span.SetStatus(status.Code(err))
+			fmt.Fprint(w, "event: error\ndata: {\"error\":\"Error from backend service\"}\n\n")
+			flusher.Flush()
+			return
+		}
+
+		j, err := json.Marshal(res)
+		if err != nil {
+			log.Println("Error serializing to JSON")
+			return
+		}
+
+		fmt.Fprintf(w, "data: %s\n\n", j)
+		flusher.Flush()
+
+		titles++
+		span.AddEvent(ctx, "Generated title",
+			key.New("title").String(res.Title),
+			key.New("index").Int(titles),
+		)
+	}
+}
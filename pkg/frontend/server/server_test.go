@@ -0,0 +1,452 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/johananl/otel-demo/pkg/fault"
+	"github.com/johananl/otel-demo/pkg/frontend/i18n"
+	"github.com/johananl/otel-demo/pkg/frontend/server"
+	"github.com/johananl/otel-demo/pkg/latency"
+	"github.com/johananl/otel-demo/pkg/tracetest"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/trace"
+	"google.golang.org/grpc/codes"
+)
+
+var update = flag.Bool("update", false, "update the golden trace topologies in testdata")
+
+// assertGolden compares the topology of tree with testdata/<name>.golden.
+func assertGolden(t *testing.T, name string, tree *tracetest.Tree) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	got := []byte(tree.String())
+	if *update {
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("trace topology differs from %s:\ngot\n%s\nwant\n%s", path, got, want)
+	}
+}
+
+// setFaults makes service fail according to spec.
+func setFaults(t *testing.T, env *tracetest.Env, service, spec string) {
+	t.Helper()
+
+	rules, err := fault.Parse(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := env.Stack().Faults[service].SetRules(rules); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// assertBackendTiming checks that the backends were called one after another
+// in the order seniority, field, role if sequential, and at the same time
+// otherwise.
+func assertBackendTiming(t *testing.T, tree *tracetest.Tree, sequential bool) {
+	t.Helper()
+
+	var spans []*tracetest.Tree
+	for _, service := range []string{"seniority", "field", "role"} {
+		spans = append(spans, tree.Find(service, "handle-grpc-request"))
+	}
+
+	if sequential {
+		for i := 1; i < len(spans); i++ {
+			prev, cur := spans[i-1], spans[i]
+			if cur.Data.StartTime.Before(prev.Data.EndTime) {
+				t.Errorf("%s started at %v, before %s ended at %v", cur.Service, cur.Data.StartTime, prev.Service, prev.Data.EndTime)
+			}
+		}
+		return
+	}
+
+	// All calls overlap if the last one started before the first one ended.
+	lastStart, firstEnd := spans[0].Data.StartTime, spans[0].Data.EndTime
+	for _, s := range spans[1:] {
+		if s.Data.StartTime.After(lastStart) {
+			lastStart = s.Data.StartTime
+		}
+		if s.Data.EndTime.Before(firstEnd) {
+			firstEnd = s.Data.EndTime
+		}
+	}
+	if !lastStart.Before(firstEnd) {
+		t.Errorf("backend calls don't overlap: the last started at %v, after the first ended at %v", lastStart, firstEnd)
+	}
+}
+
+func TestServeTitle(t *testing.T) {
+	env := tracetest.Start(t)
+	defer env.Close()
+
+	// Every backend call takes a while, so that fast requests are seen
+	// calling the backends at the same time.
+	for _, tc := range []struct {
+		name       string
+		target     string
+		sequential bool
+	}{
+		{"fast", "/api?latency=fixed:20ms", false},
+		{"slow", "/api?slow=1&latency=fixed:20ms", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec, traceID := env.Get(t, tc.target)
+			if rec.Code != 200 {
+				t.Fatalf("got status %d: %s", rec.Code, rec.Body)
+			}
+
+			// The response must have exactly the documented fields.
+			var fields map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &fields); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if len(fields) != 5 {
+				t.Errorf("got fields %v, want seniority, field, role, locale and title", fields)
+			}
+
+			var res server.Response
+			dec := json.NewDecoder(bytes.NewReader(rec.Body.Bytes()))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&res); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if res.Seniority == "" || res.Field == "" || res.Role == "" {
+				t.Errorf("response has empty words: %+v", res)
+			}
+			if res.Locale != "en" {
+				t.Errorf("got locale %q, want en", res.Locale)
+			}
+			if want := i18n.Title(res.Locale, res.Seniority, res.Field, res.Role); res.Title != want {
+				t.Errorf("got title %q, want %q", res.Title, want)
+			}
+
+			// The topologies of both paths are the same, so the timing tells
+			// them apart.
+			tree := env.Trace(t, traceID)
+			assertGolden(t, "title_"+tc.name, tree)
+			assertBackendTiming(t, tree, tc.sequential)
+		})
+	}
+}
+
+func TestServeTitleBackendFailure(t *testing.T) {
+	env := tracetest.Start(t)
+	defer env.Close()
+
+	setFaults(t, env, "role", "error:unavailable:1")
+
+	for _, target := range []string{"/api", "/api?slow=1"} {
+		t.Run(target, func(t *testing.T) {
+			rec, traceID := env.Get(t, target)
+			if rec.Code != 500 {
+				t.Fatalf("got status %d, want 500", rec.Code)
+			}
+			if got, want := rec.Body.String(), "Error from role service\n"; got != want {
+				t.Errorf("got body %q, want %q", got, want)
+			}
+
+			tree := env.Trace(t, traceID)
+			tree.Find("frontend", "serve-http-request").Assert(t, tracetest.Want{
+				Kind:       trace.SpanKindServer,
+				Status:     codes.Unavailable,
+				Attributes: map[string]string{"locale": "en"},
+				Events:     []string{"Error generating title"},
+			})
+			tree.Find("role", "handle-grpc-request").Assert(t, tracetest.Want{
+				Kind:       trace.SpanKindServer,
+				Status:     codes.Unavailable,
+				Attributes: map[string]string{"fault.mode": "error", "fault.code": "Unavailable"},
+				Events:     []string{"Injected fault"},
+			})
+		})
+	}
+
+	// Only slow requests call the backends in a deterministic order, so only
+	// their topology is compared.
+	_, traceID := env.Get(t, "/api?slow=1")
+	assertGolden(t, "title_backend_failure", env.Trace(t, traceID))
+}
+
+func TestServeTitleTimeout(t *testing.T) {
+	const timeout = 100 * time.Millisecond
+
+	env := tracetest.Start(t, tracetest.WithBackendTimeout(timeout))
+	defer env.Close()
+
+	setFaults(t, env, "field", "hang:1")
+
+	start := time.Now()
+	rec, traceID := env.Get(t, "/api?slow=1")
+	elapsed := time.Since(start)
+
+	if rec.Code != 500 {
+		t.Fatalf("got status %d, want 500", rec.Code)
+	}
+	if got, want := rec.Body.String(), "Error from field service\n"; got != want {
+		t.Errorf("got body %q, want %q", got, want)
+	}
+	if elapsed < timeout || elapsed > 10*timeout {
+		t.Errorf("request took %v, want about %v", elapsed, timeout)
+	}
+
+	tree := env.Trace(t, traceID)
+	tree.Find("frontend", "serve-http-request").Assert(t, tracetest.Want{
+		Status: codes.DeadlineExceeded,
+		Events: []string{"Error generating title"},
+	})
+	tree.Find("field", "handle-grpc-request").Assert(t, tracetest.Want{
+		Status:     codes.DeadlineExceeded,
+		Attributes: map[string]string{"fault.mode": "hang"},
+	})
+	assertGolden(t, "title_timeout", tree)
+}
+
+func TestServeTitleSlowDelay(t *testing.T) {
+	const (
+		min = 20 * time.Millisecond
+		max = 40 * time.Millisecond
+	)
+
+	env := tracetest.Start(t, tracetest.WithLatency(latency.Uniform{Min: min, Max: max}))
+	defer env.Close()
+
+	t.Run("slow", func(t *testing.T) {
+		start := time.Now()
+		rec, traceID := env.Get(t, "/api?slow=1")
+		elapsed := time.Since(start)
+		if rec.Code != 200 {
+			t.Fatalf("got status %d: %s", rec.Code, rec.Body)
+		}
+
+		// Slow requests call the backends one after the other, so the delays
+		// add up.
+		var total time.Duration
+		tree := env.Trace(t, traceID)
+		for _, service := range []string{"seniority", "field", "role"} {
+			span := tree.Find(service, "handle-grpc-request")
+			span.Assert(t, tracetest.Want{Events: []string{"Injected delay"}})
+
+			ev, _ := span.Event("Injected delay")
+			var delay time.Duration
+			for _, kv := range ev.Attributes {
+				if kv.Key == "latency.delay_ms" {
+					delay = time.Duration(kv.Value.AsFloat64() * float64(time.Millisecond))
+				}
+			}
+			if delay < min || delay > max {
+				t.Errorf("%s: delay is %v, want between %v and %v", service, delay, min, max)
+			}
+			if d := span.Data.EndTime.Sub(span.Data.StartTime); d < delay {
+				t.Errorf("%s: span took %v, less than the delay of %v", service, d, delay)
+			}
+			total += delay
+		}
+		if elapsed < total {
+			t.Errorf("request took %v, less than the total delay of %v", elapsed, total)
+		}
+	})
+
+	t.Run("fast", func(t *testing.T) {
+		rec, traceID := env.Get(t, "/api")
+		if rec.Code != 200 {
+			t.Fatalf("got status %d: %s", rec.Code, rec.Body)
+		}
+
+		tree := env.Trace(t, traceID)
+		for _, service := range []string{"seniority", "field", "role"} {
+			if _, ok := tree.Find(service, "handle-grpc-request").Event("Injected delay"); ok {
+				t.Errorf("%s: fast request was delayed", service)
+			}
+		}
+	})
+}
+
+// eventTrace returns the trace referenced by the attributes <prefix>.trace_id
+// and <prefix>.span_id of an event of span.
+func eventTrace(t *testing.T, span *tracetest.Tree, event, prefix string) core.SpanContext {
+	t.Helper()
+
+	ev, ok := span.Event(event)
+	if !ok {
+		t.Fatalf("%s %s: event %q missing", span.Service, span.Name, event)
+	}
+
+	var sc core.SpanContext
+	var err error
+	for _, kv := range ev.Attributes {
+		switch string(kv.Key) {
+		case prefix + ".trace_id":
+			sc.TraceID, err = core.TraceIDFromHex(kv.Value.AsString())
+		case prefix + ".span_id":
+			sc.SpanID, err = core.SpanIDFromHex(kv.Value.AsString())
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return sc
+}
+
+// assertLinks checks that span is linked to exactly the given spans.
+func assertLinks(t *testing.T, span *tracetest.Tree, want []core.SpanContext) {
+	t.Helper()
+
+	got := map[core.SpanID]bool{}
+	for _, l := range span.Data.Links {
+		got[l.SpanID] = true
+	}
+	if len(got) != len(want) {
+		t.Errorf("%s %s: got %d links, want %d", span.Service, span.Name, len(got), len(want))
+	}
+	for _, sc := range want {
+		if !got[sc.SpanID] {
+			t.Errorf("%s %s: link to span %s missing", span.Service, span.Name, sc.SpanIDString())
+		}
+	}
+}
+
+func TestBatchLinks(t *testing.T) {
+	const n = 3
+
+	env := tracetest.Start(t, tracetest.WithBatchWindow(100*time.Millisecond))
+	defer env.Close()
+
+	var wg sync.WaitGroup
+	statuses := make([]int, n)
+	traceIDs := make([]core.TraceID, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rec, traceID := env.Get(t, "/api")
+			statuses[i], traceIDs[i] = rec.Code, traceID
+		}(i)
+	}
+	wg.Wait()
+
+	var requests []core.SpanContext
+	var batch core.SpanContext
+	for i, traceID := range traceIDs {
+		if statuses[i] != 200 {
+			t.Fatalf("got status %d", statuses[i])
+		}
+
+		// The backends are called in the batch trace only.
+		tree := env.Trace(t, traceID)
+		frontend := tree.Find("frontend", "serve-http-request")
+		frontend.AssertChildren(t)
+		requests = append(requests, frontend.Data.SpanContext)
+
+		b := eventTrace(t, frontend, "Generated in batch", "batch")
+		if i > 0 && b != batch {
+			t.Fatalf("requests were served by different batches")
+		}
+		batch = b
+	}
+
+	tree := env.Trace(t, batch.TraceID)
+	tree.Assert(t, tracetest.Want{
+		Kind:       trace.SpanKindInternal,
+		Attributes: map[string]string{"batch.size": "3"},
+	})
+	assertLinks(t, tree, requests)
+	for _, service := range []string{"seniority", "field", "role"} {
+		assertLinks(t, tree.Find(service, "handle-grpc-request"), requests)
+	}
+	assertGolden(t, "batch", tree)
+}
+
+func TestPrefetchRefreshLink(t *testing.T) {
+	env := tracetest.Start(t, tracetest.WithPrefetch(4))
+	defer env.Close()
+
+	// The cache starts empty, so the first request is a miss which triggers
+	// a refresh.
+	_, traceID := env.Get(t, "/api")
+	first := env.Trace(t, traceID).Find("frontend", "serve-http-request")
+	first.AssertChildren(t,
+		"field handle-grpc-request",
+		"role handle-grpc-request",
+		"seniority handle-grpc-request",
+	)
+
+	refresh := eventTrace(t, first, "Triggered cache refresh", "refresh")
+	if refresh.TraceID == traceID {
+		t.Fatal("refresh is part of the triggering trace")
+	}
+	tree := env.Trace(t, refresh.TraceID)
+	tree.Assert(t, tracetest.Want{
+		Kind:       trace.SpanKindInternal,
+		Attributes: map[string]string{"cache.refill": "4"},
+	})
+	assertLinks(t, tree, []core.SpanContext{first.Data.SpanContext})
+	assertGolden(t, "prefetch_refresh", tree)
+
+	// The next request is served from the cache without calling the
+	// backends.
+	_, traceID = env.Get(t, "/api")
+	second := env.Trace(t, traceID).Find("frontend", "serve-http-request")
+	second.Assert(t, tracetest.Want{Events: []string{"Served from cache"}})
+	second.AssertChildren(t)
+}
+
+func TestServeStreamInterval(t *testing.T) {
+	env := tracetest.Start(t)
+	defer env.Close()
+
+	// Intervals the backends reject are rejected before the stream starts.
+	for _, target := range []string{"/api/stream?interval=-1", "/api/stream?interval=5"} {
+		rec, _ := env.Get(t, target)
+		if rec.Code != 400 {
+			t.Errorf("%s: got status %d, want 400", target, rec.Code)
+		}
+	}
+}
+
+func TestServeTitleSlowParameter(t *testing.T) {
+	env := tracetest.Start(t)
+	defer env.Close()
+
+	for _, tc := range []struct {
+		target string
+		slow   string
+	}{
+		{"/api", "false"},
+		{"/api?slow=false", "false"},
+		{"/api?slow=0", "false"},
+		{"/api?slow=true", "true"},
+		{"/api/titles?count=2&slow=false", "false"},
+		{"/api/titles?count=2&slow=1", "true"},
+	} {
+		rec, traceID := env.Get(t, tc.target)
+		if rec.Code != 200 {
+			t.Fatalf("%s: got status %d: %s", tc.target, rec.Code, rec.Body)
+		}
+		env.Trace(t, traceID).Find("frontend", "serve-http-request").Assert(t, tracetest.Want{
+			Attributes: map[string]string{"slow": tc.slow},
+		})
+	}
+
+	if rec, _ := env.Get(t, "/api?slow=maybe"); rec.Code != 400 {
+		t.Errorf("got status %d for a non-boolean slow, want 400", rec.Code)
+	}
+}
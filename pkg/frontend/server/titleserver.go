@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/johananl/otel-demo/pkg/frontend/i18n"
+	titlepb "github.com/johananl/otel-demo/proto/title"
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// titleServer serves titles over gRPC using the same generator as the HTTP
+// API.
+type titleServer struct {
+	titlepb.UnimplementedTitleServiceServer
+	gen *titleGenerator
+}
+
+func (s *titleServer) GenerateTitle(ctx context.Context, in *titlepb.TitleRequest) (*titlepb.TitleReply, error) {
+	log.Println("Received title request")
+
+	req := titleRequest{
+		Slow:    in.Slow,
+		Locale:  grpcLocale(in.Locale),
+		Tags:    in.Tags,
+		Seed:    in.Seed,
+		Latency: in.Latency,
+	}
+
+	// Get current span. The span was created within the gRPC interceptor.
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		key.New("slow").Bool(req.Slow),
+		key.New("locale").String(req.Locale),
+		key.New("tags").String(strings.Join(req.Tags, ",")),
+		key.New("latency").String(req.Latency),
+	)
+
+	res, err := s.gen.Generate(ctx, req)
+	if err != nil {
+		log.Printf("gRPC error: %v", err)
+		recordError(ctx, span, err)
+		return nil, grpcError(err)
+	}
+
+	span.AddEvent(ctx, "Generated title", key.New("title").String(res.Title))
+
+	return titleReply(res), nil
+}
+
+func (s *titleServer) GenerateTitles(ctx context.Context, in *titlepb.TitlesRequest) (*titlepb.TitlesReply, error) {
+	log.Printf("Received titles request for %d titles", in.Count)
+
+	if in.Count < 1 || in.Count > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "count must be between 1 and %d", maxBatchSize)
+	}
+
+	req := titleRequest{
+		Slow:    in.Slow,
+		Locale:  grpcLocale(in.Locale),
+		Tags:    in.Tags,
+		Latency: in.Latency,
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		key.New("batch.size").Int(int(in.Count)),
+		key.New("slow").Bool(req.Slow),
+		key.New("locale").String(req.Locale),
+		key.New("tags").String(strings.Join(req.Tags, ",")),
+		key.New("latency").String(req.Latency),
+	)
+
+	res, err := s.gen.GenerateBatch(ctx, req, int(in.Count))
+	if err != nil {
+		log.Printf("gRPC error: %v", err)
+		recordError(ctx, span, err)
+		return nil, grpcError(err)
+	}
+
+	titles := make([]*titlepb.TitleReply, len(res))
+	for i, r := range res {
+		titles[i] = titleReply(r)
+	}
+
+	return &titlepb.TitlesReply{Titles: titles}, nil
+}
+
+// grpcLocale returns the supported locale for a locale requested over gRPC.
+func grpcLocale(locale string) string {
+	if l := i18n.Match(locale); l != "" {
+		return l
+	}
+
+	return i18n.DefaultLocale
+}
+
+// grpcError converts a title generation error into a gRPC status error. The
+// status code of a failing backend is passed through to the client.
+func grpcError(err error) error {
+	be, ok := err.(*backendError)
+	if !ok {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	code := codes.Unavailable
+	if s, ok := status.FromError(be.err); ok && s.Code() != codes.Unknown {
+		code = s.Code()
+	}
+
+	return status.Error(code, err.Error())
+}
+
+func titleReply(r Response) *titlepb.TitleReply {
+	return &titlepb.TitleReply{
+		Seniority: r.Seniority,
+		Field:     r.Field,
+		Role:      r.Role,
+		Locale:    r.Locale,
+		Title:     r.Title,
+	}
+}
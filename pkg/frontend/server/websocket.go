@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/johananl/otel-demo/pkg/frontend/i18n"
+	"github.com/johananl/otel-demo/pkg/frontend/tracing"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/trace"
+	"golang.org/x/net/websocket"
+)
+
+// wsCommand is a message sent by a WebSocket client.
+type wsCommand struct {
+	// Type is the command type. Only "generate" is supported.
+	Type string `json:"type"`
+	// ID is an optional client-chosen identifier which is echoed in the reply.
+	ID     string   `json:"id,omitempty"`
+	Slow   bool     `json:"slow"`
+	Seed   int64    `json:"seed"`
+	Locale string   `json:"locale"`
+	Tags   []string `json:"tags"`
+	// Latency is a latency profile spec, e.g. "pareto:20ms,1.5".
+	Latency string `json:"latency"`
+}
+
+// wsReply is a message sent to a WebSocket client.
+type wsReply struct {
+	// Type is either "title" or "error".
+	Type string `json:"type"`
+	ID   string `json:"id,omitempty"`
+	// TraceID identifies the trace of the handled command.
+	TraceID string    `json:"trace_id"`
+	Title   *Response `json:"title,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// websocketHandler returns a handler which generates titles on behalf of
+// WebSocket clients. The connection is traced as one long span. Every command
+// is handled in a trace of its own which is linked to the connection span, so
+// that each title can be looked up by the trace ID sent back to the client.
+func websocketHandler(tr trace.Tracer, gen *titleGenerator) websocket.Handler {
+	return func(ws *websocket.Conn) {
+		defer ws.Close()
+
+		connCtx, parent := tracing.ExtractHTTP(ws.Request())
+		connCtx, connSpan := tr.Start(
+			connCtx,
+			"serve-websocket",
+			parent,
+			trace.WithSpanKind(trace.SpanKindServer),
+		)
+		defer connSpan.End()
+
+		defaultLocale := i18n.Locale(ws.Request())
+		messages := 0
+
+		for {
+			var cmd wsCommand
+			if err := websocket.JSON.Receive(ws, &cmd); err != nil {
+				if err != io.EOF {
+					log.Printf("Receiving WebSocket message: %v", err)
+					connSpan.AddEvent(connCtx, "Receive error", key.New("error").String(err.Error()))
+				}
+				break
+			}
+			messages++
+
+			reply := handleCommand(connCtx, tr, connSpan.SpanContext(), gen, cmd, defaultLocale)
+			connSpan.AddEvent(connCtx, "Handled message",
+				key.New("type").String(cmd.Type),
+				key.New("message.trace_id").String(reply.TraceID),
+			)
+
+			if err := websocket.JSON.Send(ws, reply); err != nil {
+				log.Printf("Sending WebSocket message: %v", err)
+				connSpan.AddEvent(connCtx, "Send error", key.New("error").String(err.Error()))
+				break
+			}
+		}
+
+		connSpan.SetAttributes(key.New("websocket.messages").Int(messages))
+	}
+}
+
+// handleCommand handles a single WebSocket command in a new trace linked to
+// the connection span.
+func handleCommand(connCtx context.Context, tr trace.Tracer, conn core.SpanContext, gen *titleGenerator, cmd wsCommand, defaultLocale string) wsReply {
+	// Drop the connection span from the context so that the message span
+	// becomes the root of a new trace rather than a child of the connection.
+	ctx := trace.ContextWithSpan(connCtx, trace.NoopSpan{})
+	ctx, span := tr.Start(
+		ctx,
+		"handle-websocket-message",
+		trace.LinkedTo(conn),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	reply := wsReply{
+		ID:      cmd.ID,
+		TraceID: span.SpanContext().TraceIDString(),
+	}
+
+	if cmd.Type != "generate" {
+		reply.Type = "error"
+		reply.Error = "unknown command type"
+		span.AddEvent(ctx, "Unknown command", key.New("type").String(cmd.Type))
+		return reply
+	}
+
+	locale := i18n.Match(cmd.Locale)
+	if locale == "" {
+		locale = defaultLocale
+	}
+
+	req := titleRequest{
+		Slow:    cmd.Slow,
+		Locale:  locale,
+		Tags:    cmd.Tags,
+		Seed:    cmd.Seed,
+		Latency: cmd.Latency,
+	}
+	span.SetAttributes(
+		key.New("slow").Bool(req.Slow),
+		key.New("seed").Int64(req.Seed),
+		key.New("locale").String(req.Locale),
+		key.New("tags").String(strings.Join(req.Tags, ",")),
+	)
+
+	res, err := gen.Generate(ctx, req)
+	if err != nil {
+		log.Printf("gRPC error: %v", err)
+		recordError(ctx, span, err)
+		reply.Type = "error"
+		reply.Error = errorMessage(err)
+		return reply
+	}
+
+	span.AddEvent(ctx, "Generated title", key.New("title").String(res.Title))
+
+	reply.Type = "title"
+	reply.Title = &res
+	return reply
+}
@@ -2,21 +2,85 @@ package tracing
 
 import (
 	"context"
+	"net/http"
 
+	"github.com/johananl/otel-demo/pkg/spanlink"
+	"go.opentelemetry.io/otel/api/distributedcontext"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/trace"
 	"go.opentelemetry.io/otel/plugin/grpctrace"
+	"go.opentelemetry.io/otel/plugin/httptrace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 )
 
-// UnaryClientInterceptor intercepts and injects outgoing trace data.
+var tr = global.TraceProvider().Tracer("frontend")
+
+// UnaryClientInterceptor intercepts and injects outgoing trace data,
+// including the span links carried by the context.
 func UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 	requestMetadata, _ := metadata.FromOutgoingContext(ctx)
 	metadataCopy := requestMetadata.Copy()
 
 	grpctrace.Inject(ctx, &metadataCopy)
+	spanlink.Inject(ctx, &metadataCopy)
 	ctx = metadata.NewOutgoingContext(ctx, metadataCopy)
 
 	err := invoker(ctx, method, req, reply, cc, opts...)
 
 	return err
 }
+
+// StreamClientInterceptor intercepts and injects outgoing trace data for
+// streaming RPCs.
+func StreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	requestMetadata, _ := metadata.FromOutgoingContext(ctx)
+	metadataCopy := requestMetadata.Copy()
+
+	grpctrace.Inject(ctx, &metadataCopy)
+	spanlink.Inject(ctx, &metadataCopy)
+	ctx = metadata.NewOutgoingContext(ctx, metadataCopy)
+
+	return streamer(ctx, desc, cc, method, opts...)
+}
+
+// UnaryServerInterceptor intercepts and extracts incoming trace data.
+func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return NewUnaryServerInterceptor(tr)(ctx, req, info, handler)
+}
+
+// NewUnaryServerInterceptor returns a UnaryServerInterceptor which records
+// spans using the given tracer rather than the tracer of the global provider.
+func NewUnaryServerInterceptor(tr trace.Tracer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		requestMetadata, _ := metadata.FromIncomingContext(ctx)
+		metadataCopy := requestMetadata.Copy()
+
+		entries, spanCtx := grpctrace.Extract(ctx, &metadataCopy)
+		ctx = distributedcontext.WithMap(ctx, distributedcontext.NewMap(distributedcontext.MapUpdate{
+			MultiKV: entries,
+		}))
+
+		ctx, span := tr.Start(
+			ctx,
+			"handle-grpc-request",
+			trace.ChildOf(spanCtx),
+			trace.WithSpanKind(trace.SpanKindServer),
+		)
+		defer span.End()
+
+		return handler(ctx, req)
+	}
+}
+
+// ExtractHTTP extracts incoming trace data from an HTTP request. The returned
+// context carries the correlation context of the request and the returned
+// option makes a new span a child of the remote span, if there is one.
+func ExtractHTTP(r *http.Request) (context.Context, trace.StartOption) {
+	_, entries, spanCtx := httptrace.Extract(r.Context(), r)
+	ctx := distributedcontext.WithMap(r.Context(), distributedcontext.NewMap(distributedcontext.MapUpdate{
+		MultiKV: entries,
+	}))
+
+	return ctx, trace.ChildOf(spanCtx)
+}
@@ -0,0 +1,330 @@
+// Package latency injects artificial delays drawn from configurable
+// distributions, so that realistic tail latency shows up in traces.
+package latency
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/trace"
+)
+
+// DefaultSpec is the profile used for slow requests unless configured
+// otherwise. It matches the original slow mode of up to 300ms.
+const DefaultSpec = "uniform:0ms,300ms"
+
+// maxDelay caps the delays of all profiles. Parse rejects profiles whose
+// bounds or means exceed it.
+const maxDelay = 30 * time.Second
+
+// Profile is a latency distribution.
+type Profile interface {
+	// Delay draws a delay from the distribution.
+	Delay() time.Duration
+	// String returns the spec of the profile as accepted by Parse.
+	String() string
+}
+
+// Fixed always returns the same delay.
+type Fixed struct {
+	D time.Duration
+}
+
+func (p Fixed) Delay() time.Duration { return limit(p.D) }
+func (p Fixed) String() string       { return fmt.Sprintf("fixed:%v", p.D) }
+
+// Uniform returns delays uniformly distributed between Min and Max.
+type Uniform struct {
+	Min, Max time.Duration
+}
+
+func (p Uniform) Delay() time.Duration {
+	min, max := limit(p.Min), limit(p.Max)
+	if max <= min {
+		return min
+	}
+
+	return min + time.Duration(rand.Int63n(int64(max-min)+1))
+}
+
+func (p Uniform) String() string { return fmt.Sprintf("uniform:%v,%v", p.Min, p.Max) }
+
+// Normal returns normally distributed delays. Negative draws are clamped to 0.
+type Normal struct {
+	Mean, StdDev time.Duration
+}
+
+func (p Normal) Delay() time.Duration {
+	return clamp(float64(p.Mean) + rand.NormFloat64()*float64(p.StdDev))
+}
+
+func (p Normal) String() string { return fmt.Sprintf("normal:%v,%v", p.Mean, p.StdDev) }
+
+// Pareto returns long-tailed delays of at least Scale. The smaller Shape is,
+// the longer the tail.
+type Pareto struct {
+	Scale time.Duration
+	Shape float64
+}
+
+func (p Pareto) Delay() time.Duration {
+	u := 1 - rand.Float64() // (0, 1]
+	return clamp(float64(p.Scale) / math.Pow(u, 1/p.Shape))
+}
+
+func (p Pareto) String() string {
+	return fmt.Sprintf("pareto:%v,%s", p.Scale, strconv.FormatFloat(p.Shape, 'g', -1, 64))
+}
+
+// Bimodal mixes a fast and a slow mode. A delay is drawn from the slow mode
+// with probability SlowRatio. Both modes are normally distributed with a
+// standard deviation of 10% of their mean.
+type Bimodal struct {
+	Fast, Slow time.Duration
+	SlowRatio  float64
+}
+
+func (p Bimodal) Delay() time.Duration {
+	mean := p.Fast
+	if rand.Float64() < p.SlowRatio {
+		mean = p.Slow
+	}
+
+	return Normal{Mean: mean, StdDev: mean / 10}.Delay()
+}
+
+func (p Bimodal) String() string {
+	return fmt.Sprintf("bimodal:%v,%v,%s", p.Fast, p.Slow, strconv.FormatFloat(p.SlowRatio, 'g', -1, 64))
+}
+
+// limit clamps d to [0, maxDelay].
+func limit(d time.Duration) time.Duration {
+	switch {
+	case d < 0:
+		return 0
+	case d > maxDelay:
+		return maxDelay
+	default:
+		return d
+	}
+}
+
+func clamp(d float64) time.Duration {
+	switch {
+	case d < 0:
+		return 0
+	case d > float64(maxDelay):
+		return maxDelay
+	default:
+		return time.Duration(d)
+	}
+}
+
+// Parse parses a profile spec of the form "<kind>:<arg>,<arg>...":
+//
+//	fixed:100ms
+//	uniform:10ms,300ms
+//	normal:100ms,20ms
+//	pareto:20ms,1.5
+//	bimodal:20ms,400ms,0.1
+//
+// Delays, bounds and means must not exceed 30s. The spec "none" disables
+// delays and yields a nil profile.
+func Parse(spec string) (Profile, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "none" {
+		return nil, nil
+	}
+
+	kind := spec
+	var args []string
+	if i := strings.Index(spec, ":"); i >= 0 {
+		kind = spec[:i]
+		args = strings.Split(spec[i+1:], ",")
+	}
+
+	p := &parser{args: args}
+	var profile Profile
+	switch kind {
+	case "fixed":
+		profile = Fixed{D: p.duration(0)}
+	case "uniform":
+		profile = Uniform{Min: p.duration(0), Max: p.duration(1)}
+	case "normal":
+		profile = Normal{Mean: p.duration(0), StdDev: p.duration(1)}
+	case "pareto":
+		profile = Pareto{Scale: p.duration(0), Shape: p.float(1)}
+	case "bimodal":
+		profile = Bimodal{Fast: p.duration(0), Slow: p.duration(1), SlowRatio: p.float(2)}
+	default:
+		return nil, fmt.Errorf("unknown latency profile %q", kind)
+	}
+	if p.err != nil {
+		return nil, fmt.Errorf("parsing latency profile %q: %v", spec, p.err)
+	}
+	if p.used != len(args) {
+		return nil, fmt.Errorf("parsing latency profile %q: expected %d arguments", spec, p.used)
+	}
+
+	if err := validate(profile); err != nil {
+		return nil, fmt.Errorf("invalid latency profile %q: %v", spec, err)
+	}
+
+	return profile, nil
+}
+
+func validate(p Profile) error {
+	switch p := p.(type) {
+	case Fixed:
+		if p.D < 0 || p.D > maxDelay {
+			return fmt.Errorf("delay must be between 0 and %v", maxDelay)
+		}
+	case Uniform:
+		if p.Min < 0 || p.Max < p.Min || p.Max > maxDelay {
+			return fmt.Errorf("range must satisfy 0 <= min <= max <= %v", maxDelay)
+		}
+	case Normal:
+		if p.Mean < 0 || p.StdDev < 0 || p.Mean > maxDelay || p.StdDev > maxDelay {
+			return fmt.Errorf("mean and standard deviation must be between 0 and %v", maxDelay)
+		}
+	case Pareto:
+		if p.Scale <= 0 || p.Shape <= 0 || p.Scale > maxDelay {
+			return fmt.Errorf("scale and shape must be positive and the scale at most %v", maxDelay)
+		}
+	case Bimodal:
+		if p.Fast < 0 || p.Slow < 0 || p.Fast > maxDelay || p.Slow > maxDelay || p.SlowRatio < 0 || p.SlowRatio > 1 {
+			return fmt.Errorf("delays must be between 0 and %v and the ratio between 0 and 1", maxDelay)
+		}
+	}
+
+	return nil
+}
+
+// parser collects the arguments of a profile spec. The first error sticks.
+type parser struct {
+	args []string
+	used int
+	err  error
+}
+
+func (p *parser) arg(i int) string {
+	if i+1 > p.used {
+		p.used = i + 1
+	}
+	if i >= len(p.args) {
+		if p.err == nil {
+			p.err = fmt.Errorf("missing argument %d", i+1)
+		}
+		return ""
+	}
+
+	return strings.TrimSpace(p.args[i])
+}
+
+func (p *parser) duration(i int) time.Duration {
+	s := p.arg(i)
+	if p.err != nil {
+		return 0
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		p.err = err
+	}
+
+	return d
+}
+
+func (p *parser) float(i int) float64 {
+	s := p.arg(i)
+	if p.err != nil {
+		return 0
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		p.err = err
+	}
+
+	return f
+}
+
+// ForRequest returns the profile to apply to a request. An explicit spec takes
+// precedence. Otherwise slow requests use the default profile and all other
+// requests aren't delayed, in which case nil is returned.
+func ForRequest(slow bool, spec string, def Profile) (Profile, error) {
+	if spec != "" {
+		return Parse(spec)
+	}
+	if slow {
+		return def, nil
+	}
+
+	return nil, nil
+}
+
+// Inject sleeps for a delay drawn from p and records it as an event on the
+// current span. It returns early with the context's error if ctx is done
+// before the delay elapsed. A nil profile doesn't delay at all.
+func Inject(ctx context.Context, p Profile) error {
+	if p == nil {
+		return nil
+	}
+
+	d := p.Delay()
+	trace.SpanFromContext(ctx).AddEvent(ctx, "Injected delay",
+		key.New("latency.profile").String(p.String()),
+		key.New("latency.delay_ms").Float64(float64(d)/float64(time.Millisecond)),
+	)
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Value holds a profile which can be replaced while it is in use.
+type Value struct {
+	mu sync.RWMutex
+	p  Profile
+}
+
+// NewValue returns a value holding p.
+func NewValue(p Profile) *Value {
+	return &Value{p: p}
+}
+
+// Load returns the current profile.
+func (v *Value) Load() Profile {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return v.p
+}
+
+// Store replaces the current profile.
+func (v *Value) Store(p Profile) {
+	v.mu.Lock()
+	v.p = p
+	v.mu.Unlock()
+}
+
+// SpecOf returns the spec of p as accepted by Parse. A nil profile yields
+// "none".
+func SpecOf(p Profile) string {
+	if p == nil {
+		return "none"
+	}
+
+	return p.String()
+}
@@ -0,0 +1,48 @@
+package latency
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseBounds(t *testing.T) {
+	for _, spec := range []string{
+		"fixed:31s",
+		"fixed:-1ms",
+		"uniform:0s,2562047h47m16.854775807s",
+		"uniform:-2562047h47m16.854775808s,1s",
+		"normal:1h,1s",
+		"pareto:1h,1.5",
+		"bimodal:20ms,1h,0.1",
+	} {
+		if p, err := Parse(spec); err == nil {
+			t.Errorf("%s: got profile %v, want an error", spec, p)
+		}
+	}
+
+	for _, spec := range []string{"fixed:30s", "uniform:0s,30s", "uniform:10ms,10ms", "pareto:20ms,0.01"} {
+		p, err := Parse(spec)
+		if err != nil {
+			t.Errorf("%s: %v", spec, err)
+			continue
+		}
+		for i := 0; i < 100; i++ {
+			if d := p.Delay(); d < 0 || d > maxDelay {
+				t.Fatalf("%s: got delay %v, want at most %v", spec, d, maxDelay)
+			}
+		}
+	}
+}
+
+func TestDelayLimits(t *testing.T) {
+	// Profiles which weren't parsed, e.g. set by code, are clamped as well.
+	for _, p := range []Profile{
+		Fixed{D: time.Hour},
+		Uniform{Min: 0, Max: 1<<63 - 1},
+		Uniform{Min: time.Hour, Max: time.Second},
+	} {
+		if d := p.Delay(); d < 0 || d > maxDelay {
+			t.Errorf("%v: got delay %v, want at most %v", p, d, maxDelay)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Package logging adds levels to the standard logger. The level can be
+// changed at runtime.
+package logging
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+)
+
+// Level is the severity of a log message.
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("Level(%d)", int32(l))
+}
+
+// ParseLevel parses a level name such as "debug" or "WARN".
+func ParseLevel(s string) (Level, error) {
+	for l, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			return l, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown log level %q", s)
+}
+
+var level = int32(LevelInfo)
+
+// SetLevel sets the minimum level of messages which are logged.
+func SetLevel(l Level) {
+	atomic.StoreInt32(&level, int32(l))
+}
+
+// CurrentLevel returns the minimum level of messages which are logged.
+func CurrentLevel() Level {
+	return Level(atomic.LoadInt32(&level))
+}
+
+func logf(l Level, format string, v ...interface{}) {
+	if l < CurrentLevel() {
+		return
+	}
+	log.Output(3, fmt.Sprintf(format, v...))
+}
+
+// Debugf logs a message at debug level.
+func Debugf(format string, v ...interface{}) { logf(LevelDebug, format, v...) }
+
+// Infof logs a message at info level.
+func Infof(format string, v ...interface{}) { logf(LevelInfo, format, v...) }
+
+// Warnf logs a message at warn level.
+func Warnf(format string, v ...interface{}) { logf(LevelWarn, format, v...) }
+
+// Errorf logs a message at error level.
+func Errorf(format string, v ...interface{}) { logf(LevelError, format, v...) }
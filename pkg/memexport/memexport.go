@@ -0,0 +1,53 @@
+// Package memexport provides a span exporter which keeps finished spans in
+// memory, so that tests can inspect them.
+package memexport
+
+import (
+	"context"
+	"sync"
+
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// Exporter records exported spans in memory. It is safe for concurrent use.
+type Exporter struct {
+	mu    sync.Mutex
+	spans []*export.SpanData
+}
+
+var _ export.SpanSyncer = (*Exporter)(nil)
+
+// New returns an empty exporter.
+func New() *Exporter {
+	return &Exporter{}
+}
+
+// ExportSpan records d.
+func (e *Exporter) ExportSpan(ctx context.Context, d *export.SpanData) {
+	e.mu.Lock()
+	e.spans = append(e.spans, d)
+	e.mu.Unlock()
+}
+
+// Spans returns the recorded spans in the order they were exported.
+func (e *Exporter) Spans() []*export.SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]*export.SpanData(nil), e.spans...)
+}
+
+// Len returns the number of recorded spans.
+func (e *Exporter) Len() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return len(e.spans)
+}
+
+// Reset drops all recorded spans.
+func (e *Exporter) Reset() {
+	e.mu.Lock()
+	e.spans = nil
+	e.mu.Unlock()
+}
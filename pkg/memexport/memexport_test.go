@@ -0,0 +1,45 @@
+package memexport
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/api/trace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestExporter(t *testing.T) {
+	exp := New()
+	tp, err := sdktrace.NewProvider(
+		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.AlwaysSample()}),
+		sdktrace.WithSyncer(exp),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tp.Tracer("test")
+
+	ctx, parent := tr.Start(context.Background(), "parent", trace.WithSpanKind(trace.SpanKindServer))
+	_, child := tr.Start(ctx, "child")
+	child.End()
+	parent.End()
+
+	spans := exp.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	if spans[0].Name != "test/child" || spans[1].Name != "test/parent" {
+		t.Errorf("got spans %q and %q, want child before parent", spans[0].Name, spans[1].Name)
+	}
+	if spans[0].ParentSpanID != spans[1].SpanContext.SpanID {
+		t.Error("child span isn't linked to its parent")
+	}
+	if spans[1].SpanKind != trace.SpanKindServer {
+		t.Errorf("parent kind is %s, want server", spans[1].SpanKind)
+	}
+
+	exp.Reset()
+	if n := exp.Len(); n != 0 {
+		t.Errorf("got %d spans after reset, want 0", n)
+	}
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// grpcDuration records the time spent handling gRPC requests by method and
+// status code.
+var grpcDuration = NewHistogramVec(
+	"grpc_server_handling_seconds",
+	"Time spent handling gRPC requests.",
+	LatencyBuckets,
+	"grpc_method", "grpc_code",
+)
+
+func init() {
+	Default.Register(grpcDuration)
+}
+
+// UnaryServerInterceptor records the duration of unary calls. It must run
+// after the tracing interceptor, so that exemplars carry the server span.
+func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	grpcDuration.Observe(ctx, time.Since(start).Seconds(), info.FullMethod, status.Code(err).String())
+
+	return resp, err
+}
+
+// StreamServerInterceptor records the duration of streaming calls. It must
+// run after the tracing interceptor, so that exemplars carry the server span.
+func StreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	grpcDuration.Observe(ss.Context(), time.Since(start).Seconds(), info.FullMethod, status.Code(err).String())
+
+	return err
+}
@@ -0,0 +1,140 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/api/trace"
+)
+
+// LatencyBuckets are bucket bounds in seconds suitable for request
+// latencies, from a few milliseconds up to the backend timeout.
+var LatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Exemplar is an observation which illustrates a bucket, typically carrying
+// the ID of the trace of the observed request.
+type Exemplar struct {
+	Labels    []Label
+	Value     float64
+	Timestamp time.Time
+}
+
+// HistogramVec is a set of histograms with the same bucket bounds, one per
+// combination of label values. Every bucket keeps the most recent exemplar of
+// a sampled trace. It is safe for concurrent use.
+type HistogramVec struct {
+	name       string
+	help       string
+	buckets    []float64
+	labelNames []string
+
+	mu     sync.Mutex
+	series map[string]*histogram
+}
+
+// histogram holds the observations with the same label values. counts and
+// exemplars are per bucket and not cumulative; the last element is the +Inf
+// bucket.
+type histogram struct {
+	labelValues []string
+	counts      []uint64
+	exemplars   []*Exemplar
+	sum         float64
+	count       uint64
+}
+
+// NewHistogramVec returns a histogram set with the given bucket upper bounds,
+// which must be sorted, and label names.
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	return &HistogramVec{
+		name:       name,
+		help:       help,
+		buckets:    buckets,
+		labelNames: labelNames,
+		series:     map[string]*histogram{},
+	}
+}
+
+// Observe records v for the given label values. If ctx carries a sampled
+// span, the observation becomes the exemplar of its bucket.
+func (h *HistogramVec) Observe(ctx context.Context, v float64, labelValues ...string) {
+	var ex *Exemplar
+	if sc := trace.SpanFromContext(ctx).SpanContext(); sc.IsSampled() {
+		ex = &Exemplar{
+			Labels: []Label{
+				{"trace_id", sc.TraceIDString()},
+				{"span_id", sc.SpanIDString()},
+			},
+			Value:     v,
+			Timestamp: time.Now(),
+		}
+	}
+
+	i := sort.SearchFloat64s(h.buckets, v)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	k := strings.Join(labelValues, "\xff")
+	s, ok := h.series[k]
+	if !ok {
+		s = &histogram{
+			labelValues: append([]string(nil), labelValues...),
+			counts:      make([]uint64, len(h.buckets)+1),
+			exemplars:   make([]*Exemplar, len(h.buckets)+1),
+		}
+		h.series[k] = s
+	}
+	s.counts[i]++
+	s.sum += v
+	s.count++
+	if ex != nil {
+		s.exemplars[i] = ex
+	}
+}
+
+// Collect returns the histograms with cumulative bucket counts.
+func (h *HistogramVec) Collect() []Family {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	keys := make([]string, 0, len(h.series))
+	for k := range h.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	f := Family{Name: h.name, Help: h.help, Type: Histogram}
+	for _, k := range keys {
+		s := h.series[k]
+		labels := make([]Label, len(h.labelNames))
+		for i, name := range h.labelNames {
+			labels[i] = Label{name, s.labelValues[i]}
+		}
+
+		var cumulative uint64
+		for i, n := range s.counts {
+			cumulative += n
+			le := "+Inf"
+			if i < len(h.buckets) {
+				le = strconv.FormatFloat(h.buckets[i], 'g', -1, 64)
+			}
+			f.Samples = append(f.Samples, Sample{
+				Suffix:   "_bucket",
+				Labels:   append(labels[:len(labels):len(labels)], Label{"le", le}),
+				Value:    float64(cumulative),
+				Exemplar: s.exemplars[i],
+			})
+		}
+		f.Samples = append(f.Samples,
+			Sample{Suffix: "_sum", Labels: labels, Value: s.sum},
+			Sample{Suffix: "_count", Labels: labels, Value: float64(s.count)},
+		)
+	}
+
+	return []Family{f}
+}
@@ -0,0 +1,238 @@
+// Package metrics collects metrics about the services and exposes them in the
+// Prometheus text format or, if the scraper asks for it, in the OpenMetrics
+// format, so that they can be put on dashboards next to the traces.
+//
+// Metrics are produced by collectors registered with a Registry. The Default
+// registry comes with collectors for Go runtime and process metrics, so every
+// service reports e.g. its number of goroutines simply by serving Handler.
+//
+// Histogram buckets carry exemplars holding the ID of the trace of a recent
+// request. Exemplars are only part of the OpenMetrics format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Type is the type of a metric.
+type Type string
+
+// Metric types.
+const (
+	Counter   Type = "counter"
+	Gauge     Type = "gauge"
+	Summary   Type = "summary"
+	Histogram Type = "histogram"
+)
+
+// Label is a dimension of a sample.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is a single value of a metric.
+type Sample struct {
+	// Suffix is appended to the metric name, e.g. "_bucket" for histograms.
+	Suffix string
+	Labels []Label
+	Value  float64
+	// Exemplar is an optional exemplar of the sample.
+	Exemplar *Exemplar
+}
+
+// Family is a metric and its samples.
+type Family struct {
+	Name    string
+	Help    string
+	Type    Type
+	Samples []Sample
+}
+
+// Collector produces metrics. Collect is called on every scrape.
+type Collector interface {
+	Collect() []Family
+}
+
+// CollectorFunc adapts a function to the Collector interface.
+type CollectorFunc func() []Family
+
+// Collect calls f.
+func (f CollectorFunc) Collect() []Family {
+	return f()
+}
+
+// GaugeFunc returns a collector of a single gauge whose value is returned by
+// fn.
+func GaugeFunc(name, help string, fn func() float64) Collector {
+	return CollectorFunc(func() []Family {
+		return []Family{{Name: name, Help: help, Type: Gauge, Samples: []Sample{{Value: fn()}}}}
+	})
+}
+
+// Registry holds collectors. It is safe for concurrent use.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []Collector
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Default is the registry served by Handler. It collects Go runtime and
+// process metrics.
+var Default = NewRegistry()
+
+func init() {
+	Default.Register(NewRuntimeCollector())
+	Default.Register(NewProcessCollector())
+}
+
+// Register adds c to the registry.
+func (r *Registry) Register(c Collector) {
+	r.mu.Lock()
+	r.collectors = append(r.collectors, c)
+	r.mu.Unlock()
+}
+
+// Gather collects all metrics sorted by name.
+func (r *Registry) Gather() []Family {
+	r.mu.Lock()
+	collectors := append([]Collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	var families []Family
+	for _, c := range collectors {
+		families = append(families, c.Collect()...)
+	}
+	sort.SliceStable(families, func(i, j int) bool {
+		return families[i].Name < families[j].Name
+	})
+
+	return families
+}
+
+// Handler serves the metrics of the registry in the OpenMetrics format if
+// the request accepts it and in the Prometheus text format otherwise.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.Header.Get("Accept"), "application/openmetrics-text") {
+			w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+			WriteOpenMetrics(w, r.Gather())
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteText(w, r.Gather())
+	})
+}
+
+// Handler serves the metrics of the Default registry.
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+// WriteText writes families in the Prometheus text format.
+func WriteText(w io.Writer, families []Family) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		fmt.Fprintf(bw, "# HELP %s %s\n", f.Name, escape(f.Help, false))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.Name, f.Type)
+		for _, s := range f.Samples {
+			bw.WriteString(f.Name + s.Suffix)
+			writeLabels(bw, s.Labels)
+			bw.WriteString(" " + formatValue(s.Value) + "\n")
+		}
+	}
+
+	return bw.Flush()
+}
+
+// WriteOpenMetrics writes families in the OpenMetrics text format, including
+// exemplars.
+func WriteOpenMetrics(w io.Writer, families []Family) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		// The metadata of counters names the metric without the "_total"
+		// suffix of its samples.
+		name := f.Name
+		if f.Type == Counter {
+			name = strings.TrimSuffix(name, "_total")
+		}
+		fmt.Fprintf(bw, "# TYPE %s %s\n", name, f.Type)
+		fmt.Fprintf(bw, "# HELP %s %s\n", name, escape(f.Help, true))
+		for _, s := range f.Samples {
+			sampleName := f.Name + s.Suffix
+			if f.Type == Counter && !strings.HasSuffix(sampleName, "_total") {
+				sampleName += "_total"
+			}
+			bw.WriteString(sampleName)
+			writeLabels(bw, s.Labels)
+			bw.WriteString(" " + formatValue(s.Value))
+			if ex := s.Exemplar; ex != nil {
+				bw.WriteString(" # {")
+				writeLabelPairs(bw, ex.Labels)
+				bw.WriteString("} " + formatValue(ex.Value))
+				ts := float64(ex.Timestamp.UnixNano()) / 1e9
+				bw.WriteString(" " + strconv.FormatFloat(ts, 'f', 3, 64))
+			}
+			bw.WriteString("\n")
+		}
+	}
+	bw.WriteString("# EOF\n")
+
+	return bw.Flush()
+}
+
+func writeLabels(w *bufio.Writer, labels []Label) {
+	if len(labels) == 0 {
+		return
+	}
+
+	w.WriteByte('{')
+	writeLabelPairs(w, labels)
+	w.WriteByte('}')
+}
+
+func writeLabelPairs(w *bufio.Writer, labels []Label) {
+	for i, l := range labels {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		fmt.Fprintf(w, "%s=\"%s\"", l.Name, escape(l.Value, true))
+	}
+}
+
+// escape escapes backslashes and line feeds and, in label values, double
+// quotes.
+func escape(s string, quotes bool) string {
+	r := strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	if quotes {
+		r = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	}
+
+	return r.Replace(s)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
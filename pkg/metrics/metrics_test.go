@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/api/trace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestWriteText(t *testing.T) {
+	var b bytes.Buffer
+	err := WriteText(&b, []Family{
+		{
+			Name: "requests_total", Help: "Number of requests.", Type: Counter,
+			Samples: []Sample{{Labels: []Label{{"path", `/a"b`}}, Value: 3}},
+		},
+		{
+			Name: "latency_seconds", Help: "Request latency.", Type: Histogram,
+			Samples: []Sample{
+				{Suffix: "_bucket", Labels: []Label{{"le", "0.1"}}, Value: 1},
+				{Suffix: "_bucket", Labels: []Label{{"le", "+Inf"}}, Value: 2},
+				{Suffix: "_sum", Value: 0.35},
+				{Suffix: "_count", Value: 2},
+			},
+		},
+		{Name: "up", Help: "Multi\nline.", Type: Gauge, Samples: []Sample{{Value: math.Inf(1)}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# HELP requests_total Number of requests.
+# TYPE requests_total counter
+requests_total{path="/a\"b"} 3
+# HELP latency_seconds Request latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 1
+latency_seconds_bucket{le="+Inf"} 2
+latency_seconds_sum 0.35
+latency_seconds_count 2
+# HELP up Multi\nline.
+# TYPE up gauge
+up +Inf
+`
+	if got := b.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	names := map[string]bool{}
+	for _, f := range Default.Gather() {
+		names[f.Name] = true
+	}
+	for _, name := range []string{"go_goroutines", "go_memstats_heap_alloc_bytes", "go_gc_duration_seconds"} {
+		if !names[name] {
+			t.Errorf("metric %s missing", name)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := WriteText(&b, Default.Gather()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "# TYPE go_goroutines gauge\n") {
+		t.Error("go_goroutines isn't written as a gauge")
+	}
+}
+
+func TestHistogramExemplars(t *testing.T) {
+	tp, err := sdktrace.NewProvider(sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.AlwaysSample()}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, span := tp.Tracer("test").Start(context.Background(), "request", trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	h := NewHistogramVec("latency_seconds", "Request latency.", []float64{0.1, 1}, "path")
+	h.Observe(context.Background(), 0.05, "/api")
+	h.Observe(ctx, 0.5, "/api")
+
+	families := h.Collect()
+	for _, f := range families {
+		for i := range f.Samples {
+			if ex := f.Samples[i].Exemplar; ex != nil {
+				ex.Timestamp = time.Unix(1, 0)
+			}
+		}
+	}
+
+	var b bytes.Buffer
+	if err := WriteOpenMetrics(&b, families); err != nil {
+		t.Fatal(err)
+	}
+
+	sc := span.SpanContext()
+	want := `# TYPE latency_seconds histogram
+# HELP latency_seconds Request latency.
+latency_seconds_bucket{path="/api",le="0.1"} 1
+latency_seconds_bucket{path="/api",le="1"} 2 # {trace_id="` + sc.TraceIDString() + `",span_id="` + sc.SpanIDString() + `"} 0.5 1.000
+latency_seconds_bucket{path="/api",le="+Inf"} 2
+latency_seconds_sum{path="/api"} 0.55
+latency_seconds_count{path="/api"} 2
+# EOF
+`
+	if got := b.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
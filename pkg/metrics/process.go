@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// procDir is the proc file system directory of this process.
+var procDir = "/proc/self"
+
+// clockTicks is the number of clock ticks per second used by the proc file
+// system. It is 100 on virtually all Linux systems.
+const clockTicks = 100
+
+// NewProcessCollector returns a collector of process metrics: CPU time,
+// resident memory and file descriptors. The metrics are read from the proc
+// file system, so they are only available on Linux; metrics which can't be
+// read are left out.
+func NewProcessCollector() Collector {
+	return CollectorFunc(collectProcess)
+}
+
+func collectProcess() []Family {
+	var families []Family
+
+	if stat, err := readStat(); err == nil {
+		families = append(families,
+			counter("process_cpu_seconds_total", "Total user and system CPU time spent in seconds.",
+				(stat.utime+stat.stime)/clockTicks),
+			gauge("process_resident_memory_bytes", "Resident memory size in bytes.",
+				stat.rss*float64(os.Getpagesize())),
+			gauge("process_virtual_memory_bytes", "Virtual memory size in bytes.", stat.vsize),
+		)
+		if boot, err := bootTime(); err == nil {
+			families = append(families, gauge("process_start_time_seconds",
+				"Start time of the process since the Unix epoch in seconds.", boot+stat.starttime/clockTicks))
+		}
+	}
+
+	if fds, err := ioutil.ReadDir(procDir + "/fd"); err == nil {
+		families = append(families, gauge("process_open_fds", "Number of open file descriptors.", float64(len(fds))))
+	}
+	if max, err := maxFDs(); err == nil {
+		families = append(families, gauge("process_max_fds", "Maximum number of open file descriptors.", max))
+	}
+
+	return families
+}
+
+// procStat holds the fields of /proc/self/stat which are reported.
+type procStat struct {
+	utime, stime float64
+	starttime    float64
+	vsize, rss   float64
+}
+
+func readStat() (procStat, error) {
+	b, err := ioutil.ReadFile(procDir + "/stat")
+	if err != nil {
+		return procStat{}, err
+	}
+
+	// The command name in the second field may contain spaces, so fields are
+	// counted from the closing parenthesis. fields[0] is the third field.
+	if i := bytes.LastIndexByte(b, ')'); i >= 0 {
+		b = b[i+1:]
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) < 22 {
+		return procStat{}, strconv.ErrSyntax
+	}
+
+	var s procStat
+	for _, f := range []struct {
+		v     *float64
+		index int
+	}{
+		{&s.utime, 11}, {&s.stime, 12}, {&s.starttime, 19}, {&s.vsize, 20}, {&s.rss, 21},
+	} {
+		if *f.v, err = strconv.ParseFloat(fields[f.index], 64); err != nil {
+			return procStat{}, err
+		}
+	}
+
+	return s, nil
+}
+
+// bootTime returns the boot time of the system since the Unix epoch in
+// seconds.
+func bootTime() (float64, error) {
+	b, err := ioutil.ReadFile("/proc/stat")
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.HasPrefix(line, "btime ") {
+			return strconv.ParseFloat(strings.TrimSpace(strings.TrimPrefix(line, "btime ")), 64)
+		}
+	}
+
+	return 0, os.ErrNotExist
+}
+
+// maxFDs returns the soft limit of open file descriptors.
+func maxFDs() (float64, error) {
+	b, err := ioutil.ReadFile(procDir + "/limits")
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if !strings.HasPrefix(line, "Max open files") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "Max open files"))
+		if len(fields) == 0 {
+			break
+		}
+		if fields[0] == "unlimited" {
+			return 0, os.ErrNotExist
+		}
+		return strconv.ParseFloat(fields[0], 64)
+	}
+
+	return 0, os.ErrNotExist
+}
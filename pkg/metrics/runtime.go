@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"time"
+)
+
+// gcQuantiles are the quantiles of GC pause durations which are reported.
+var gcQuantiles = []float64{0, 0.25, 0.5, 0.75, 1}
+
+// NewRuntimeCollector returns a collector of Go runtime metrics: goroutines,
+// threads, heap usage, garbage collection pauses and, when built with Go 1.16
+// or later, scheduler latencies.
+func NewRuntimeCollector() Collector {
+	return CollectorFunc(collectRuntime)
+}
+
+func collectRuntime() []Family {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	threads, _ := runtime.ThreadCreateProfile(nil)
+
+	families := []Family{
+		gauge("go_info", "Information about the Go runtime.", 1, Label{"version", runtime.Version()}),
+		gauge("go_goroutines", "Number of goroutines that currently exist.", float64(runtime.NumGoroutine())),
+		gauge("go_threads", "Number of OS threads created.", float64(threads)),
+		gauge("go_memstats_heap_alloc_bytes", "Number of heap bytes allocated and still in use.", float64(ms.HeapAlloc)),
+		gauge("go_memstats_heap_inuse_bytes", "Number of heap bytes that are in use.", float64(ms.HeapInuse)),
+		gauge("go_memstats_heap_sys_bytes", "Number of heap bytes obtained from the system.", float64(ms.HeapSys)),
+		gauge("go_memstats_heap_objects", "Number of allocated objects.", float64(ms.HeapObjects)),
+		gauge("go_memstats_next_gc_bytes", "Heap size at which the next garbage collection will take place.", float64(ms.NextGC)),
+		counter("go_memstats_alloc_bytes_total", "Total number of heap bytes allocated, even if freed.", float64(ms.TotalAlloc)),
+		counter("go_gc_cycles_total", "Number of completed garbage collection cycles.", float64(ms.NumGC)),
+		gcPauses(),
+	}
+	if f, ok := schedLatencies(); ok {
+		families = append(families, f)
+	}
+
+	return families
+}
+
+// gcPauses returns a summary of the garbage collection pause durations.
+func gcPauses() Family {
+	stats := debug.GCStats{PauseQuantiles: make([]time.Duration, len(gcQuantiles))}
+	debug.ReadGCStats(&stats)
+
+	f := Family{
+		Name: "go_gc_duration_seconds",
+		Help: "Summary of the pause duration of garbage collection cycles.",
+		Type: Summary,
+	}
+	for i, q := range gcQuantiles {
+		f.Samples = append(f.Samples, Sample{
+			Labels: []Label{{"quantile", strconv.FormatFloat(q, 'g', -1, 64)}},
+			Value:  stats.PauseQuantiles[i].Seconds(),
+		})
+	}
+	f.Samples = append(f.Samples,
+		Sample{Suffix: "_sum", Value: stats.PauseTotal.Seconds()},
+		Sample{Suffix: "_count", Value: float64(stats.NumGC)},
+	)
+
+	return f
+}
+
+func gauge(name, help string, v float64, labels ...Label) Family {
+	return Family{Name: name, Help: help, Type: Gauge, Samples: []Sample{{Labels: labels, Value: v}}}
+}
+
+func counter(name, help string, v float64) Family {
+	return Family{Name: name, Help: help, Type: Counter, Samples: []Sample{{Value: v}}}
+}
@@ -0,0 +1,75 @@
+//go:build go1.16
+// +build go1.16
+
+package metrics
+
+import (
+	"math"
+	"runtime/metrics"
+	"strconv"
+)
+
+// schedLatencyMetric is the runtime metric holding the time goroutines spent
+// runnable before running.
+const schedLatencyMetric = "/sched/latencies:seconds"
+
+// schedBuckets are the upper bounds of the reported scheduler latency
+// buckets. The runtime uses much finer buckets, which are merged into these.
+var schedBuckets = []float64{1e-6, 1e-5, 1e-4, 1e-3, 1e-2, 1e-1, 1}
+
+// schedLatencies returns a histogram of scheduler latencies.
+func schedLatencies() (Family, bool) {
+	s := []metrics.Sample{{Name: schedLatencyMetric}}
+	metrics.Read(s)
+	if s[0].Value.Kind() != metrics.KindFloat64Histogram {
+		return Family{}, false
+	}
+	h := s[0].Value.Float64Histogram()
+
+	f := Family{
+		Name: "go_sched_latencies_seconds",
+		Help: "Distribution of the time goroutines spent runnable before running.",
+		Type: Histogram,
+	}
+
+	// Bucket i of the runtime histogram counts values in
+	// [Buckets[i], Buckets[i+1]), so it is added to the first reported
+	// bucket whose bound isn't below its upper end.
+	var count uint64
+	var sum float64
+	cumulative := make([]uint64, len(schedBuckets))
+	for i, n := range h.Counts {
+		if n == 0 {
+			continue
+		}
+		lo, hi := math.Max(h.Buckets[i], 0), h.Buckets[i+1]
+		count += n
+		if math.IsInf(hi, 1) {
+			sum += float64(n) * lo
+		} else {
+			sum += float64(n) * (lo + hi) / 2
+		}
+		for j, le := range schedBuckets {
+			if hi <= le {
+				cumulative[j] += n
+			}
+		}
+	}
+
+	for j, le := range schedBuckets {
+		f.Samples = append(f.Samples, Sample{
+			Suffix: "_bucket",
+			Labels: []Label{{"le", strconv.FormatFloat(le, 'g', -1, 64)}},
+			Value:  float64(cumulative[j]),
+		})
+	}
+	f.Samples = append(f.Samples,
+		Sample{Suffix: "_bucket", Labels: []Label{{"le", "+Inf"}}, Value: float64(count)},
+		// The runtime doesn't record the sum, so it is estimated from the
+		// bucket midpoints.
+		Sample{Suffix: "_sum", Value: sum},
+		Sample{Suffix: "_count", Value: float64(count)},
+	)
+
+	return f, true
+}
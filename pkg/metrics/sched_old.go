@@ -0,0 +1,10 @@
+//go:build !go1.16
+// +build !go1.16
+
+package metrics
+
+// schedLatencies reports nothing since scheduler latencies are only exposed
+// by the runtime since Go 1.16.
+func schedLatencies() (Family, bool) {
+	return Family{}, false
+}
@@ -0,0 +1,219 @@
+// Package otlp converts spans to and from the OpenTelemetry protocol (OTLP)
+// and exports them to an OTLP receiver such as the collector.
+package otlp
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/trace"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc/codes"
+)
+
+// ServiceNameKey is the resource attribute holding the service name.
+const ServiceNameKey = "service.name"
+
+// FromSpanData converts a span recorded by the SDK to OTLP.
+func FromSpanData(d *export.SpanData) *tracepb.Span {
+	s := &tracepb.Span{
+		TraceId:           d.SpanContext.TraceID[:],
+		SpanId:            d.SpanContext.SpanID[:],
+		Name:              d.Name,
+		Kind:              spanKind(d.SpanKind),
+		StartTimeUnixNano: uint64(d.StartTime.UnixNano()),
+		EndTimeUnixNano:   uint64(d.EndTime.UnixNano()),
+		Attributes:        FromAttributes(d.Attributes),
+		Status: &tracepb.Status{
+			DeprecatedCode: tracepb.Status_DeprecatedStatusCode(d.Status),
+			Code:           tracepb.Status_STATUS_CODE_UNSET,
+		},
+		DroppedAttributesCount: uint32(d.DroppedAttributeCount),
+		DroppedEventsCount:     uint32(d.DroppedMessageEventCount),
+		DroppedLinksCount:      uint32(d.DroppedLinkCount),
+	}
+	if d.ParentSpanID.IsValid() {
+		s.ParentSpanId = d.ParentSpanID[:]
+	}
+	if d.Status != codes.OK {
+		s.Status.Code = tracepb.Status_STATUS_CODE_ERROR
+	}
+	for _, ev := range d.MessageEvents {
+		s.Events = append(s.Events, &tracepb.Span_Event{
+			TimeUnixNano: uint64(ev.Time.UnixNano()),
+			Name:         ev.Name,
+			Attributes:   FromAttributes(ev.Attributes),
+		})
+	}
+	for _, l := range d.Links {
+		traceID, spanID := l.TraceID, l.SpanID
+		s.Links = append(s.Links, &tracepb.Span_Link{
+			TraceId:    traceID[:],
+			SpanId:     spanID[:],
+			Attributes: FromAttributes(l.Attributes),
+		})
+	}
+
+	return s
+}
+
+// ToSpanData converts an OTLP span to the representation used by the SDK, so
+// that it can be passed to SDK exporters.
+func ToSpanData(s *tracepb.Span) *export.SpanData {
+	d := &export.SpanData{
+		Name:                     s.Name,
+		SpanKind:                 fromSpanKind(s.Kind),
+		StartTime:                fromUnixNano(s.StartTimeUnixNano),
+		EndTime:                  fromUnixNano(s.EndTimeUnixNano),
+		Attributes:               ToAttributes(s.Attributes),
+		DroppedAttributeCount:    int(s.DroppedAttributesCount),
+		DroppedMessageEventCount: int(s.DroppedEventsCount),
+		DroppedLinkCount:         int(s.DroppedLinksCount),
+	}
+	copy(d.SpanContext.TraceID[:], s.TraceId)
+	copy(d.SpanContext.SpanID[:], s.SpanId)
+	copy(d.ParentSpanID[:], s.ParentSpanId)
+	d.SpanContext.TraceFlags = core.TraceFlagsSampled
+	if st := s.Status; st != nil {
+		d.Status = codes.Code(st.DeprecatedCode)
+		if d.Status == codes.OK && st.Code == tracepb.Status_STATUS_CODE_ERROR {
+			d.Status = codes.Unknown
+		}
+	}
+	for _, ev := range s.Events {
+		d.MessageEvents = append(d.MessageEvents, export.Event{
+			Name:       ev.Name,
+			Attributes: ToAttributes(ev.Attributes),
+			Time:       fromUnixNano(ev.TimeUnixNano),
+		})
+	}
+	for _, l := range s.Links {
+		var link trace.Link
+		copy(link.TraceID[:], l.TraceId)
+		copy(link.SpanID[:], l.SpanId)
+		link.Attributes = ToAttributes(l.Attributes)
+		d.Links = append(d.Links, link)
+	}
+
+	return d
+}
+
+// Resource returns an OTLP resource with the given attributes.
+func Resource(attrs []core.KeyValue) *resourcepb.Resource {
+	return &resourcepb.Resource{Attributes: FromAttributes(attrs)}
+}
+
+// ServiceName returns the service name of a resource or an empty string if
+// there is none.
+func ServiceName(attrs []core.KeyValue) string {
+	for _, kv := range attrs {
+		if kv.Key == ServiceNameKey {
+			return kv.Value.AsString()
+		}
+	}
+
+	return ""
+}
+
+// FromAttributes converts attributes to OTLP.
+func FromAttributes(attrs []core.KeyValue) []*commonpb.KeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	out := make([]*commonpb.KeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		v := &commonpb.AnyValue{}
+		switch kv.Value.Type() {
+		case core.BOOL:
+			v.Value = &commonpb.AnyValue_BoolValue{BoolValue: kv.Value.AsBool()}
+		case core.INT32:
+			v.Value = &commonpb.AnyValue_IntValue{IntValue: int64(kv.Value.AsInt32())}
+		case core.INT64:
+			v.Value = &commonpb.AnyValue_IntValue{IntValue: kv.Value.AsInt64()}
+		case core.UINT32:
+			v.Value = &commonpb.AnyValue_IntValue{IntValue: int64(kv.Value.AsUint32())}
+		case core.UINT64:
+			v.Value = &commonpb.AnyValue_IntValue{IntValue: int64(kv.Value.AsUint64())}
+		case core.FLOAT32:
+			v.Value = &commonpb.AnyValue_DoubleValue{DoubleValue: float64(kv.Value.AsFloat32())}
+		case core.FLOAT64:
+			v.Value = &commonpb.AnyValue_DoubleValue{DoubleValue: kv.Value.AsFloat64()}
+		default:
+			v.Value = &commonpb.AnyValue_StringValue{StringValue: kv.Value.Emit()}
+		}
+		out = append(out, &commonpb.KeyValue{Key: string(kv.Key), Value: v})
+	}
+
+	return out
+}
+
+// ToAttributes converts OTLP attributes. Arrays and key-value lists, which
+// the SDK doesn't support, are skipped.
+func ToAttributes(attrs []*commonpb.KeyValue) []core.KeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	out := make([]core.KeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		k := core.Key(kv.Key)
+		switch v := kv.Value.GetValue().(type) {
+		case *commonpb.AnyValue_StringValue:
+			out = append(out, k.String(v.StringValue))
+		case *commonpb.AnyValue_BoolValue:
+			out = append(out, k.Bool(v.BoolValue))
+		case *commonpb.AnyValue_IntValue:
+			out = append(out, k.Int64(v.IntValue))
+		case *commonpb.AnyValue_DoubleValue:
+			out = append(out, k.Float64(v.DoubleValue))
+		}
+	}
+
+	return out
+}
+
+func spanKind(k trace.SpanKind) tracepb.Span_SpanKind {
+	switch k {
+	case trace.SpanKindInternal:
+		return tracepb.Span_SPAN_KIND_INTERNAL
+	case trace.SpanKindServer:
+		return tracepb.Span_SPAN_KIND_SERVER
+	case trace.SpanKindClient:
+		return tracepb.Span_SPAN_KIND_CLIENT
+	case trace.SpanKindProducer:
+		return tracepb.Span_SPAN_KIND_PRODUCER
+	case trace.SpanKindConsumer:
+		return tracepb.Span_SPAN_KIND_CONSUMER
+	}
+
+	return tracepb.Span_SPAN_KIND_UNSPECIFIED
+}
+
+func fromSpanKind(k tracepb.Span_SpanKind) trace.SpanKind {
+	switch k {
+	case tracepb.Span_SPAN_KIND_INTERNAL:
+		return trace.SpanKindInternal
+	case tracepb.Span_SPAN_KIND_SERVER:
+		return trace.SpanKindServer
+	case tracepb.Span_SPAN_KIND_CLIENT:
+		return trace.SpanKindClient
+	case tracepb.Span_SPAN_KIND_PRODUCER:
+		return trace.SpanKindProducer
+	case tracepb.Span_SPAN_KIND_CONSUMER:
+		return trace.SpanKindConsumer
+	}
+
+	return trace.SpanKindUnspecified
+}
+
+func fromUnixNano(ns uint64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, int64(ns))
+}
@@ -0,0 +1,158 @@
+package otlp
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/api/core"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+)
+
+const (
+	// maxBatchSize is the number of spans after which a batch is sent
+	// without waiting for flushInterval.
+	maxBatchSize = 256
+	// maxQueueSize bounds the number of spans waiting to be sent. Spans are
+	// dropped once the queue is full.
+	maxQueueSize = 4096
+	// flushInterval is the maximum time spans wait before being sent.
+	flushInterval = time.Second
+	// exportTimeout bounds a single export call.
+	exportTimeout = 10 * time.Second
+)
+
+// Exporter sends spans to an OTLP receiver over gRPC. Spans are sent in
+// batches in the background. It is safe for concurrent use.
+type Exporter struct {
+	conn     *grpc.ClientConn
+	client   collectorpb.TraceServiceClient
+	resource []core.KeyValue
+
+	mu      sync.Mutex
+	pending []*tracepb.Span
+	// sendMu serializes sends, so that Flush returns only once all spans
+	// exported before it were sent.
+	sendMu sync.Mutex
+
+	wake chan struct{}
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+var _ export.SpanSyncer = (*Exporter)(nil)
+
+// NewExporter returns an exporter which sends spans to the OTLP receiver at
+// endpoint, e.g. "localhost:4317". resource describes the process which
+// records the spans. Connecting happens in the background, so an unavailable
+// receiver doesn't fail NewExporter.
+func NewExporter(endpoint string, resource []core.KeyValue) (*Exporter, error) {
+	conn, err := grpc.Dial(endpoint, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Exporter{
+		conn:     conn,
+		client:   collectorpb.NewTraceServiceClient(conn),
+		resource: resource,
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	e.wg.Add(1)
+	go e.loop()
+
+	return e, nil
+}
+
+// ExportSpan queues d to be sent.
+func (e *Exporter) ExportSpan(ctx context.Context, d *export.SpanData) {
+	e.mu.Lock()
+	if len(e.pending) >= maxQueueSize {
+		e.mu.Unlock()
+		log.Printf("OTLP export queue full, dropping span %s", d.Name)
+		return
+	}
+	e.pending = append(e.pending, FromSpanData(d))
+	full := len(e.pending) >= maxBatchSize
+	e.mu.Unlock()
+
+	if full {
+		select {
+		case e.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush sends all queued spans.
+func (e *Exporter) Flush() {
+	e.send()
+}
+
+// Close sends all queued spans and closes the connection to the receiver.
+func (e *Exporter) Close() error {
+	close(e.done)
+	e.wg.Wait()
+	e.send()
+
+	return e.conn.Close()
+}
+
+func (e *Exporter) loop() {
+	defer e.wg.Done()
+
+	t := time.NewTicker(flushInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-e.wake:
+		case <-e.done:
+			return
+		}
+		e.send()
+	}
+}
+
+// send sends the queued spans in batches of at most maxBatchSize spans.
+func (e *Exporter) send() {
+	e.sendMu.Lock()
+	defer e.sendMu.Unlock()
+
+	e.mu.Lock()
+	spans := e.pending
+	e.pending = nil
+	e.mu.Unlock()
+
+	for len(spans) > 0 {
+		n := len(spans)
+		if n > maxBatchSize {
+			n = maxBatchSize
+		}
+		batch := spans[:n]
+		spans = spans[n:]
+
+		req := &collectorpb.ExportTraceServiceRequest{
+			ResourceSpans: []*tracepb.ResourceSpans{{
+				Resource: Resource(e.resource),
+				InstrumentationLibrarySpans: []*tracepb.InstrumentationLibrarySpans{{
+					InstrumentationLibrary: &commonpb.InstrumentationLibrary{Name: "otel-demo"},
+					Spans:                  batch,
+				}},
+			}},
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+		_, err := e.client.Export(ctx, req)
+		cancel()
+		if err != nil {
+			log.Printf("Error when exporting %d spans over OTLP: %v", len(batch), err)
+		}
+	}
+}
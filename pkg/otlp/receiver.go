@@ -0,0 +1,45 @@
+package otlp
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/api/core"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+)
+
+// Receiver implements the OTLP trace service. It converts the received spans
+// and passes them to a handler.
+type Receiver struct {
+	collectorpb.UnimplementedTraceServiceServer
+	handle func(resource []core.KeyValue, spans []*export.SpanData)
+}
+
+// NewReceiver returns a receiver which calls handle with the spans of every
+// resource in a request. handle may be called concurrently.
+func NewReceiver(handle func(resource []core.KeyValue, spans []*export.SpanData)) *Receiver {
+	return &Receiver{handle: handle}
+}
+
+// Register registers the receiver with s.
+func (r *Receiver) Register(s *grpc.Server) {
+	collectorpb.RegisterTraceServiceServer(s, r)
+}
+
+// Export receives a batch of spans.
+func (r *Receiver) Export(ctx context.Context, req *collectorpb.ExportTraceServiceRequest) (*collectorpb.ExportTraceServiceResponse, error) {
+	for _, rs := range req.ResourceSpans {
+		var spans []*export.SpanData
+		for _, ils := range rs.InstrumentationLibrarySpans {
+			for _, s := range ils.Spans {
+				spans = append(spans, ToSpanData(s))
+			}
+		}
+		if len(spans) > 0 {
+			r.handle(ToAttributes(rs.GetResource().GetAttributes()), spans)
+		}
+	}
+
+	return &collectorpb.ExportTraceServiceResponse{}, nil
+}
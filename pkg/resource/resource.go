@@ -0,0 +1,178 @@
+// Package resource detects the attributes describing the process which
+// records spans, such as the service name and version, the host and the
+// container or Kubernetes pod the process runs in.
+//
+// The Jaeger exporter reports the detected attributes as process tags and the
+// OTLP exporter as the resource of the spans.
+package resource
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/key"
+)
+
+// Version is the version of the services. It can be set at build time with
+// -ldflags "-X github.com/johananl/otel-demo/pkg/resource.Version=v1.2.3" and
+// otherwise defaults to the main module version from the build info.
+var Version = ""
+
+// instanceID identifies this process. It is shared by all services running in
+// the process.
+var instanceID = newInstanceID()
+
+// cgroupPath is the file the container ID is read from.
+var cgroupPath = "/proc/self/cgroup"
+
+// containerIDPattern matches the container IDs used by Docker and containerd
+// in cgroup paths.
+var containerIDPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// Kubernetes attributes are read from environment variables which are usually
+// populated using the downward API.
+var kubernetesEnv = []struct {
+	attr string
+	env  string
+}{
+	{"k8s.namespace.name", "K8S_NAMESPACE_NAME"},
+	{"k8s.pod.name", "K8S_POD_NAME"},
+	{"k8s.pod.uid", "K8S_POD_UID"},
+	{"k8s.node.name", "K8S_NODE_NAME"},
+	{"k8s.container.name", "K8S_CONTAINER_NAME"},
+}
+
+// Detect returns the attributes of the given service running in this process.
+// Attributes listed in OTEL_RESOURCE_ATTRIBUTES as comma-separated key=value
+// pairs override detected ones.
+func Detect(service string) []core.KeyValue {
+	attrs := []core.KeyValue{
+		key.String("service.name", service),
+		key.String("service.version", version()),
+		key.String("service.instance.id", instanceID),
+		key.Int("process.pid", os.Getpid()),
+		key.String("process.runtime.name", "go"),
+		key.String("process.runtime.version", runtime.Version()),
+	}
+	if host, err := os.Hostname(); err == nil {
+		attrs = append(attrs, key.String("host.name", host))
+	}
+	if id := containerID(); id != "" {
+		attrs = append(attrs, key.String("container.id", id))
+	}
+	attrs = append(attrs, kubernetes()...)
+
+	return merge(attrs, fromEnv(os.Getenv("OTEL_RESOURCE_ATTRIBUTES")))
+}
+
+// version returns Version if set and the main module version otherwise.
+func version() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+
+	return "unknown"
+}
+
+func newInstanceID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// containerID returns the ID of the container the process runs in or an
+// empty string if it isn't running in a container. CONTAINER_ID takes
+// precedence over the ID found in the cgroup of the process.
+func containerID() string {
+	if id := os.Getenv("CONTAINER_ID"); id != "" {
+		return id
+	}
+
+	f, err := os.Open(cgroupPath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if id := containerIDPattern.FindString(s.Text()); id != "" {
+			return id
+		}
+	}
+
+	return ""
+}
+
+// kubernetes returns the attributes of the pod the process runs in. The pod
+// name falls back to the host name, which Kubernetes sets to the pod name.
+func kubernetes() []core.KeyValue {
+	var attrs []core.KeyValue
+	for _, e := range kubernetesEnv {
+		if v := os.Getenv(e.env); v != "" {
+			attrs = append(attrs, key.String(e.attr, v))
+		}
+	}
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" && os.Getenv("K8S_POD_NAME") == "" {
+		if host, err := os.Hostname(); err == nil {
+			attrs = append(attrs, key.String("k8s.pod.name", host))
+		}
+	}
+
+	return attrs
+}
+
+// fromEnv parses comma-separated key=value pairs. Malformed pairs are
+// skipped.
+func fromEnv(s string) []core.KeyValue {
+	var attrs []core.KeyValue
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		k, v := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		if k == "" {
+			continue
+		}
+		attrs = append(attrs, key.String(k, v))
+	}
+
+	return attrs
+}
+
+// merge returns attrs with the attributes of override replacing those with
+// the same key.
+func merge(attrs, override []core.KeyValue) []core.KeyValue {
+	merged := make([]core.KeyValue, 0, len(attrs)+len(override))
+	for _, kv := range attrs {
+		if !has(override, kv.Key) {
+			merged = append(merged, kv)
+		}
+	}
+
+	return append(merged, override...)
+}
+
+func has(attrs []core.KeyValue, k core.Key) bool {
+	for _, kv := range attrs {
+		if kv.Key == k {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,69 @@
+package resource
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/api/core"
+)
+
+func value(attrs []core.KeyValue, k string) (string, bool) {
+	for _, kv := range attrs {
+		if string(kv.Key) == k {
+			return kv.Value.Emit(), true
+		}
+	}
+
+	return "", false
+}
+
+func TestDetect(t *testing.T) {
+	os.Setenv("OTEL_RESOURCE_ATTRIBUTES", "deployment.environment=test, service.version=v9,malformed")
+	defer os.Unsetenv("OTEL_RESOURCE_ATTRIBUTES")
+
+	attrs := Detect("seniority")
+	for k, want := range map[string]string{
+		"service.name":           "seniority",
+		"service.version":        "v9",
+		"service.instance.id":    instanceID,
+		"process.runtime.name":   "go",
+		"deployment.environment": "test",
+	} {
+		if got, _ := value(attrs, k); got != want {
+			t.Errorf("attribute %q is %q, want %q", k, got, want)
+		}
+	}
+	for _, k := range []string{"process.pid", "process.runtime.version", "host.name"} {
+		if _, ok := value(attrs, k); !ok {
+			t.Errorf("attribute %q missing", k)
+		}
+	}
+	if _, ok := value(attrs, "malformed"); ok {
+		t.Error("malformed pair was added")
+	}
+}
+
+func TestContainerID(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resource")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	id := strings.Repeat("0123456789abcdef", 4)
+	path := filepath.Join(dir, "cgroup")
+	cgroup := "12:pids:/\n0::/kubepods/besteffort/pod1234/" + id + "\n"
+	if err := ioutil.WriteFile(path, []byte(cgroup), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(p string) { cgroupPath = p }(cgroupPath)
+	cgroupPath = path
+
+	if got := containerID(); got != id {
+		t.Errorf("got container ID %q, want %q", got, id)
+	}
+}
@@ -0,0 +1,166 @@
+// Package server implements the role service.
+package server
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/johananl/otel-demo/pkg/latency"
+	"github.com/johananl/otel-demo/pkg/logging"
+	"github.com/johananl/otel-demo/pkg/words"
+	pb "github.com/johananl/otel-demo/proto/role"
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxBatchSize is the maximum number of words which can be requested at once.
+const maxBatchSize = 100
+
+const (
+	// defaultStreamInterval is the delay between two streamed words if the
+	// client doesn't specify one.
+	defaultStreamInterval = time.Second
+	// minStreamInterval is the shortest allowed delay between two streamed
+	// words.
+	minStreamInterval = 10 * time.Millisecond
+)
+
+// Server implements the role service.
+type Server struct {
+	pb.UnimplementedRoleServer
+	// latency is the profile applied to slow requests. It can be changed at
+	// runtime through the admin API.
+	latency *latency.Value
+}
+
+// New returns a role server which applies the latency profile held by lat to
+// slow requests.
+func New(lat *latency.Value) *Server {
+	return &Server{latency: lat}
+}
+
+func (s *Server) GetRole(ctx context.Context, in *pb.RoleRequest) (*pb.RoleReply, error) {
+	logging.Infof("Received role request")
+
+	profile, err := latency.ForRequest(in.Slow, in.Latency, s.latency.Load())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := latency.Inject(ctx, profile); err != nil {
+		return nil, status.Errorf(status.FromContextError(err).Code(), "injecting delay: %v", err)
+	}
+
+	// A seeded request always yields the same word for the same locale and tags.
+	var rnd *rand.Rand
+	if in.Seed != 0 {
+		rnd = rand.New(rand.NewSource(in.Seed))
+	}
+
+	locale, list := roles.ForLocale(in.Locale)
+	selected, err := words.PickRand(rnd, list, in.Tags)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "selecting role: %v", err)
+	}
+
+	// Get current span. The span was created within the gRPC interceptor.
+	// We are just adding data to it here.
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		key.New("role.weight").Int(selected.Weight),
+		key.New("role.tags").String(strings.Join(selected.Tags, ",")),
+		key.New("seed").Int64(in.Seed),
+	)
+	span.AddEvent(ctx, "Selected role",
+		key.New("role").String(selected.Text),
+		key.New("locale").String(locale),
+	)
+
+	logging.Debugf("Selected role %q for locale %s", selected.Text, locale)
+
+	return &pb.RoleReply{Role: selected.Text}, nil
+}
+
+func (s *Server) GetRoles(ctx context.Context, in *pb.RolesRequest) (*pb.RolesReply, error) {
+	logging.Infof("Received roles request for %d roles", in.Count)
+
+	if in.Count < 1 || in.Count > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "count must be between 1 and %d", maxBatchSize)
+	}
+
+	profile, err := latency.ForRequest(in.Slow, in.Latency, s.latency.Load())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := latency.Inject(ctx, profile); err != nil {
+		return nil, status.Errorf(status.FromContextError(err).Code(), "injecting delay: %v", err)
+	}
+
+	locale, list := roles.ForLocale(in.Locale)
+	selected := make([]string, 0, in.Count)
+	for i := int32(0); i < in.Count; i++ {
+		w, err := words.Pick(list, in.Tags)
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound, "selecting role: %v", err)
+		}
+		selected = append(selected, w.Text)
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(key.New("batch.size").Int(int(in.Count)))
+	span.AddEvent(ctx, "Selected roles",
+		key.New("roles").String(strings.Join(selected, ",")),
+		key.New("locale").String(locale),
+	)
+
+	return &pb.RolesReply{Roles: selected}, nil
+}
+
+func (s *Server) StreamRoles(in *pb.RoleStreamRequest, stream pb.Role_StreamRolesServer) error {
+	logging.Infof("Received role stream request")
+
+	interval := time.Duration(in.IntervalMs) * time.Millisecond
+	if interval == 0 {
+		interval = defaultStreamInterval
+	}
+	if interval < minStreamInterval {
+		return status.Errorf(codes.InvalidArgument, "interval must be at least %v", minStreamInterval)
+	}
+
+	ctx := stream.Context()
+	locale, list := roles.ForLocale(in.Locale)
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		key.New("stream.interval_ms").Int64(interval.Nanoseconds()/1e6),
+		key.New("locale").String(locale),
+	)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for sent := int32(0); in.Count == 0 || sent < in.Count; sent++ {
+		if sent > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+
+		w, err := words.Pick(list, in.Tags)
+		if err != nil {
+			return status.Errorf(codes.NotFound, "selecting role: %v", err)
+		}
+		if err := stream.Send(&pb.RoleReply{Role: w.Text}); err != nil {
+			return err
+		}
+
+		span.AddEvent(ctx, "Sent role", key.New("role").String(w.Text))
+	}
+
+	return nil
+}
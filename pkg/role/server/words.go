@@ -0,0 +1,68 @@
+package server
+
+import "github.com/johananl/otel-demo/pkg/words"
+
+// roles holds the available roles per locale. Weights are relative within
+// a locale and tags allow clients to narrow down the selection.
+var roles = words.List{
+	"en": {
+		{Text: "coordinator", Weight: 4, Tags: []string{"serious"}},
+		{Text: "manager", Weight: 5, Tags: []string{"serious"}},
+		{Text: "trainer", Weight: 2, Tags: []string{"serious"}},
+		{Text: "dictator", Weight: 1, Tags: []string{"silly"}},
+		{Text: "tamer", Weight: 1, Tags: []string{"silly"}},
+		{Text: "analyst", Weight: 3, Tags: []string{"serious", "tech"}},
+		{Text: "engineer", Weight: 4, Tags: []string{"serious", "tech"}},
+		{Text: "evangelist", Weight: 2, Tags: []string{"tech"}},
+		{Text: "designer", Weight: 3, Tags: []string{"serious", "tech"}},
+		{Text: "plumber", Weight: 1, Tags: []string{"silly"}},
+		{Text: "consultant", Weight: 3, Tags: []string{"serious"}},
+		{Text: "optimizer", Weight: 2, Tags: []string{"tech"}},
+		{Text: "specialist", Weight: 3, Tags: []string{"serious"}},
+	},
+	"de": {
+		{Text: "koordinator", Weight: 4, Tags: []string{"serious"}},
+		{Text: "manager", Weight: 5, Tags: []string{"serious"}},
+		{Text: "trainer", Weight: 2, Tags: []string{"serious"}},
+		{Text: "diktator", Weight: 1, Tags: []string{"silly"}},
+		{Text: "dompteur", Weight: 1, Tags: []string{"silly"}},
+		{Text: "analyst", Weight: 3, Tags: []string{"serious", "tech"}},
+		{Text: "ingenieur", Weight: 4, Tags: []string{"serious", "tech"}},
+		{Text: "evangelist", Weight: 2, Tags: []string{"tech"}},
+		{Text: "designer", Weight: 3, Tags: []string{"serious", "tech"}},
+		{Text: "klempner", Weight: 1, Tags: []string{"silly"}},
+		{Text: "berater", Weight: 3, Tags: []string{"serious"}},
+		{Text: "optimierer", Weight: 2, Tags: []string{"tech"}},
+		{Text: "spezialist", Weight: 3, Tags: []string{"serious"}},
+	},
+	"es": {
+		{Text: "coordinador", Weight: 4, Tags: []string{"serious"}},
+		{Text: "gerente", Weight: 5, Tags: []string{"serious"}},
+		{Text: "entrenador", Weight: 2, Tags: []string{"serious"}},
+		{Text: "dictador", Weight: 1, Tags: []string{"silly"}},
+		{Text: "domador", Weight: 1, Tags: []string{"silly"}},
+		{Text: "analista", Weight: 3, Tags: []string{"serious", "tech"}},
+		{Text: "ingeniero", Weight: 4, Tags: []string{"serious", "tech"}},
+		{Text: "evangelista", Weight: 2, Tags: []string{"tech"}},
+		{Text: "diseñador", Weight: 3, Tags: []string{"serious", "tech"}},
+		{Text: "fontanero", Weight: 1, Tags: []string{"silly"}},
+		{Text: "consultor", Weight: 3, Tags: []string{"serious"}},
+		{Text: "optimizador", Weight: 2, Tags: []string{"tech"}},
+		{Text: "especialista", Weight: 3, Tags: []string{"serious"}},
+	},
+	"fr": {
+		{Text: "coordinateur", Weight: 4, Tags: []string{"serious"}},
+		{Text: "manager", Weight: 5, Tags: []string{"serious"}},
+		{Text: "formateur", Weight: 2, Tags: []string{"serious"}},
+		{Text: "dictateur", Weight: 1, Tags: []string{"silly"}},
+		{Text: "dompteur", Weight: 1, Tags: []string{"silly"}},
+		{Text: "analyste", Weight: 3, Tags: []string{"serious", "tech"}},
+		{Text: "ingénieur", Weight: 4, Tags: []string{"serious", "tech"}},
+		{Text: "évangéliste", Weight: 2, Tags: []string{"tech"}},
+		{Text: "designer", Weight: 3, Tags: []string{"serious", "tech"}},
+		{Text: "plombier", Weight: 1, Tags: []string{"silly"}},
+		{Text: "consultant", Weight: 3, Tags: []string{"serious"}},
+		{Text: "optimiseur", Weight: 2, Tags: []string{"tech"}},
+		{Text: "spécialiste", Weight: 3, Tags: []string{"serious"}},
+	},
+}
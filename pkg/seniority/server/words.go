@@ -0,0 +1,56 @@
+package server
+
+import "github.com/johananl/otel-demo/pkg/words"
+
+// seniorities holds the available seniorities per locale. Weights are relative within
+// a locale and tags allow clients to narrow down the selection.
+var seniorities = words.List{
+	"en": {
+		{Text: "senior", Weight: 5, Tags: []string{"serious"}},
+		{Text: "junior", Weight: 5, Tags: []string{"serious"}},
+		{Text: "assistant", Weight: 4, Tags: []string{"serious"}},
+		{Text: "executive", Weight: 3, Tags: []string{"serious"}},
+		{Text: "intergalactic", Weight: 1, Tags: []string{"silly"}},
+		{Text: "lead", Weight: 4, Tags: []string{"serious"}},
+		{Text: "corporate", Weight: 3, Tags: []string{"serious"}},
+		{Text: "regional", Weight: 3, Tags: []string{"serious"}},
+		{Text: "principal", Weight: 2, Tags: []string{"serious", "tech"}},
+		{Text: "chief", Weight: 2, Tags: []string{"serious"}},
+	},
+	"de": {
+		{Text: "erfahrener", Weight: 5, Tags: []string{"serious"}},
+		{Text: "junger", Weight: 5, Tags: []string{"serious"}},
+		{Text: "stellvertretender", Weight: 4, Tags: []string{"serious"}},
+		{Text: "geschäftsführender", Weight: 3, Tags: []string{"serious"}},
+		{Text: "intergalaktischer", Weight: 1, Tags: []string{"silly"}},
+		{Text: "leitender", Weight: 4, Tags: []string{"serious"}},
+		{Text: "konzernweiter", Weight: 3, Tags: []string{"serious"}},
+		{Text: "regionaler", Weight: 3, Tags: []string{"serious"}},
+		{Text: "hauptverantwortlicher", Weight: 2, Tags: []string{"serious", "tech"}},
+		{Text: "oberster", Weight: 2, Tags: []string{"serious"}},
+	},
+	"es": {
+		{Text: "sénior", Weight: 5, Tags: []string{"serious"}},
+		{Text: "júnior", Weight: 5, Tags: []string{"serious"}},
+		{Text: "adjunto", Weight: 4, Tags: []string{"serious"}},
+		{Text: "ejecutivo", Weight: 3, Tags: []string{"serious"}},
+		{Text: "intergaláctico", Weight: 1, Tags: []string{"silly"}},
+		{Text: "líder", Weight: 4, Tags: []string{"serious"}},
+		{Text: "corporativo", Weight: 3, Tags: []string{"serious"}},
+		{Text: "regional", Weight: 3, Tags: []string{"serious"}},
+		{Text: "principal", Weight: 2, Tags: []string{"serious", "tech"}},
+		{Text: "en jefe", Weight: 2, Tags: []string{"serious"}},
+	},
+	"fr": {
+		{Text: "senior", Weight: 5, Tags: []string{"serious"}},
+		{Text: "junior", Weight: 5, Tags: []string{"serious"}},
+		{Text: "adjoint", Weight: 4, Tags: []string{"serious"}},
+		{Text: "exécutif", Weight: 3, Tags: []string{"serious"}},
+		{Text: "intergalactique", Weight: 1, Tags: []string{"silly"}},
+		{Text: "référent", Weight: 4, Tags: []string{"serious"}},
+		{Text: "d'entreprise", Weight: 3, Tags: []string{"serious"}},
+		{Text: "régional", Weight: 3, Tags: []string{"serious"}},
+		{Text: "principal", Weight: 2, Tags: []string{"serious", "tech"}},
+		{Text: "en chef", Weight: 2, Tags: []string{"serious"}},
+	},
+}
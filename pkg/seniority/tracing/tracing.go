@@ -2,9 +2,14 @@ package tracing
 
 import (
 	"context"
+	"net/textproto"
+	"strings"
 
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/johananl/otel-demo/pkg/spanlink"
 	"go.opentelemetry.io/otel/api/distributedcontext"
 	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/propagators"
 	"go.opentelemetry.io/otel/api/trace"
 	"go.opentelemetry.io/otel/plugin/grpctrace"
 	"google.golang.org/grpc"
@@ -16,24 +21,98 @@ import (
 var tr = global.TraceProvider().Tracer("seniority")
 
 // UnaryServerInterceptor intercepts and extracts incoming trace data.
-func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
-	requestMetadata, _ := metadata.FromIncomingContext(ctx)
-	metadataCopy := requestMetadata.Copy()
-
-	entries, spanCtx := grpctrace.Extract(ctx, &metadataCopy)
-	ctx = distributedcontext.WithMap(ctx, distributedcontext.NewMap(distributedcontext.MapUpdate{
-		MultiKV: entries,
-	}))
-
-	ctx, span := tr.Start(
-		ctx,
-		"handle-grpc-request",
-		trace.ChildOf(spanCtx),
-		trace.WithSpanKind(trace.SpanKindServer),
-	)
-	defer span.End()
-
-	return handler(ctx, req)
+func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return NewUnaryServerInterceptor(tr)(ctx, req, info, handler)
+}
+
+// NewUnaryServerInterceptor returns a UnaryServerInterceptor which records
+// spans using the given tracer rather than the tracer of the global provider.
+func NewUnaryServerInterceptor(tr trace.Tracer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		requestMetadata, _ := metadata.FromIncomingContext(ctx)
+		metadataCopy := requestMetadata.Copy()
+
+		entries, spanCtx := grpctrace.Extract(ctx, &metadataCopy)
+		ctx = distributedcontext.WithMap(ctx, distributedcontext.NewMap(distributedcontext.MapUpdate{
+			MultiKV: entries,
+		}))
+
+		// Spans are linked to the spans listed by the caller, e.g. the
+		// requests a batch call was made for.
+		ctx, span := tr.Start(
+			ctx,
+			"handle-grpc-request",
+			append([]trace.StartOption{
+				trace.ChildOf(spanCtx),
+				trace.WithSpanKind(trace.SpanKindServer),
+			}, spanlink.Extract(metadataCopy)...)...,
+		)
+		defer span.End()
+
+		resp, err = handler(ctx, req)
+		setTraceStatus(ctx, err)
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor intercepts and extracts incoming trace data for
+// streaming RPCs. The span covers the whole lifetime of the stream.
+func StreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return NewStreamServerInterceptor(tr)(srv, ss, info, handler)
+}
+
+// NewStreamServerInterceptor returns a StreamServerInterceptor which records
+// spans using the given tracer rather than the tracer of the global provider.
+func NewStreamServerInterceptor(tr trace.Tracer) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		requestMetadata, _ := metadata.FromIncomingContext(ctx)
+		metadataCopy := requestMetadata.Copy()
+
+		entries, spanCtx := grpctrace.Extract(ctx, &metadataCopy)
+		ctx = distributedcontext.WithMap(ctx, distributedcontext.NewMap(distributedcontext.MapUpdate{
+			MultiKV: entries,
+		}))
+
+		ctx, span := tr.Start(
+			ctx,
+			"handle-grpc-stream",
+			append([]trace.StartOption{
+				trace.ChildOf(spanCtx),
+				trace.WithSpanKind(trace.SpanKindServer),
+			}, spanlink.Extract(metadataCopy)...)...,
+		)
+		defer span.End()
+
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		setTraceStatus(ctx, err)
+
+		return err
+	}
+}
+
+// serverStream overrides the context of a grpc.ServerStream so that handlers
+// see the span created by StreamServerInterceptor.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// GatewayHeaderMatcher forwards the trace context headers of REST requests
+// as gRPC metadata, so that the server interceptors continue the trace of the
+// HTTP client. All other headers are handled by the default matcher.
+func GatewayHeaderMatcher(key string) (string, bool) {
+	switch textproto.CanonicalMIMEHeaderKey(key) {
+	case propagators.TraceparentHeader, propagators.CorrelationContextHeader:
+		return strings.ToLower(key), true
+	}
+
+	return runtime.DefaultHeaderMatcher(key)
 }
 
 func setTraceStatus(ctx context.Context, err error) {
@@ -0,0 +1,72 @@
+// Package spanlink propagates span links along with requests, so that spans
+// can be related to spans other than their parent, e.g. a backend call made
+// for a batch to every request in the batch.
+//
+// Links are attached to a context with NewContext. The client interceptors of
+// the frontend inject them into the gRPC metadata of outgoing calls and the
+// server interceptors of the backends add them to their spans.
+package spanlink
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// metadataKey is the gRPC metadata key holding the links of a call.
+const metadataKey = "span-links"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx which carries links to the given spans.
+func NewContext(ctx context.Context, links ...core.SpanContext) context.Context {
+	return context.WithValue(ctx, contextKey{}, links)
+}
+
+// FromContext returns the links carried by ctx.
+func FromContext(ctx context.Context) []core.SpanContext {
+	links, _ := ctx.Value(contextKey{}).([]core.SpanContext)
+	return links
+}
+
+// Inject adds the links carried by ctx to md.
+func Inject(ctx context.Context, md *metadata.MD) {
+	links := FromContext(ctx)
+	if len(links) == 0 {
+		return
+	}
+
+	encoded := make([]string, len(links))
+	for i, l := range links {
+		encoded[i] = l.TraceIDString() + "-" + l.SpanIDString()
+	}
+	md.Set(metadataKey, strings.Join(encoded, ","))
+}
+
+// Extract returns start options which link a span to the spans listed in md.
+// Malformed links are skipped.
+func Extract(md metadata.MD) []trace.StartOption {
+	var opts []trace.StartOption
+	for _, v := range md.Get(metadataKey) {
+		for _, l := range strings.Split(v, ",") {
+			parts := strings.Split(l, "-")
+			if len(parts) != 2 {
+				continue
+			}
+			traceID, err := core.TraceIDFromHex(parts[0])
+			if err != nil {
+				continue
+			}
+			spanID, err := core.SpanIDFromHex(parts[1])
+			if err != nil {
+				continue
+			}
+			opts = append(opts, trace.LinkedTo(core.SpanContext{TraceID: traceID, SpanID: spanID}))
+		}
+	}
+
+	return opts
+}
@@ -0,0 +1,185 @@
+// Package stack runs the frontend and the seniority, field and role services
+// in a single process.
+//
+// Every service records spans with a trace provider of its own, so traces
+// look the same as when the services run as separate processes. The services
+// talk to each other over gRPC, either through loopback listeners or through
+// in-memory connections.
+package stack
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/johananl/otel-demo/pkg/fault"
+	fieldserver "github.com/johananl/otel-demo/pkg/field/server"
+	fieldtracing "github.com/johananl/otel-demo/pkg/field/tracing"
+	"github.com/johananl/otel-demo/pkg/frontend/server"
+	"github.com/johananl/otel-demo/pkg/frontend/tracing"
+	"github.com/johananl/otel-demo/pkg/latency"
+	"github.com/johananl/otel-demo/pkg/metrics"
+	roleserver "github.com/johananl/otel-demo/pkg/role/server"
+	roletracing "github.com/johananl/otel-demo/pkg/role/tracing"
+	seniorityserver "github.com/johananl/otel-demo/pkg/seniority/server"
+	senioritytracing "github.com/johananl/otel-demo/pkg/seniority/tracing"
+	fieldpb "github.com/johananl/otel-demo/proto/field"
+	rolepb "github.com/johananl/otel-demo/proto/role"
+	senioritypb "github.com/johananl/otel-demo/proto/seniority"
+	"go.opentelemetry.io/otel/api/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// bufSize is the buffer size of in-memory connections.
+const bufSize = 1 << 20
+
+// Services are the names of the services in the order they are started.
+var Services = []string{"seniority", "field", "role", "frontend"}
+
+// Config configures a Stack.
+type Config struct {
+	// InMemory connects the services in memory instead of over loopback.
+	InMemory bool
+	// Latency is the profile the backends apply to slow requests. A nil
+	// profile doesn't delay requests at all.
+	Latency latency.Profile
+	// Provider returns the trace provider of a service.
+	Provider func(service string) trace.Provider
+	// BackendTimeout bounds the backend calls of a frontend request. Zero
+	// means server.DefaultBackendTimeout.
+	BackendTimeout time.Duration
+	// BatchWindow and Prefetch enable batching and prefetching of titles by
+	// the frontend, see server.Server.SetBatchWindow and SetPrefetch.
+	BatchWindow time.Duration
+	Prefetch    int
+}
+
+// Stack is a running set of services.
+type Stack struct {
+	// Frontend is the frontend server.
+	Frontend *server.Server
+	// FrontendTracer is the tracer used by the frontend.
+	FrontendTracer trace.Tracer
+	// Faults holds the fault injectors of the backends by service name.
+	// They start without any rules.
+	Faults map[string]*fault.Injector
+
+	servers []*grpc.Server
+	conns   []*grpc.ClientConn
+}
+
+// Start starts the backends and creates a frontend connected to them. The
+// frontend isn't served; use the handlers of Stack.Frontend to do so.
+func Start(cfg Config) (*Stack, error) {
+	s := &Stack{Faults: map[string]*fault.Injector{}}
+
+	backends := []struct {
+		name     string
+		unary    func(trace.Tracer) grpc.UnaryServerInterceptor
+		stream   func(trace.Tracer) grpc.StreamServerInterceptor
+		register func(*grpc.Server)
+	}{
+		{
+			"seniority", senioritytracing.NewUnaryServerInterceptor, senioritytracing.NewStreamServerInterceptor,
+			func(g *grpc.Server) {
+				senioritypb.RegisterSeniorityServer(g, seniorityserver.New(latency.NewValue(cfg.Latency)))
+			},
+		},
+		{
+			"field", fieldtracing.NewUnaryServerInterceptor, fieldtracing.NewStreamServerInterceptor,
+			func(g *grpc.Server) {
+				fieldpb.RegisterFieldServer(g, fieldserver.New(latency.NewValue(cfg.Latency)))
+			},
+		},
+		{
+			"role", roletracing.NewUnaryServerInterceptor, roletracing.NewStreamServerInterceptor,
+			func(g *grpc.Server) {
+				rolepb.RegisterRoleServer(g, roleserver.New(latency.NewValue(cfg.Latency)))
+			},
+		},
+	}
+
+	for _, b := range backends {
+		tr := cfg.Provider(b.name).Tracer(b.name)
+		inj := fault.NewInjector(nil)
+		s.Faults[b.name] = inj
+		g := grpc.NewServer(
+			grpc.ChainUnaryInterceptor(
+				b.unary(tr), metrics.UnaryServerInterceptor, inj.UnaryServerInterceptor,
+			),
+			grpc.ChainStreamInterceptor(
+				b.stream(tr), metrics.StreamServerInterceptor, inj.StreamServerInterceptor,
+			),
+		)
+		b.register(g)
+
+		conn, err := s.serve(b.name, g, inj, cfg.InMemory)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.conns = append(s.conns, conn)
+	}
+
+	s.FrontendTracer = cfg.Provider("frontend").Tracer("frontend")
+	s.Frontend = server.New(
+		s.FrontendTracer,
+		senioritypb.NewSeniorityClient(s.conns[0]),
+		fieldpb.NewFieldClient(s.conns[1]),
+		rolepb.NewRoleClient(s.conns[2]),
+	)
+	if cfg.BackendTimeout > 0 {
+		s.Frontend.SetBackendTimeout(cfg.BackendTimeout)
+	}
+	s.Frontend.SetBatchWindow(cfg.BatchWindow)
+	s.Frontend.SetPrefetch(cfg.Prefetch)
+
+	return s, nil
+}
+
+// serve serves g and returns a client connection to it.
+func (s *Stack) serve(name string, g *grpc.Server, inj *fault.Injector, inMemory bool) (*grpc.ClientConn, error) {
+	var lis net.Listener
+	var dialOpts []grpc.DialOption
+	if inMemory {
+		bl := bufconn.Listen(bufSize)
+		lis = bl
+		dialOpts = append(dialOpts, grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return bl.Dial()
+		}))
+	} else {
+		var err error
+		if lis, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+			return nil, fmt.Errorf("listening for %s service: %v", name, err)
+		}
+	}
+
+	s.servers = append(s.servers, g)
+	go g.Serve(inj.Listener(lis))
+
+	conn, err := grpc.Dial(
+		lis.Addr().String(),
+		append(dialOpts,
+			grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(time.Second),
+			grpc.WithUnaryInterceptor(tracing.UnaryClientInterceptor),
+			grpc.WithStreamInterceptor(tracing.StreamClientInterceptor),
+		)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s service: %v", name, err)
+	}
+
+	return conn, nil
+}
+
+// Close closes the connections to the backends and stops them.
+func (s *Stack) Close() {
+	for _, c := range s.conns {
+		c.Close()
+	}
+	for _, g := range s.servers {
+		g.Stop()
+	}
+}
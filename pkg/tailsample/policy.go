@@ -0,0 +1,87 @@
+package tailsample
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/api/core"
+	"google.golang.org/grpc/codes"
+)
+
+// Policy decides whether a complete trace is worth keeping.
+type Policy interface {
+	// Name identifies the policy in stats, e.g. "error".
+	Name() string
+	// Keep reports whether the trace should be kept.
+	Keep(t *Trace) bool
+}
+
+// ErrorPolicy keeps traces with at least one failed span.
+type ErrorPolicy struct{}
+
+// Name returns "error".
+func (ErrorPolicy) Name() string { return "error" }
+
+// Keep reports whether a span of t has a status other than OK.
+func (ErrorPolicy) Keep(t *Trace) bool {
+	for _, s := range t.Spans {
+		if s.Data.Status != codes.OK {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LatencyPolicy keeps traces lasting at least Threshold.
+type LatencyPolicy struct {
+	Threshold time.Duration
+}
+
+// Name returns "latency".
+func (LatencyPolicy) Name() string { return "latency" }
+
+// Keep reports whether t lasted at least p.Threshold.
+func (p LatencyPolicy) Keep(t *Trace) bool {
+	return t.Duration() >= p.Threshold
+}
+
+// AttributePolicy keeps traces with a span or resource attribute with the
+// given key and value. An empty value matches any value.
+type AttributePolicy struct {
+	Key   string
+	Value string
+}
+
+// ParseAttributePolicy parses a rule of the form "key=value" or "key".
+func ParseAttributePolicy(rule string) (AttributePolicy, error) {
+	kv := strings.SplitN(rule, "=", 2)
+	p := AttributePolicy{Key: strings.TrimSpace(kv[0])}
+	if p.Key == "" {
+		return AttributePolicy{}, fmt.Errorf("attribute rule %q has no key", rule)
+	}
+	if len(kv) == 2 {
+		p.Value = strings.TrimSpace(kv[1])
+	}
+
+	return p, nil
+}
+
+// Name returns "attribute:<key>".
+func (p AttributePolicy) Name() string { return "attribute:" + p.Key }
+
+// Keep reports whether a span of t has a matching attribute.
+func (p AttributePolicy) Keep(t *Trace) bool {
+	for _, s := range t.Spans {
+		for _, attrs := range [][]core.KeyValue{s.Data.Attributes, s.Resource} {
+			for _, kv := range attrs {
+				if string(kv.Key) == p.Key && (p.Value == "" || kv.Value.Emit() == p.Value) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,242 @@
+// Package tailsample decides which traces to keep once they are complete,
+// rather than when they start.
+//
+// Head sampling decides on the first span of a trace, before it is known
+// whether the trace will fail or be slow. A Sampler buffers the spans of every
+// trace for a decision window instead and then keeps the trace only if one of
+// its policies matches, e.g. because a span failed.
+package tailsample
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/api/core"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// Span is a span and the resource attributes of the process which recorded
+// it.
+type Span struct {
+	Resource []core.KeyValue
+	Data     *export.SpanData
+}
+
+// Trace is the buffered spans of a trace.
+type Trace struct {
+	ID        core.TraceID
+	Spans     []Span
+	FirstSeen time.Time
+}
+
+// Duration returns the time between the earliest start and the latest end of
+// the spans of the trace.
+func (t *Trace) Duration() time.Duration {
+	var start, end time.Time
+	for i, s := range t.Spans {
+		if i == 0 || s.Data.StartTime.Before(start) {
+			start = s.Data.StartTime
+		}
+		if i == 0 || s.Data.EndTime.After(end) {
+			end = s.Data.EndTime
+		}
+	}
+
+	return end.Sub(start)
+}
+
+// Stats counts what the sampler did.
+type Stats struct {
+	// Spans is the number of received spans.
+	Spans int
+	// Pending is the number of traces waiting for a decision.
+	Pending int
+	// Kept maps policy names to the number of traces kept because of them.
+	// A trace is counted for the first matching policy only.
+	Kept map[string]int
+	// Dropped is the number of traces no policy matched.
+	Dropped int
+	// LateSpans is the number of spans received after the decision on their
+	// trace. They follow the decision.
+	LateSpans int
+}
+
+// KeptTotal returns the number of kept traces.
+func (s Stats) KeptTotal() int {
+	n := 0
+	for _, k := range s.Kept {
+		n += k
+	}
+
+	return n
+}
+
+// Config configures a Sampler.
+type Config struct {
+	// DecisionWait is how long the spans of a trace are buffered after its
+	// first span was received.
+	DecisionWait time.Duration
+	// Policies are evaluated in order. A trace is kept if any matches.
+	Policies []Policy
+}
+
+// Sampler buffers traces and forwards the ones to keep. It is safe for
+// concurrent use.
+type Sampler struct {
+	cfg     Config
+	forward func([]Span)
+	// fwdMu serializes calls to forward.
+	fwdMu sync.Mutex
+
+	mu      sync.Mutex
+	traces  map[core.TraceID]*Trace
+	decided map[core.TraceID]decision
+	stats   Stats
+}
+
+// decision is the outcome for a trace, remembered to handle late spans.
+type decision struct {
+	keep bool
+	at   time.Time
+}
+
+// New returns a sampler which passes the spans of kept traces to forward.
+// forward is never called concurrently.
+func New(cfg Config, forward func([]Span)) *Sampler {
+	return &Sampler{
+		cfg:     cfg,
+		forward: forward,
+		traces:  map[core.TraceID]*Trace{},
+		decided: map[core.TraceID]decision{},
+		stats:   Stats{Kept: map[string]int{}},
+	}
+}
+
+// Add buffers spans. Spans of traces which were already decided are
+// forwarded or dropped right away.
+func (s *Sampler) Add(spans []Span) {
+	s.AddAt(spans, time.Now())
+}
+
+// AddAt is like Add with the current time given explicitly.
+func (s *Sampler) AddAt(spans []Span, now time.Time) {
+	var late []Span
+
+	s.mu.Lock()
+	for _, sp := range spans {
+		s.stats.Spans++
+		id := sp.Data.SpanContext.TraceID
+		if d, ok := s.decided[id]; ok {
+			s.stats.LateSpans++
+			if d.keep {
+				late = append(late, sp)
+			}
+			continue
+		}
+
+		t, ok := s.traces[id]
+		if !ok {
+			t = &Trace{ID: id, FirstSeen: now}
+			s.traces[id] = t
+		}
+		t.Spans = append(t.Spans, sp)
+	}
+	s.mu.Unlock()
+
+	if len(late) > 0 {
+		s.send(late)
+	}
+}
+
+// send passes spans to forward.
+func (s *Sampler) send(spans []Span) {
+	s.fwdMu.Lock()
+	defer s.fwdMu.Unlock()
+
+	s.forward(spans)
+}
+
+// Tick decides on all traces whose decision window has passed at now and
+// forgets decisions older than twice the window.
+func (s *Sampler) Tick(now time.Time) {
+	var due []*Trace
+
+	s.mu.Lock()
+	for id, t := range s.traces {
+		if now.Sub(t.FirstSeen) >= s.cfg.DecisionWait {
+			due = append(due, t)
+			delete(s.traces, id)
+		}
+	}
+	for id, d := range s.decided {
+		if now.Sub(d.at) >= 2*s.cfg.DecisionWait {
+			delete(s.decided, id)
+		}
+	}
+
+	// Decisions are recorded before the lock is released, so that spans
+	// arriving meanwhile are handled as late spans.
+	var kept []*Trace
+	for _, t := range due {
+		policy, keep := s.decide(t)
+		s.decided[t.ID] = decision{keep: keep, at: now}
+		if keep {
+			s.stats.Kept[policy]++
+			kept = append(kept, t)
+		} else {
+			s.stats.Dropped++
+		}
+	}
+	s.mu.Unlock()
+
+	// Kept traces are forwarded in arrival order.
+	sort.Slice(kept, func(i, j int) bool { return kept[i].FirstSeen.Before(kept[j].FirstSeen) })
+	for _, t := range kept {
+		s.send(t.Spans)
+	}
+}
+
+// decide returns the name of the first policy keeping t.
+func (s *Sampler) decide(t *Trace) (string, bool) {
+	for _, p := range s.cfg.Policies {
+		if p.Keep(t) {
+			return p.Name(), true
+		}
+	}
+
+	return "", false
+}
+
+// Run calls Tick periodically until ctx is done. Pending traces are decided
+// when Run returns.
+func (s *Sampler) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case now := <-t.C:
+			s.Tick(now)
+		case <-ctx.Done():
+			s.Tick(time.Now().Add(s.cfg.DecisionWait))
+			return
+		}
+	}
+}
+
+// Stats returns a snapshot of the counters of the sampler.
+func (s *Sampler) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.stats
+	st.Pending = len(s.traces)
+	st.Kept = make(map[string]int, len(s.stats.Kept))
+	for k, v := range s.stats.Kept {
+		st.Kept[k] = v
+	}
+
+	return st
+}
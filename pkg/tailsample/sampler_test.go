@@ -0,0 +1,96 @@
+package tailsample
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/key"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	"google.golang.org/grpc/codes"
+)
+
+// recorder collects forwarded spans by trace ID.
+type recorder struct {
+	mu    sync.Mutex
+	spans map[core.TraceID]int
+}
+
+func (r *recorder) forward(spans []Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range spans {
+		r.spans[s.Data.SpanContext.TraceID]++
+	}
+}
+
+func span(trace byte, start time.Time, d time.Duration, status codes.Code, attrs ...core.KeyValue) Span {
+	var id core.TraceID
+	id[0] = trace
+
+	return Span{
+		Resource: []core.KeyValue{key.String("service.name", "frontend")},
+		Data: &export.SpanData{
+			SpanContext: core.SpanContext{TraceID: id},
+			StartTime:   start,
+			EndTime:     start.Add(d),
+			Status:      status,
+			Attributes:  attrs,
+		},
+	}
+}
+
+func TestSampler(t *testing.T) {
+	attr, err := ParseAttributePolicy("fault=hang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &recorder{spans: map[core.TraceID]int{}}
+	s := New(Config{
+		DecisionWait: time.Second,
+		Policies:     []Policy{ErrorPolicy{}, LatencyPolicy{Threshold: 500 * time.Millisecond}, attr},
+	}, rec.forward)
+
+	now := time.Unix(1000, 0)
+	s.AddAt([]Span{
+		span(1, now, 10*time.Millisecond, codes.OK),
+		span(1, now, 20*time.Millisecond, codes.Unavailable),
+		span(2, now, 600*time.Millisecond, codes.OK),
+		span(3, now, 10*time.Millisecond, codes.OK, key.String("fault", "hang")),
+		span(4, now, 10*time.Millisecond, codes.OK, key.String("fault", "error")),
+	}, now)
+
+	s.Tick(now.Add(500 * time.Millisecond))
+	if st := s.Stats(); st.Pending != 4 || st.KeptTotal() != 0 {
+		t.Fatalf("traces decided before the decision window passed: %+v", st)
+	}
+
+	s.Tick(now.Add(time.Second))
+	// A late span follows the decision on its trace.
+	s.AddAt([]Span{span(1, now, time.Millisecond, codes.OK), span(4, now, time.Millisecond, codes.OK)}, now.Add(time.Second))
+
+	want := map[byte]int{1: 3, 2: 1, 3: 1}
+	for id, n := range want {
+		var tid core.TraceID
+		tid[0] = id
+		if rec.spans[tid] != n {
+			t.Errorf("got %d spans of trace %d forwarded, want %d", rec.spans[tid], id, n)
+		}
+	}
+	if len(rec.spans) != len(want) {
+		t.Errorf("got %d traces forwarded, want %d", len(rec.spans), len(want))
+	}
+
+	st := s.Stats()
+	wantKept := map[string]int{"error": 1, "latency": 1, "attribute:fault": 1}
+	for p, n := range wantKept {
+		if st.Kept[p] != n {
+			t.Errorf("got %d traces kept by %s, want %d", st.Kept[p], p, n)
+		}
+	}
+	if st.Spans != 7 || st.Dropped != 1 || st.Pending != 0 || st.LateSpans != 2 {
+		t.Errorf("got stats %+v", st)
+	}
+}
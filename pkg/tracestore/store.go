@@ -0,0 +1,868 @@
+// Package tracestore stores spans in files, so that traces survive restarts
+// and can be looked at offline, e.g. during demos without network access.
+//
+// A store is a directory of append-only segment files. Every segment is a
+// sequence of records, each of which holds the spans of one process:
+//
+//	length  uint32, big endian, length of the payload
+//	crc     uint32, big endian, CRC-32 (IEEE) of the payload
+//	payload OTLP ResourceSpans, protobuf encoded
+//
+// Spans are appended to the active segment until it reaches the segment size.
+// It is sealed then and a new one is started. Sealed segments are never
+// written to again; they are deleted as a whole once they are past the
+// retention by age or size, and small ones are merged by compaction.
+//
+// The index of traces by ID, service and start time is kept in memory and
+// rebuilt from the segments when a store is opened. Searches go through it
+// and read spans from the segments only to match attributes, without holding
+// up spans being added. A store must have a single writer, but any number of
+// read-only readers, e.g. the traces command.
+package tracestore
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/johananl/otel-demo/pkg/otlp"
+	"github.com/johananl/otel-demo/pkg/traceview"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/key"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// DefaultSegmentSize is the size at which segments are sealed unless
+	// configured otherwise.
+	DefaultSegmentSize = 4 << 20
+	// headerSize is the size of the header of a record.
+	headerSize = 8
+	// maxRecordSize bounds the payload of a record. Larger lengths are
+	// treated as corruption.
+	maxRecordSize = 64 << 20
+	// segmentExt and tmpExt are the extensions of segment files and of
+	// segment files being written by compaction.
+	segmentExt = ".seg"
+	tmpExt     = ".tmp"
+)
+
+// Options configure a Store. Zero values disable retention.
+type Options struct {
+	// SegmentSize is the size at which the active segment is sealed.
+	// DefaultSegmentSize is used if it is 0.
+	SegmentSize int64
+	// MaxSize bounds the total size of the segments. The oldest segments are
+	// deleted once it is exceeded.
+	MaxSize int64
+	// MaxAge bounds the age of the stored spans. Segments are deleted once
+	// their latest span is older.
+	MaxAge time.Duration
+	// ReadOnly opens the store for reading only. Spans added since are not
+	// seen and adding spans fails.
+	ReadOnly bool
+}
+
+// segment is a segment file.
+type segment struct {
+	id   int64
+	f    *os.File
+	size int64
+	// maxEnd is the latest end time of the spans in the segment.
+	maxEnd time.Time
+}
+
+// meta is the index entry of a span.
+type meta struct {
+	seg *segment
+	// off is the offset of the record holding the span and idx the position
+	// of the span within it.
+	off int64
+	idx int
+
+	spanID, parentID core.SpanID
+	name, service    string
+	start, end       time.Time
+	status           codes.Code
+}
+
+// trace is the index entry of a trace.
+type trace struct {
+	id    core.TraceID
+	metas []meta
+	// spans holds the IDs of the spans in metas, so that spans stored twice
+	// are indexed once.
+	spans map[core.SpanID]bool
+	// services counts the spans in metas by service.
+	services map[string]int
+	// start is the earliest start time of the spans.
+	start time.Time
+	// sum is the summary of the trace, or nil if spans were added or removed
+	// since it was computed.
+	sum *traceview.Summary
+}
+
+// before reports whether t sorts before o in the time-ordered index.
+func (t *trace) before(o *trace) bool {
+	if !t.start.Equal(o.start) {
+		return t.start.Before(o.start)
+	}
+
+	return bytes.Compare(t.id[:], o.id[:]) < 0
+}
+
+// summary returns the summary of the trace, computing it from the index if
+// needed.
+func (t *trace) summary() traceview.Summary {
+	if t.sum == nil {
+		sum := traceview.Summarize(t.id, summarySpans(t.id, t.metas))
+		t.sum = &sum
+	}
+
+	return *t.sum
+}
+
+// Store is a file-backed span store. It is safe for concurrent use.
+type Store struct {
+	dir  string
+	opts Options
+
+	mu     sync.Mutex
+	sealed []*segment
+	// active is nil if the store is read-only.
+	active *segment
+	nextID int64
+	traces map[core.TraceID]*trace
+	// byService holds the traces with spans of each service.
+	byService map[string]map[*trace]bool
+	// byStart holds the traces ordered by start time, earliest first.
+	byStart []*trace
+}
+
+var _ traceview.Store = (*Store)(nil)
+
+// Open opens the store in dir, creating it if needed.
+func Open(dir string, opts Options) (*Store, error) {
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = DefaultSegmentSize
+	}
+	if !opts.ReadOnly {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Store{
+		dir:       dir,
+		opts:      opts,
+		traces:    map[core.TraceID]*trace{},
+		byService: map[string]map[*trace]bool{},
+	}
+	if err := s.load(); err != nil {
+		s.Close()
+		return nil, err
+	}
+	if !opts.ReadOnly {
+		if err := s.roll(); err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.enforce(time.Now())
+	}
+
+	return s, nil
+}
+
+// load opens and indexes the segments in the directory of the store.
+func (s *Store) load() error {
+	names, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, fi := range names {
+		name := fi.Name()
+		if strings.HasSuffix(name, tmpExt) && !s.opts.ReadOnly {
+			// Left over by an interrupted compaction.
+			os.Remove(filepath.Join(s.dir, name))
+			continue
+		}
+		var id int64
+		if _, err := fmt.Sscanf(name, "%016x"+segmentExt, &id); err != nil || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+
+		f, err := os.Open(filepath.Join(s.dir, name))
+		if err != nil {
+			return err
+		}
+		seg := &segment{id: id, f: f}
+		s.sealed = append(s.sealed, seg)
+		if id >= s.nextID {
+			s.nextID = id + 1
+		}
+		if err := s.scan(seg); err != nil {
+			return err
+		}
+	}
+	sort.Slice(s.sealed, func(i, j int) bool { return s.sealed[i].id < s.sealed[j].id })
+
+	return nil
+}
+
+// scan indexes the records of seg. A truncated or corrupt record ends the
+// segment, as it is the result of an interrupted write.
+func (s *Store) scan(seg *segment) error {
+	var off int64
+	for {
+		rs, n, err := readRecord(seg.f, off)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Printf("Ignoring the rest of trace store segment %s after offset %d: %v", seg.f.Name(), off, err)
+			break
+		}
+		s.index(seg, off, rs)
+		off += n
+	}
+	seg.size = off
+
+	return nil
+}
+
+// Close closes the files of the store.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var firstErr error
+	for _, seg := range s.segments() {
+		if err := seg.f.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	s.sealed, s.active = nil, nil
+
+	return firstErr
+}
+
+// segments returns all segments, the active one last.
+func (s *Store) segments() []*segment {
+	segs := append([]*segment(nil), s.sealed...)
+	if s.active != nil {
+		segs = append(segs, s.active)
+	}
+
+	return segs
+}
+
+// Add appends spans recorded by the process described by resource to the
+// active segment. Errors are logged, as spans are exported in the
+// background.
+func (s *Store) Add(resource []core.KeyValue, spans []*export.SpanData) {
+	rs := &tracepb.ResourceSpans{
+		Resource: otlp.Resource(resource),
+		InstrumentationLibrarySpans: []*tracepb.InstrumentationLibrarySpans{{
+			InstrumentationLibrary: &commonpb.InstrumentationLibrary{Name: "otel-demo"},
+		}},
+	}
+	for _, d := range spans {
+		ils := rs.InstrumentationLibrarySpans[0]
+		ils.Spans = append(ils.Spans, otlp.FromSpanData(d))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.append(rs); err != nil {
+		log.Printf("Error storing %d spans: %v", len(spans), err)
+	}
+}
+
+// append writes rs to the active segment and seals it if it is full.
+func (s *Store) append(rs *tracepb.ResourceSpans) error {
+	if s.active == nil {
+		return fmt.Errorf("trace store %s is read-only", s.dir)
+	}
+
+	off, err := writeRecord(s.active, rs)
+	if err != nil {
+		return err
+	}
+	s.index(s.active, off, rs)
+
+	if s.active.size >= s.opts.SegmentSize {
+		if err := s.roll(); err != nil {
+			return err
+		}
+		s.enforce(time.Now())
+	}
+
+	return nil
+}
+
+// roll seals the active segment and starts a new one.
+func (s *Store) roll() error {
+	seg, err := s.create(segmentExt)
+	if err != nil {
+		return err
+	}
+	if s.active != nil {
+		s.sealed = append(s.sealed, s.active)
+	}
+	s.active = seg
+
+	return nil
+}
+
+// create creates an empty segment file with the given extension.
+func (s *Store) create(ext string) (*segment, error) {
+	id := s.nextID
+	f, err := os.OpenFile(filepath.Join(s.dir, fmt.Sprintf("%016x", id)+ext), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, err
+	}
+	s.nextID++
+
+	return &segment{id: id, f: f}, nil
+}
+
+// index adds the spans of the record at off in seg to the index.
+func (s *Store) index(seg *segment, off int64, rs *tracepb.ResourceSpans) {
+	service := otlp.ServiceName(otlp.ToAttributes(rs.GetResource().GetAttributes()))
+	idx := 0
+	for _, ils := range rs.InstrumentationLibrarySpans {
+		for _, sp := range ils.Spans {
+			d := otlp.ToSpanData(sp)
+			m := meta{
+				seg:      seg,
+				off:      off,
+				idx:      idx,
+				spanID:   d.SpanContext.SpanID,
+				parentID: d.ParentSpanID,
+				name:     d.Name,
+				service:  service,
+				start:    d.StartTime,
+				end:      d.EndTime,
+				status:   d.Status,
+			}
+			idx++
+			if m.end.After(seg.maxEnd) {
+				seg.maxEnd = m.end
+			}
+
+			id := d.SpanContext.TraceID
+			t := s.traces[id]
+			if t == nil {
+				t = &trace{id: id, spans: map[core.SpanID]bool{}, services: map[string]int{}, start: m.start}
+				s.traces[id] = t
+				s.insert(t)
+			}
+			// Spans may be stored twice if compaction was interrupted
+			// after writing the merged segments.
+			if t.spans[m.spanID] {
+				continue
+			}
+			t.spans[m.spanID] = true
+			t.metas = append(t.metas, m)
+			t.sum = nil
+			if t.services[service] == 0 {
+				if s.byService[service] == nil {
+					s.byService[service] = map[*trace]bool{}
+				}
+				s.byService[service][t] = true
+			}
+			t.services[service]++
+			if m.start.Before(t.start) {
+				s.unlink(t)
+				t.start = m.start
+				s.insert(t)
+			}
+		}
+	}
+}
+
+// insert adds t to the time-ordered index.
+func (s *Store) insert(t *trace) {
+	i := sort.Search(len(s.byStart), func(i int) bool { return !s.byStart[i].before(t) })
+	s.byStart = append(s.byStart, nil)
+	copy(s.byStart[i+1:], s.byStart[i:])
+	s.byStart[i] = t
+}
+
+// unlink removes t from the time-ordered index.
+func (s *Store) unlink(t *trace) {
+	i := sort.Search(len(s.byStart), func(i int) bool { return !s.byStart[i].before(t) })
+	if i == len(s.byStart) || s.byStart[i] != t {
+		return
+	}
+	copy(s.byStart[i:], s.byStart[i+1:])
+	s.byStart[len(s.byStart)-1] = nil
+	s.byStart = s.byStart[:len(s.byStart)-1]
+}
+
+// latest calls fn with the traces with spans of service, or all traces if
+// it is empty, latest first, until fn returns false.
+func (s *Store) latest(service string, fn func(t *trace) bool) {
+	if service == "" {
+		for i := len(s.byStart) - 1; i >= 0; i-- {
+			if !fn(s.byStart[i]) {
+				return
+			}
+		}
+		return
+	}
+
+	ts := make([]*trace, 0, len(s.byService[service]))
+	for t := range s.byService[service] {
+		ts = append(ts, t)
+	}
+	sort.Slice(ts, func(i, j int) bool { return ts[j].before(ts[i]) })
+	for _, t := range ts {
+		if !fn(t) {
+			return
+		}
+	}
+}
+
+// Find returns the summaries of the traces matching q, latest first.
+func (s *Store) Find(q traceview.Query) []traceview.Summary {
+	// Attributes aren't indexed, so the traces matching the rest of the
+	// query are read to match them once the lock is released.
+	type candidate struct {
+		sum   traceview.Summary
+		metas []meta
+	}
+	var found []traceview.Summary
+	var candidates []candidate
+	byOperation := traceview.Query{Operation: q.Operation}
+
+	s.mu.Lock()
+	s.latest(q.Service, func(t *trace) bool {
+		sum := t.summary()
+		if !q.Match(sum) || !byOperation.MatchSpans(summarySpans(t.id, t.metas)) {
+			return true
+		}
+		if len(q.Attributes) > 0 {
+			candidates = append(candidates, candidate{sum, append([]meta(nil), t.metas...)})
+			return true
+		}
+		found = append(found, sum)
+		return q.Limit <= 0 || len(found) < q.Limit
+	})
+	s.mu.Unlock()
+
+	for _, c := range candidates {
+		if q.Limit > 0 && len(found) == q.Limit {
+			break
+		}
+		spans, err := s.read(c.metas)
+		if err != nil {
+			log.Printf("Error reading trace %x: %v", c.sum.ID, err)
+			continue
+		}
+		if q.MatchSpans(spans) {
+			found = append(found, c.sum)
+		}
+	}
+
+	return found
+}
+
+// summarySpans returns spans with the fields needed by traceview.Summarize
+// from the index, so that traces can be searched without reading them.
+func summarySpans(id core.TraceID, metas []meta) []traceview.Span {
+	spans := make([]traceview.Span, len(metas))
+	for i, m := range metas {
+		spans[i] = traceview.Span{
+			Resource: []core.KeyValue{key.String(otlp.ServiceNameKey, m.service)},
+			Data: &export.SpanData{
+				SpanContext:  core.SpanContext{TraceID: id, SpanID: m.spanID},
+				ParentSpanID: m.parentID,
+				Name:         m.name,
+				StartTime:    m.start,
+				EndTime:      m.end,
+				Status:       m.status,
+			},
+		}
+	}
+
+	return spans
+}
+
+// Trace returns the spans of a trace or nil if it is unknown. The spans are
+// read without holding the lock, so a trace whose segments are deleted
+// meanwhile is reported as unknown.
+func (s *Store) Trace(id core.TraceID) []traceview.Span {
+	s.mu.Lock()
+	var metas []meta
+	if t := s.traces[id]; t != nil {
+		metas = append(metas, t.metas...)
+	}
+	s.mu.Unlock()
+
+	spans, err := s.read(metas)
+	if err != nil {
+		log.Printf("Error reading trace %x: %v", id, err)
+		return nil
+	}
+
+	return spans
+}
+
+// recordKey identifies a record.
+type recordKey struct {
+	seg *segment
+	off int64
+}
+
+// read reads the spans of the given index entries. Every record is read
+// once.
+func (s *Store) read(metas []meta) ([]traceview.Span, error) {
+	type record struct {
+		resource []core.KeyValue
+		spans    []*tracepb.Span
+	}
+	records := map[recordKey]record{}
+
+	var spans []traceview.Span
+	for _, m := range metas {
+		k := recordKey{m.seg, m.off}
+		r, ok := records[k]
+		if !ok {
+			rs, _, err := readRecord(m.seg.f, m.off)
+			if err != nil {
+				return nil, err
+			}
+			r.resource = otlp.ToAttributes(rs.GetResource().GetAttributes())
+			for _, ils := range rs.InstrumentationLibrarySpans {
+				r.spans = append(r.spans, ils.Spans...)
+			}
+			records[k] = r
+		}
+		if m.idx >= len(r.spans) {
+			return nil, fmt.Errorf("record at %s:%d has no span %d", m.seg.f.Name(), m.off, m.idx)
+		}
+		spans = append(spans, traceview.Span{Resource: r.resource, Data: otlp.ToSpanData(r.spans[m.idx])})
+	}
+
+	return spans, nil
+}
+
+// Run enforces the retention and compacts the store periodically until ctx
+// is done.
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case now := <-t.C:
+			s.mu.Lock()
+			s.enforce(now)
+			if err := s.compact(now); err != nil {
+				log.Printf("Error compacting trace store %s: %v", s.dir, err)
+			}
+			s.mu.Unlock()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Compact enforces the retention and merges small sealed segments, e.g.
+// those sealed on restarts. The spans of a trace are written next to each
+// other, so that it is read in one go, and traces which are entirely past
+// the retention by age are dropped.
+func (s *Store) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.enforce(now)
+
+	return s.compact(now)
+}
+
+// enforce deletes the oldest sealed segments while the store is past its
+// retention.
+func (s *Store) enforce(now time.Time) {
+	var total int64
+	for _, seg := range s.segments() {
+		total += seg.size
+	}
+
+	oldest := append([]*segment(nil), s.sealed...)
+	sort.Slice(oldest, func(i, j int) bool { return oldest[i].maxEnd.Before(oldest[j].maxEnd) })
+	for _, seg := range oldest {
+		expired := s.opts.MaxAge > 0 && now.Sub(seg.maxEnd) > s.opts.MaxAge
+		full := s.opts.MaxSize > 0 && total > s.opts.MaxSize
+		if !expired && !full {
+			break
+		}
+		total -= seg.size
+		if err := s.remove([]*segment{seg}); err != nil {
+			log.Printf("Error deleting trace store segment %s: %v", seg.f.Name(), err)
+		}
+	}
+}
+
+// remove drops segs from the index and deletes their files.
+func (s *Store) remove(segs []*segment) error {
+	gone := map[*segment]bool{}
+	for _, seg := range segs {
+		gone[seg] = true
+	}
+
+	for id, t := range s.traces {
+		kept := t.metas[:0]
+		for _, m := range t.metas {
+			if !gone[m.seg] {
+				kept = append(kept, m)
+				continue
+			}
+			delete(t.spans, m.spanID)
+			if t.services[m.service]--; t.services[m.service] == 0 {
+				delete(t.services, m.service)
+				delete(s.byService[m.service], t)
+				if len(s.byService[m.service]) == 0 {
+					delete(s.byService, m.service)
+				}
+			}
+		}
+		if len(kept) == len(t.metas) {
+			continue
+		}
+		for i := len(kept); i < len(t.metas); i++ {
+			t.metas[i] = meta{}
+		}
+		t.metas, t.sum = kept, nil
+		if len(kept) == 0 {
+			delete(s.traces, id)
+			continue
+		}
+		t.start = kept[0].start
+		for _, m := range kept[1:] {
+			if m.start.Before(t.start) {
+				t.start = m.start
+			}
+		}
+	}
+	// The start of the remaining traces may have changed, so the
+	// time-ordered index is rebuilt.
+	byStart := s.byStart[:0]
+	for _, t := range s.byStart {
+		if s.traces[t.id] == t {
+			byStart = append(byStart, t)
+		}
+	}
+	for i := len(byStart); i < len(s.byStart); i++ {
+		s.byStart[i] = nil
+	}
+	sort.Slice(byStart, func(i, j int) bool { return byStart[i].before(byStart[j]) })
+	s.byStart = byStart
+
+	sealed := s.sealed[:0]
+	for _, seg := range s.sealed {
+		if !gone[seg] {
+			sealed = append(sealed, seg)
+		}
+	}
+	s.sealed = sealed
+
+	var firstErr error
+	for _, seg := range segs {
+		seg.f.Close()
+		if err := os.Remove(seg.f.Name()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// compact merges the sealed segments which are less than half full.
+func (s *Store) compact(now time.Time) error {
+	if s.active == nil {
+		return nil
+	}
+
+	small := map[*segment]bool{}
+	var smallSegs []*segment
+	for _, seg := range s.sealed {
+		if seg.size < s.opts.SegmentSize/2 {
+			small[seg] = true
+			smallSegs = append(smallSegs, seg)
+		}
+	}
+	if len(smallSegs) < 2 {
+		return nil
+	}
+
+	// Collect the spans of the small segments by trace, oldest trace first.
+	type group struct {
+		start time.Time
+		metas []meta
+	}
+	var groups []group
+	for _, t := range s.traces {
+		var g group
+		var end time.Time
+		for _, m := range t.metas {
+			if !small[m.seg] {
+				continue
+			}
+			if len(g.metas) == 0 || m.start.Before(g.start) {
+				g.start = m.start
+			}
+			if m.end.After(end) {
+				end = m.end
+			}
+			g.metas = append(g.metas, m)
+		}
+		if len(g.metas) == 0 || (s.opts.MaxAge > 0 && now.Sub(end) > s.opts.MaxAge) {
+			continue
+		}
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].start.Before(groups[j].start) })
+
+	// Write the merged segments next to the old ones and index them once
+	// they are complete.
+	var merged []*segment
+	type written struct {
+		seg *segment
+		off int64
+		rs  *tracepb.ResourceSpans
+	}
+	var records []written
+	// renamed holds the final names of the merged segments renamed so far.
+	var renamed []string
+	fail := func(err error) error {
+		for _, seg := range merged {
+			seg.f.Close()
+			os.Remove(seg.f.Name())
+		}
+		for _, name := range renamed {
+			os.Remove(name)
+		}
+		return err
+	}
+	for _, g := range groups {
+		spans, err := s.read(g.metas)
+		if err != nil {
+			return fail(err)
+		}
+		for _, rs := range traceview.Export(spans).ResourceSpans {
+			if len(merged) == 0 || merged[len(merged)-1].size >= s.opts.SegmentSize {
+				seg, err := s.create(tmpExt)
+				if err != nil {
+					return fail(err)
+				}
+				merged = append(merged, seg)
+			}
+			seg := merged[len(merged)-1]
+			off, err := writeRecord(seg, rs)
+			if err != nil {
+				return fail(err)
+			}
+			records = append(records, written{seg, off, rs})
+		}
+	}
+	for _, seg := range merged {
+		if err := seg.f.Sync(); err != nil {
+			return fail(err)
+		}
+		name := strings.TrimSuffix(seg.f.Name(), tmpExt) + segmentExt
+		if err := os.Rename(seg.f.Name(), name); err != nil {
+			return fail(err)
+		}
+		renamed = append(renamed, name)
+		seg.f.Close()
+		f, err := os.Open(name)
+		if err != nil {
+			return fail(err)
+		}
+		seg.f = f
+	}
+
+	if err := s.remove(smallSegs); err != nil {
+		log.Printf("Error deleting compacted trace store segments: %v", err)
+	}
+	s.sealed = append(s.sealed, merged...)
+	for _, r := range records {
+		s.index(r.seg, r.off, r.rs)
+	}
+	log.Printf("Compacted %d trace store segments into %d", len(smallSegs), len(merged))
+
+	return nil
+}
+
+// writeRecord appends rs to seg and returns the offset of the record.
+func writeRecord(seg *segment, rs *tracepb.ResourceSpans) (int64, error) {
+	payload, err := proto.Marshal(rs)
+	if err != nil {
+		return 0, err
+	}
+
+	var b bytes.Buffer
+	var header [headerSize]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(header[4:], crc32.ChecksumIEEE(payload))
+	b.Write(header[:])
+	b.Write(payload)
+
+	off := seg.size
+	if _, err := seg.f.WriteAt(b.Bytes(), off); err != nil {
+		return 0, err
+	}
+	seg.size += int64(b.Len())
+
+	return off, nil
+}
+
+// readRecord reads the record at off in f and returns it and its size. It
+// returns io.EOF if there is no record at off.
+func readRecord(f io.ReaderAt, off int64) (*tracepb.ResourceSpans, int64, error) {
+	var header [headerSize]byte
+	if n, err := f.ReadAt(header[:], off); err != nil {
+		if err == io.EOF && n == 0 {
+			return nil, 0, io.EOF
+		}
+		return nil, 0, fmt.Errorf("reading record header: %v", err)
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	if length > maxRecordSize {
+		return nil, 0, fmt.Errorf("record length %d exceeds %d", length, maxRecordSize)
+	}
+
+	payload := make([]byte, length)
+	if _, err := f.ReadAt(payload, off+headerSize); err != nil {
+		return nil, 0, fmt.Errorf("reading record: %v", err)
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:]) {
+		return nil, 0, fmt.Errorf("record checksum mismatch")
+	}
+
+	rs := &tracepb.ResourceSpans{}
+	if err := proto.Unmarshal(payload, rs); err != nil {
+		return nil, 0, err
+	}
+
+	return rs, headerSize + int64(length), nil
+}
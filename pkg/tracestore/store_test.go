@@ -0,0 +1,266 @@
+package tracestore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/johananl/otel-demo/pkg/traceview"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/key"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	"google.golang.org/grpc/codes"
+)
+
+var start = time.Unix(1000, 0)
+
+// addTrace adds a trace of a frontend span and a failed role span.
+func addTrace(s *Store, n byte, d time.Duration) core.TraceID {
+	id := core.TraceID{n}
+	parent := &export.SpanData{
+		SpanContext: core.SpanContext{TraceID: id, SpanID: core.SpanID{n, 1}},
+		Name:        "serve",
+		StartTime:   start,
+		EndTime:     start.Add(d),
+		Attributes:  []core.KeyValue{key.String("http.path", "/api")},
+	}
+	child := &export.SpanData{
+		SpanContext:  core.SpanContext{TraceID: id, SpanID: core.SpanID{n, 2}},
+		ParentSpanID: parent.SpanContext.SpanID,
+		Name:         "get-role",
+		StartTime:    start,
+		EndTime:      start.Add(d / 2),
+		Status:       codes.Unavailable,
+	}
+	s.Add([]core.KeyValue{key.String("service.name", "role")}, []*export.SpanData{child})
+	s.Add([]core.KeyValue{key.String("service.name", "frontend")}, []*export.SpanData{parent})
+
+	return id
+}
+
+func segmentCount(t *testing.T, dir string) int {
+	names, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return len(names)
+}
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tracestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast := addTrace(s, 1, 10*time.Millisecond)
+	slow := addTrace(s, 2, time.Second)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The spans are found again after reopening the store.
+	s, err = Open(dir, Options{ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	found := s.Find(traceview.Query{MinDuration: 100 * time.Millisecond, Service: "role", Errors: true})
+	if len(found) != 1 || found[0].ID != slow {
+		t.Fatalf("got %+v, want the slow trace", found)
+	}
+	if sum := found[0]; sum.Root != "serve" || sum.Service != "frontend" || sum.Spans != 2 || sum.Errors != 1 {
+		t.Errorf("got summary %+v", sum)
+	}
+
+	spans := s.Trace(fast)
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	if sp := spans[1]; sp.Service() != "frontend" || sp.Data.Name != "serve" || len(sp.Data.Attributes) != 1 || !sp.Data.EndTime.Equal(start.Add(10*time.Millisecond)) {
+		t.Errorf("got span %+v", sp.Data)
+	}
+	if spans[0].Data.Status != codes.Unavailable || spans[0].Data.ParentSpanID != spans[1].Data.SpanContext.SpanID {
+		t.Errorf("got span %+v", spans[0].Data)
+	}
+}
+
+func TestRetentionAndCompaction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tracestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Every restart seals the active segment, leaving small segments.
+	var ids []core.TraceID
+	for i := 0; i < 3; i++ {
+		s, err := Open(dir, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, addTrace(s, byte(i+1), time.Second))
+		s.Close()
+	}
+
+	s, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := segmentCount(t, dir); n != 4 {
+		t.Fatalf("got %d segments, want 4", n)
+	}
+	if err := s.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if n := segmentCount(t, dir); n != 2 {
+		t.Errorf("got %d segments after compaction, want 2", n)
+	}
+	for _, id := range ids {
+		if n := len(s.Trace(id)); n != 2 {
+			t.Errorf("got %d spans of trace %x after compaction, want 2", n, id)
+		}
+	}
+	s.Close()
+
+	// Segments with spans older than the maximum age are deleted.
+	s, err = Open(dir, Options{MaxAge: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if n := len(s.Find(traceview.Query{})); n != 0 {
+		t.Errorf("got %d traces past the retention, want 0", n)
+	}
+	if n := segmentCount(t, dir); n != 1 {
+		t.Errorf("got %d segments after retention, want only the active one", n)
+	}
+}
+
+func TestSizeRetention(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tracestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Every restart seals a segment of one trace. The segments are written
+	// in another order than that of the ends of their spans.
+	var ids []core.TraceID
+	for i, d := range []time.Duration{3 * time.Second, time.Second, 2 * time.Second} {
+		s, err := Open(dir, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, addTrace(s, byte(i+1), d))
+		s.Close()
+	}
+
+	names, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total, size int64
+	for _, name := range names {
+		fi, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += fi.Size()
+		if fi.Size() > size {
+			size = fi.Size()
+		}
+	}
+
+	// Exceeding the maximum size by less than a segment deletes the segment
+	// whose spans ended first.
+	s, err := Open(dir, Options{MaxSize: total - size/2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if n := segmentCount(t, dir); n != 3 {
+		t.Errorf("got %d segments, want 2 sealed ones and the active one", n)
+	}
+	for i, want := range []int{2, 0, 2} {
+		if n := len(s.Trace(ids[i])); n != want {
+			t.Errorf("got %d spans of trace %d, want %d", n, i+1, want)
+		}
+	}
+	if _, ok := s.traces[ids[1]]; ok {
+		t.Error("deleted trace is still indexed")
+	}
+	if n := len(s.Find(traceview.Query{})); n != 2 {
+		t.Errorf("got %d traces, want 2", n)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tracestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	add := func(service string, trace, id, parent byte, offset time.Duration, attrs ...core.KeyValue) {
+		s.Add([]core.KeyValue{key.String("service.name", service)}, []*export.SpanData{{
+			SpanContext:  core.SpanContext{TraceID: core.TraceID{trace}, SpanID: core.SpanID{trace, id}},
+			ParentSpanID: core.SpanID{trace, parent},
+			Name:         "op",
+			StartTime:    start.Add(offset),
+			EndTime:      start.Add(offset + time.Second),
+			Attributes:   attrs,
+		}})
+	}
+	// The root span of the first trace arrives last and moves the trace
+	// behind the second one.
+	add("role", 1, 2, 1, 3*time.Second)
+	add("frontend", 2, 1, 0, 2*time.Second)
+	add("frontend", 2, 1, 0, 2*time.Second)
+	add("frontend", 1, 1, 0, time.Second, key.String("http.path", "/api"))
+
+	for _, tc := range []struct {
+		name string
+		q    traceview.Query
+		want []byte
+	}{
+		{"all", traceview.Query{}, []byte{2, 1}},
+		{"limit", traceview.Query{Limit: 1}, []byte{2}},
+		{"service", traceview.Query{Service: "role"}, []byte{1}},
+		{"unknown service", traceview.Query{Service: "field"}, nil},
+		{"attribute", traceview.Query{Attributes: []traceview.Attribute{{Key: "http.path"}}}, []byte{1}},
+	} {
+		found := s.Find(tc.q)
+		if len(found) != len(tc.want) {
+			t.Errorf("%s: got %d traces, want %d", tc.name, len(found), len(tc.want))
+			continue
+		}
+		for i, sum := range found {
+			if sum.ID != (core.TraceID{tc.want[i]}) {
+				t.Errorf("%s: got trace %x at %d, want %d", tc.name, sum.ID, i, tc.want[i])
+			}
+		}
+	}
+
+	// The span stored twice is indexed once.
+	if sum := s.Find(traceview.Query{Limit: 1})[0]; sum.Spans != 1 {
+		t.Errorf("got %d spans of the second trace, want 1", sum.Spans)
+	}
+	if n := len(s.Trace(core.TraceID{1})); n != 2 {
+		t.Errorf("got %d spans of the first trace, want 2", n)
+	}
+}
@@ -0,0 +1,83 @@
+package tracetest
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"google.golang.org/grpc/codes"
+)
+
+// Want describes the expected properties of a span. Zero fields aren't
+// checked, except for Status since a zero status means OK.
+type Want struct {
+	Kind   trace.SpanKind
+	Status codes.Code
+	// Attributes maps attribute keys to their expected values as rendered
+	// by core.Value.Emit. Attributes not listed aren't checked.
+	Attributes map[string]string
+	// Events lists the names of events the span must have.
+	Events []string
+	// RemoteParent requires the parent span to have been propagated from
+	// another process.
+	RemoteParent bool
+}
+
+// Assert checks the span against w. A nil tree, e.g. returned by Find, fails
+// the test.
+func (n *Tree) Assert(t testing.TB, w Want) {
+	t.Helper()
+
+	if n == nil {
+		t.Fatal("span not found")
+	}
+
+	if w.Kind != trace.SpanKindUnspecified && n.Data.SpanKind != w.Kind {
+		t.Errorf("%s: kind is %s, want %s", n.label(), n.Data.SpanKind, w.Kind)
+	}
+	if n.Data.Status != w.Status {
+		t.Errorf("%s: status is %s, want %s", n.label(), n.Data.Status, w.Status)
+	}
+	for k, want := range w.Attributes {
+		v, ok := n.Attr(k)
+		if !ok {
+			t.Errorf("%s: attribute %q missing", n.label(), k)
+			continue
+		}
+		if got := v.Emit(); got != want {
+			t.Errorf("%s: attribute %q is %q, want %q", n.label(), k, got, want)
+		}
+	}
+	for _, name := range w.Events {
+		if _, ok := n.Event(name); !ok {
+			t.Errorf("%s: event %q missing", n.label(), name)
+		}
+	}
+	if w.RemoteParent && !n.Data.HasRemoteParent {
+		t.Errorf("%s: parent is not remote", n.label())
+	}
+}
+
+// AssertChildren checks that the children of the span are exactly the given
+// spans, each written as "<service> <name>", in the order of Tree.String.
+func (n *Tree) AssertChildren(t testing.TB, want ...string) {
+	t.Helper()
+
+	if n == nil {
+		t.Fatal("span not found")
+	}
+
+	got := make([]string, len(n.Children))
+	for i, c := range n.Children {
+		got[i] = c.Service + " " + c.Name
+	}
+	if len(got) != len(want) {
+		t.Errorf("%s: children are %q, want %q", n.label(), got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("%s: children are %q, want %q", n.label(), got, want)
+			return
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// Package tracetest runs all services in memory and records their spans, so
+// that tests can send requests to the frontend and assert the resulting span
+// trees.
+//
+// A typical test looks like this:
+//
+//	env := tracetest.Start(t)
+//	defer env.Close()
+//
+//	rec, traceID := env.Get(t, "/api")
+//	tree := env.Trace(t, traceID)
+//	tree.Find("frontend", "serve-http-request").Assert(t, tracetest.Want{
+//		Kind: trace.SpanKindServer,
+//	})
+package tracetest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/johananl/otel-demo/pkg/latency"
+	"github.com/johananl/otel-demo/pkg/memexport"
+	"github.com/johananl/otel-demo/pkg/stack"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/plugin/httptrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// ClientService is the service name of the spans recorded by Env.Get.
+const ClientService = "client"
+
+const (
+	// idleWait is how long no new spans must be recorded for a trace to be
+	// considered complete.
+	idleWait = 50 * time.Millisecond
+	// maxWait bounds the time spent waiting for spans.
+	maxWait = 5 * time.Second
+)
+
+// Option changes the configuration of the services started by Start.
+type Option func(*stack.Config)
+
+// WithLatency makes the backends delay slow requests according to p.
+func WithLatency(p latency.Profile) Option {
+	return func(c *stack.Config) { c.Latency = p }
+}
+
+// WithBackendTimeout bounds the backend calls of frontend requests by d.
+func WithBackendTimeout(d time.Duration) Option {
+	return func(c *stack.Config) { c.BackendTimeout = d }
+}
+
+// WithBatchWindow makes the frontend batch requests arriving within d.
+func WithBatchWindow(d time.Duration) Option {
+	return func(c *stack.Config) { c.BatchWindow = d }
+}
+
+// WithPrefetch makes the frontend prefetch up to n titles per locale.
+func WithPrefetch(n int) Option {
+	return func(c *stack.Config) { c.Prefetch = n }
+}
+
+// Env is a set of services running in memory whose spans are recorded.
+type Env struct {
+	stack     *stack.Stack
+	handler   http.Handler
+	exporters map[string]*memexport.Exporter
+	client    trace.Tracer
+}
+
+// Start starts all services. Unless an option says otherwise, slow requests
+// aren't delayed. Call Close once the test is done.
+func Start(t testing.TB, opts ...Option) *Env {
+	t.Helper()
+
+	e := &Env{exporters: map[string]*memexport.Exporter{}}
+	providers := map[string]trace.Provider{}
+	for _, service := range append([]string{ClientService}, stack.Services...) {
+		exp := memexport.New()
+		tp, err := sdktrace.NewProvider(
+			sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.AlwaysSample()}),
+			sdktrace.WithSyncer(exp),
+		)
+		if err != nil {
+			t.Fatalf("creating trace provider: %v", err)
+		}
+		e.exporters[service] = exp
+		providers[service] = tp
+	}
+
+	cfg := stack.Config{
+		InMemory: true,
+		Provider: func(service string) trace.Provider { return providers[service] },
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	st, err := stack.Start(cfg)
+	if err != nil {
+		t.Fatalf("starting services: %v", err)
+	}
+
+	e.stack = st
+	e.handler = st.Frontend.Handler("")
+	e.client = providers[ClientService].Tracer(ClientService)
+
+	return e
+}
+
+// Close stops the services.
+func (e *Env) Close() {
+	e.stack.Close()
+}
+
+// Stack returns the running services.
+func (e *Env) Stack() *stack.Stack {
+	return e.stack
+}
+
+// Reset drops all recorded spans.
+func (e *Env) Reset() {
+	for _, exp := range e.exporters {
+		exp.Reset()
+	}
+}
+
+// Get sends a GET request for target, e.g. "/api?slow=1", to the frontend
+// within a new client span. The trace context is propagated in the request
+// headers only. Get returns the response and the ID of the trace.
+func (e *Env) Get(t testing.TB, target string) (*httptest.ResponseRecorder, core.TraceID) {
+	t.Helper()
+
+	ctx, span := e.client.Start(context.Background(), "send-request", trace.WithSpanKind(trace.SpanKindClient))
+	req := httptest.NewRequest("GET", target, nil)
+	httptrace.Inject(ctx, req)
+
+	rec := httptest.NewRecorder()
+	e.handler.ServeHTTP(rec, req)
+	span.End()
+
+	return rec, span.SpanContext().TraceID
+}
+
+// Spans returns the recorded spans of a trace once no new spans were recorded
+// for a short while.
+func (e *Env) Spans(traceID core.TraceID) []Span {
+	e.waitIdle()
+
+	var spans []Span
+	for service, exp := range e.exporters {
+		for _, d := range exp.Spans() {
+			if d.SpanContext.TraceID == traceID {
+				spans = append(spans, newSpan(service, d))
+			}
+		}
+	}
+
+	return spans
+}
+
+// Trace returns the span tree of a trace. The test fails unless the trace has
+// exactly one root and every other span's parent was recorded.
+func (e *Env) Trace(t testing.TB, traceID core.TraceID) *Tree {
+	t.Helper()
+
+	tree, err := BuildTree(e.Spans(traceID))
+	if err != nil {
+		t.Fatalf("building tree of trace %x: %v", traceID, err)
+	}
+
+	return tree
+}
+
+// waitIdle waits until no new spans were recorded for idleWait, since some
+// spans may still be ending when a response was received.
+func (e *Env) waitIdle() {
+	deadline := time.Now().Add(maxWait)
+	last := -1
+	for time.Now().Before(deadline) {
+		n := 0
+		for _, exp := range e.exporters {
+			n += exp.Len()
+		}
+		if n == last {
+			return
+		}
+		last = n
+		time.Sleep(idleWait)
+	}
+}
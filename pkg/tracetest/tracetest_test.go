@@ -0,0 +1,88 @@
+package tracetest
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"google.golang.org/grpc/codes"
+)
+
+func TestPropagation(t *testing.T) {
+	env := Start(t)
+	defer env.Close()
+
+	for _, target := range []string{"/api", "/api?slow=1"} {
+		t.Run(target, func(t *testing.T) {
+			rec, traceID := env.Get(t, target)
+			if rec.Code != 200 {
+				t.Fatalf("got status %d: %s", rec.Code, rec.Body)
+			}
+
+			tree := env.Trace(t, traceID)
+			tree.Assert(t, Want{Kind: trace.SpanKindClient})
+			tree.AssertChildren(t, "frontend serve-http-request")
+
+			frontend := tree.Find("frontend", "serve-http-request")
+			frontend.Assert(t, Want{
+				Kind:         trace.SpanKindServer,
+				Attributes:   map[string]string{"locale": "en"},
+				Events:       []string{"Generating response"},
+				RemoteParent: true,
+			})
+			frontend.AssertChildren(t,
+				"field handle-grpc-request",
+				"role handle-grpc-request",
+				"seniority handle-grpc-request",
+			)
+
+			for _, service := range []string{"seniority", "field", "role"} {
+				tree.Find(service, "handle-grpc-request").Assert(t, Want{
+					Kind:         trace.SpanKindServer,
+					Events:       []string{"Selected " + service},
+					RemoteParent: true,
+				})
+			}
+		})
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	env := Start(t)
+	defer env.Close()
+
+	rec, traceID := env.Get(t, "/api?slow=1&tag=no-such-tag")
+	if rec.Code != 500 {
+		t.Fatalf("got status %d, want 500", rec.Code)
+	}
+
+	tree := env.Trace(t, traceID)
+
+	// Slow requests stop at the first failing backend.
+	tree.Find("frontend", "serve-http-request").Assert(t, Want{
+		Status:     codes.NotFound,
+		Attributes: map[string]string{"tags": "no-such-tag"},
+		Events:     []string{"Error generating title"},
+	})
+	tree.Find("frontend", "serve-http-request").AssertChildren(t, "seniority handle-grpc-request")
+	tree.Find("seniority", "handle-grpc-request").Assert(t, Want{
+		Kind:   trace.SpanKindServer,
+		Status: codes.NotFound,
+	})
+}
+
+func TestTreeString(t *testing.T) {
+	env := Start(t)
+	defer env.Close()
+
+	_, traceID := env.Get(t, "/api/titles?count=2")
+
+	want := `client send-request (client)
+  frontend serve-http-request (server)
+    field handle-grpc-request (server)
+    role handle-grpc-request (server)
+    seniority handle-grpc-request (server)
+`
+	if got := env.Trace(t, traceID).String(); got != want {
+		t.Errorf("got tree\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,170 @@
+package tracetest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/api/core"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// Span is a recorded span of a service.
+type Span struct {
+	// Service is the service which recorded the span.
+	Service string
+	// Name is the name of the span without the tracer name prefix added by
+	// the SDK.
+	Name string
+	// Data holds everything recorded about the span.
+	Data *export.SpanData
+}
+
+func newSpan(service string, d *export.SpanData) Span {
+	return Span{
+		Service: service,
+		Name:    strings.TrimPrefix(d.Name, service+"/"),
+		Data:    d,
+	}
+}
+
+// Attr returns the value of an attribute of the span.
+func (s Span) Attr(key string) (core.Value, bool) {
+	for _, kv := range s.Data.Attributes {
+		if string(kv.Key) == key {
+			return kv.Value, true
+		}
+	}
+
+	return core.Value{}, false
+}
+
+// Event returns the first event of the span with the given name.
+func (s Span) Event(name string) (export.Event, bool) {
+	for _, ev := range s.Data.MessageEvents {
+		if ev.Name == name {
+			return ev, true
+		}
+	}
+
+	return export.Event{}, false
+}
+
+// Tree is a span and its children.
+type Tree struct {
+	Span
+	Children []*Tree
+}
+
+// BuildTree arranges the spans of a single trace in a tree. It fails unless
+// there is exactly one root and the parent of every other span is among the
+// spans.
+func BuildTree(spans []Span) (*Tree, error) {
+	nodes := make(map[core.SpanID]*Tree, len(spans))
+	for _, s := range spans {
+		nodes[s.Data.SpanContext.SpanID] = &Tree{Span: s}
+	}
+
+	var root *Tree
+	for _, s := range spans {
+		n := nodes[s.Data.SpanContext.SpanID]
+		if !s.Data.ParentSpanID.IsValid() {
+			if root != nil {
+				return nil, fmt.Errorf("multiple roots: %s and %s", root.label(), n.label())
+			}
+			root = n
+			continue
+		}
+
+		parent, ok := nodes[s.Data.ParentSpanID]
+		if !ok {
+			return nil, fmt.Errorf("parent of %s was not recorded", n.label())
+		}
+		parent.Children = append(parent.Children, n)
+	}
+	if root == nil {
+		return nil, fmt.Errorf("no root span among %d spans", len(spans))
+	}
+
+	root.sort()
+	return root, nil
+}
+
+// sort orders children by service, name and start time, so that trees of
+// concurrent calls are rendered deterministically.
+func (n *Tree) sort() {
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Data.StartTime.Before(b.Data.StartTime)
+	})
+	for _, c := range n.Children {
+		c.sort()
+	}
+}
+
+// Find returns the first span with the given service and name in depth-first
+// order or nil if there is none.
+func (n *Tree) Find(service, name string) *Tree {
+	if all := n.FindAll(service, name); len(all) > 0 {
+		return all[0]
+	}
+
+	return nil
+}
+
+// FindAll returns all spans with the given service and name in depth-first
+// order.
+func (n *Tree) FindAll(service, name string) []*Tree {
+	var found []*Tree
+	n.Walk(func(t *Tree, depth int) {
+		if t.Service == service && t.Name == name {
+			found = append(found, t)
+		}
+	})
+
+	return found
+}
+
+// Walk calls fn for every span of the tree in depth-first order.
+func (n *Tree) Walk(fn func(t *Tree, depth int)) {
+	n.walk(fn, 0)
+}
+
+func (n *Tree) walk(fn func(t *Tree, depth int), depth int) {
+	fn(n, depth)
+	for _, c := range n.Children {
+		c.walk(fn, depth+1)
+	}
+}
+
+// Len returns the number of spans in the tree.
+func (n *Tree) Len() int {
+	l := 0
+	n.Walk(func(*Tree, int) { l++ })
+
+	return l
+}
+
+// String renders the tree with one span per line, indented by depth:
+//
+//	client send-request (client)
+//	  frontend serve-http-request (server)
+//	    seniority handle-grpc-request (server)
+func (n *Tree) String() string {
+	var b strings.Builder
+	n.Walk(func(t *Tree, depth int) {
+		fmt.Fprintf(&b, "%s%s\n", strings.Repeat("  ", depth), t.label())
+	})
+
+	return b.String()
+}
+
+func (n *Tree) label() string {
+	return fmt.Sprintf("%s %s (%s)", n.Service, n.Name, n.Data.SpanKind)
+}
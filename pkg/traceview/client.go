@@ -0,0 +1,109 @@
+package traceview
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"go.opentelemetry.io/otel/api/core"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+)
+
+// Client queries the trace viewer of a remote process, e.g. of the frontend.
+type Client struct {
+	baseURL string
+	hc      *http.Client
+}
+
+// NewClient returns a client of the trace viewer served at baseURL + Path.
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/") + Path,
+		hc:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Find returns the summaries of the traces matching q, latest first.
+func (c *Client) Find(q Query) ([]Summary, error) {
+	v := q.Values()
+	v.Set("format", "json")
+	body, err := c.get(c.baseURL + "?" + v.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var in []summaryJSON
+	if err := json.Unmarshal(body, &in); err != nil {
+		return nil, fmt.Errorf("decoding traces: %v", err)
+	}
+	found := make([]Summary, len(in))
+	for i, s := range in {
+		id, err := core.TraceIDFromHex(s.TraceID)
+		if err != nil {
+			return nil, err
+		}
+		d, err := time.ParseDuration(s.Duration)
+		if err != nil {
+			return nil, err
+		}
+		found[i] = Summary{
+			ID:       id,
+			Root:     s.Root,
+			Service:  s.Service,
+			Start:    s.Start,
+			Duration: d,
+			Spans:    s.Spans,
+			Errors:   s.Errors,
+			Services: s.Services,
+		}
+	}
+
+	return found, nil
+}
+
+// Trace returns the spans of a trace or nil if it is unknown.
+func (c *Client) Trace(id core.TraceID) ([]Span, error) {
+	body, err := c.get(c.baseURL + "/" + hex.EncodeToString(id[:]) + "?format=otlp")
+	if err == errNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	req := &collectorpb.ExportTraceServiceRequest{}
+	if err := proto.Unmarshal(body, req); err != nil {
+		return nil, fmt.Errorf("decoding trace: %v", err)
+	}
+
+	return Import(req), nil
+}
+
+var errNotFound = errors.New("not found")
+
+func (c *Client) get(url string) ([]byte, error) {
+	resp, err := c.hc.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return body, nil
+	case http.StatusNotFound:
+		return nil, errNotFound
+	}
+
+	return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
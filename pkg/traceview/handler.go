@@ -0,0 +1,312 @@
+package traceview
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"go.opentelemetry.io/otel/api/core"
+	"google.golang.org/grpc/codes"
+)
+
+// Path is the path the handler expects to be mounted on. Traces are served on
+// Path + "/<trace ID>".
+const Path = "/debug/traces"
+
+// defaultLimit bounds the number of listed traces if the query doesn't.
+const defaultLimit = 100
+
+// Handler serves the traces in store: a list of the traces matching the query
+// parameters trace_id, service, operation, attr (repeatable, key=value), min
+// and max (durations, e.g. 100ms) and errors on Path and the waterfall of a
+// trace on Path/<trace ID>.
+//
+// With format=json, the list is served as JSON and traces as OTLP JSON. With
+// format=otlp, traces are served as protobuf encoded OTLP export requests.
+func Handler(store Store) http.Handler {
+	return &handler{store: store}
+}
+
+type handler struct {
+	store Store
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if id := strings.Trim(strings.TrimPrefix(r.URL.Path, Path), "/"); id != "" {
+		h.serveTrace(w, r, id)
+		return
+	}
+	h.serveList(w, r)
+}
+
+// summaryJSON is the JSON representation of a Summary.
+type summaryJSON struct {
+	TraceID  string    `json:"trace_id"`
+	Root     string    `json:"root"`
+	Service  string    `json:"service"`
+	Start    time.Time `json:"start"`
+	Duration string    `json:"duration"`
+	Spans    int       `json:"spans"`
+	Errors   int       `json:"errors"`
+	Services []string  `json:"services"`
+}
+
+// serveList serves the traces matching the query.
+func (h *handler) serveList(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	q, err := ParseQuery(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	found := h.store.Find(q)
+	if v.Get("format") == "json" {
+		out := make([]summaryJSON, len(found))
+		for i, s := range found {
+			out[i] = summaryJSON{
+				TraceID:  hex.EncodeToString(s.ID[:]),
+				Root:     s.Root,
+				Service:  s.Service,
+				Start:    s.Start,
+				Duration: s.Duration.String(),
+				Spans:    s.Spans,
+				Errors:   s.Errors,
+				Services: s.Services,
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			log.Printf("Error encoding traces: %v", err)
+		}
+		return
+	}
+
+	// A complete trace ID leads to the trace.
+	if len(q.TraceID) == 32 && len(found) == 1 {
+		http.Redirect(w, r, Path+"/"+q.TraceID, http.StatusFound)
+		return
+	}
+
+	render(w, listTemplate, map[string]interface{}{
+		"Query":  v,
+		"Traces": found,
+		"Limit":  q.Limit,
+	})
+}
+
+// ParseQuery parses query parameters in the form served by Handler.
+func ParseQuery(v url.Values) (Query, error) {
+	q := Query{
+		TraceID:   strings.TrimSpace(v.Get("trace_id")),
+		Service:   v.Get("service"),
+		Operation: v.Get("operation"),
+		Limit:     defaultLimit,
+	}
+	var err error
+	for _, s := range v["attr"] {
+		if s == "" {
+			continue
+		}
+		a, err := ParseAttribute(s)
+		if err != nil {
+			return Query{}, err
+		}
+		q.Attributes = append(q.Attributes, a)
+	}
+	if s := v.Get("min"); s != "" {
+		if q.MinDuration, err = time.ParseDuration(s); err != nil {
+			return Query{}, err
+		}
+	}
+	if s := v.Get("max"); s != "" {
+		if q.MaxDuration, err = time.ParseDuration(s); err != nil {
+			return Query{}, err
+		}
+	}
+	if s := v.Get("errors"); s != "" {
+		if q.Errors, err = strconv.ParseBool(s); err != nil {
+			return Query{}, err
+		}
+	}
+	if s := v.Get("limit"); s != "" {
+		if q.Limit, err = strconv.Atoi(s); err != nil {
+			return Query{}, err
+		}
+	}
+
+	return q, nil
+}
+
+// Values returns the query parameters parsed by ParseQuery.
+func (q Query) Values() url.Values {
+	v := url.Values{}
+	set := func(k, s string) {
+		if s != "" {
+			v.Set(k, s)
+		}
+	}
+	set("trace_id", q.TraceID)
+	set("service", q.Service)
+	set("operation", q.Operation)
+	for _, a := range q.Attributes {
+		v.Add("attr", a.String())
+	}
+	if q.MinDuration > 0 {
+		v.Set("min", q.MinDuration.String())
+	}
+	if q.MaxDuration > 0 {
+		v.Set("max", q.MaxDuration.String())
+	}
+	if q.Errors {
+		v.Set("errors", "true")
+	}
+	if q.Limit > 0 {
+		v.Set("limit", strconv.Itoa(q.Limit))
+	}
+
+	return v
+}
+
+// serveTrace serves the waterfall of a trace.
+func (h *handler) serveTrace(w http.ResponseWriter, r *http.Request, hexID string) {
+	id, err := core.TraceIDFromHex(hexID)
+	if err != nil {
+		http.Error(w, "invalid trace ID", http.StatusBadRequest)
+		return
+	}
+	spans := h.store.Trace(id)
+	if len(spans) == 0 {
+		http.Error(w, "trace not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		m := jsonpb.Marshaler{Indent: "  "}
+		if err := m.Marshal(w, Export(spans)); err != nil {
+			log.Printf("Error encoding trace %s: %v", hexID, err)
+		}
+	case "otlp":
+		b, err := proto.Marshal(Export(spans))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.Write(b)
+	default:
+		render(w, traceTemplate, map[string]interface{}{
+			"Summary": Summarize(id, spans),
+			"Rows":    Waterfall(spans),
+		})
+	}
+}
+
+func render(w http.ResponseWriter, t *template.Template, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := t.Execute(w, data); err != nil {
+		log.Printf("Error rendering %s: %v", t.Name(), err)
+	}
+}
+
+var funcs = template.FuncMap{
+	"path": func() string { return Path },
+	"hex": func(id core.TraceID) string {
+		return hex.EncodeToString(id[:])
+	},
+	"spanid": func(id core.SpanID) string {
+		return hex.EncodeToString(id[:])
+	},
+	"ms": func(d time.Duration) string {
+		return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 2, 64) + "ms"
+	},
+	"pct": func(d, total time.Duration) string {
+		if total <= 0 {
+			return "0"
+		}
+		return strconv.FormatFloat(100*float64(d)/float64(total), 'f', 3, 64)
+	},
+	"failed": func(c codes.Code) bool { return c != codes.OK },
+	"indent": func(depth int) int { return depth * 16 },
+	"value":  func(v core.Value) string { return v.Emit() },
+	"since":  func(start, t time.Time) time.Duration { return t.Sub(start) },
+}
+
+const style = `<style>
+body { font-family: sans-serif; font-size: 14px; margin: 1em 2em; }
+table { border-collapse: collapse; }
+td, th { padding: 2px 10px; text-align: left; }
+tr:nth-child(even) { background: #f4f4f4; }
+.error { color: #c00; }
+.row { display: flex; align-items: center; border-bottom: 1px solid #eee; }
+.label { width: 35%; overflow: hidden; white-space: nowrap; }
+.lane { position: relative; width: 65%; height: 18px; }
+.bar { position: absolute; height: 12px; top: 3px; background: #4a90d9; min-width: 1px; }
+.bar.error { background: #d9534f; }
+.dur { position: absolute; top: 1px; font-size: 11px; color: #555; padding-left: 4px; white-space: nowrap; }
+details { font-size: 12px; color: #333; }
+</style>`
+
+var listTemplate = template.Must(template.New("trace list").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html><head><title>Traces</title>` + style + `</head><body>
+<h1>Traces</h1>
+<form method="get" action="{{path}}">
+Trace ID <input name="trace_id" value="{{.Query.Get "trace_id"}}" size="34">
+Service <input name="service" value="{{.Query.Get "service"}}" size="10">
+Operation <input name="operation" value="{{.Query.Get "operation"}}" size="14">
+Attribute <input name="attr" value="{{.Query.Get "attr"}}" size="14" placeholder="key=value">
+Min <input name="min" value="{{.Query.Get "min"}}" size="6" placeholder="100ms">
+Max <input name="max" value="{{.Query.Get "max"}}" size="6">
+<label><input type="checkbox" name="errors" value="true"{{if .Query.Get "errors"}} checked{{end}}> Errors only</label>
+<input type="submit" value="Search">
+</form>
+<p>{{len .Traces}} traces{{if eq (len .Traces) .Limit}} (limited to {{.Limit}}){{end}}</p>
+<table>
+<tr><th>Start</th><th>Root</th><th>Duration</th><th>Spans</th><th>Errors</th><th>Services</th><th>Trace ID</th></tr>
+{{range .Traces}}<tr{{if .Errors}} class="error"{{end}}>
+<td>{{.Start.Format "15:04:05.000"}}</td>
+<td>{{.Service}}: {{.Root}}</td>
+<td>{{ms .Duration}}</td>
+<td>{{.Spans}}</td>
+<td>{{.Errors}}</td>
+<td>{{range $i, $s := .Services}}{{if $i}}, {{end}}{{$s}}{{end}}</td>
+<td><a href="{{path}}/{{hex .ID}}">{{hex .ID}}</a></td>
+</tr>
+{{end}}</table>
+</body></html>
+`))
+
+var traceTemplate = template.Must(template.New("trace").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html><head><title>Trace {{hex .Summary.ID}}</title>` + style + `</head><body>
+<p><a href="{{path}}">All traces</a></p>
+{{with .Summary}}<h1>{{.Service}}: {{.Root}}</h1>
+<p>Trace {{hex .ID}}, started {{.Start.Format "2006-01-02 15:04:05.000"}}, {{ms .Duration}}, {{.Spans}} spans, {{.Errors}} errors</p>
+{{end}}
+{{$total := .Summary.Duration}}
+{{range .Rows}}<div class="row">
+<div class="label" style="padding-left: {{indent .Depth}}px">
+<details><summary{{if failed .Data.Status}} class="error"{{end}}>{{.Service}}: {{.Data.Name}}</summary>
+span {{spanid .Data.SpanContext.SpanID}}, {{.Data.SpanKind}}, status {{.Data.Status}}
+{{range .Data.Attributes}}<br>{{.Key}} = {{value .Value}}{{end}}
+{{$start := .Data.StartTime}}{{range .Data.MessageEvents}}<br>+{{ms (since $start .Time)}} {{.Name}}{{range .Attributes}} {{.Key}}={{value .Value}}{{end}}{{end}}
+{{range .Data.Links}}<br>link to <a href="{{path}}/{{hex .TraceID}}">{{hex .TraceID}}</a>{{end}}
+</details>
+</div>
+<div class="lane">
+<div class="bar{{if failed .Data.Status}} error{{end}}" style="left: {{pct .Offset $total}}%; width: {{pct (.Data.EndTime.Sub .Data.StartTime) $total}}%"></div>
+<span class="dur" style="left: {{pct .Offset $total}}%">{{ms (.Data.EndTime.Sub .Data.StartTime)}}</span>
+</div>
+</div>
+{{end}}
+</body></html>
+`))
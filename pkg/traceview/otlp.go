@@ -0,0 +1,54 @@
+package traceview
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/johananl/otel-demo/pkg/otlp"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// Export converts spans to an OTLP export request, grouping them by the
+// process which recorded them. The request can be sent to any OTLP receiver
+// or shared as a file.
+func Export(spans []Span) *collectorpb.ExportTraceServiceRequest {
+	req := &collectorpb.ExportTraceServiceRequest{}
+	byResource := map[string]*tracepb.InstrumentationLibrarySpans{}
+	for _, s := range spans {
+		var k strings.Builder
+		for _, kv := range s.Resource {
+			fmt.Fprintf(&k, "%s=%s;", kv.Key, kv.Value.Emit())
+		}
+		ils, ok := byResource[k.String()]
+		if !ok {
+			ils = &tracepb.InstrumentationLibrarySpans{
+				InstrumentationLibrary: &commonpb.InstrumentationLibrary{Name: "otel-demo"},
+			}
+			byResource[k.String()] = ils
+			req.ResourceSpans = append(req.ResourceSpans, &tracepb.ResourceSpans{
+				Resource:                    otlp.Resource(s.Resource),
+				InstrumentationLibrarySpans: []*tracepb.InstrumentationLibrarySpans{ils},
+			})
+		}
+		ils.Spans = append(ils.Spans, otlp.FromSpanData(s.Data))
+	}
+
+	return req
+}
+
+// Import converts the spans of an OTLP export request.
+func Import(req *collectorpb.ExportTraceServiceRequest) []Span {
+	var spans []Span
+	for _, rs := range req.ResourceSpans {
+		res := otlp.ToAttributes(rs.GetResource().GetAttributes())
+		for _, ils := range rs.InstrumentationLibrarySpans {
+			for _, s := range ils.Spans {
+				spans = append(spans, Span{Resource: res, Data: otlp.ToSpanData(s)})
+			}
+		}
+	}
+
+	return spans
+}
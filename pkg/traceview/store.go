@@ -0,0 +1,136 @@
+package traceview
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"go.opentelemetry.io/otel/api/core"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// Store holds spans and answers queries about their traces.
+type Store interface {
+	// Add stores spans recorded by the process described by resource.
+	Add(resource []core.KeyValue, spans []*export.SpanData)
+	// Find returns the summaries of the traces matching q, latest first.
+	Find(q Query) []Summary
+	// Trace returns the spans of a trace or nil if it is unknown.
+	Trace(id core.TraceID) []Span
+}
+
+// Ring is a store which keeps the most recent spans up to a fixed number. The
+// oldest spans are evicted first, so old traces may be incomplete. It is safe
+// for concurrent use.
+type Ring struct {
+	mu     sync.Mutex
+	spans  []Span
+	next   int
+	full   bool
+	traces map[core.TraceID][]Span
+	ids    map[spanKey]bool
+}
+
+// spanKey identifies a span within all traces.
+type spanKey struct {
+	trace core.TraceID
+	span  core.SpanID
+}
+
+var _ Store = (*Ring)(nil)
+
+// NewRing returns a store which keeps up to size spans.
+func NewRing(size int) *Ring {
+	return &Ring{
+		spans:  make([]Span, size),
+		traces: map[core.TraceID][]Span{},
+		ids:    map[spanKey]bool{},
+	}
+}
+
+// Add stores spans, evicting the oldest ones if the ring is full. Spans with
+// the ID of a span already in their trace are dropped.
+func (r *Ring) Add(resource []core.KeyValue, spans []*export.SpanData) {
+	if len(r.spans) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, d := range spans {
+		key := spanKey{d.SpanContext.TraceID, d.SpanContext.SpanID}
+		if r.ids[key] {
+			continue
+		}
+		if r.full {
+			r.evict(r.spans[r.next])
+		}
+		s := Span{Resource: resource, Data: d}
+		r.spans[r.next] = s
+		r.ids[key] = true
+		id := d.SpanContext.TraceID
+		r.traces[id] = append(r.traces[id], s)
+
+		r.next++
+		if r.next == len(r.spans) {
+			r.next, r.full = 0, true
+		}
+	}
+}
+
+// evict drops s, which is the oldest span of its trace.
+func (r *Ring) evict(s Span) {
+	id := s.Data.SpanContext.TraceID
+	delete(r.ids, spanKey{id, s.Data.SpanContext.SpanID})
+	ts := r.traces[id]
+	if len(ts) <= 1 {
+		delete(r.traces, id)
+		return
+	}
+	ts[0] = Span{}
+	r.traces[id] = ts[1:]
+}
+
+// Find returns the summaries of the traces matching q, latest first.
+func (r *Ring) Find(q Query) []Summary {
+	r.mu.Lock()
+	var found []Summary
+	for id, spans := range r.traces {
+		if sum := Summarize(id, spans); q.Match(sum) && q.MatchSpans(spans) {
+			found = append(found, sum)
+		}
+	}
+	r.mu.Unlock()
+
+	sort.Slice(found, func(i, j int) bool { return found[i].Start.After(found[j].Start) })
+	if q.Limit > 0 && len(found) > q.Limit {
+		found = found[:q.Limit]
+	}
+
+	return found
+}
+
+// Trace returns the spans of a trace or nil if it is unknown.
+func (r *Ring) Trace(id core.TraceID) []Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Span(nil), r.traces[id]...)
+}
+
+// syncer adds the spans of a trace provider to a store.
+type syncer struct {
+	store    Store
+	resource []core.KeyValue
+}
+
+// NewSyncer returns an exporter which adds spans to store. resource describes
+// the process which records the spans.
+func NewSyncer(store Store, resource []core.KeyValue) export.SpanSyncer {
+	return &syncer{store: store, resource: resource}
+}
+
+func (s *syncer) ExportSpan(ctx context.Context, d *export.SpanData) {
+	s.store.Add(s.resource, []*export.SpanData{d})
+}
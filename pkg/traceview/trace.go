@@ -0,0 +1,281 @@
+// Package traceview keeps recent spans in memory and serves a page for
+// browsing them, so that traces can be looked at without running Jaeger.
+//
+// Spans enter a Store through a SpanSyncer on the trace provider of a service
+// (see NewSyncer) or, for spans of other processes, through an OTLP receiver
+// (see otlp.NewReceiver). Handler serves the stored traces: a searchable list
+// and a waterfall of every trace.
+package traceview
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johananl/otel-demo/pkg/otlp"
+	"go.opentelemetry.io/otel/api/core"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	"google.golang.org/grpc/codes"
+)
+
+// Span is a span and the resource attributes of the process which recorded
+// it.
+type Span struct {
+	Resource []core.KeyValue
+	Data     *export.SpanData
+}
+
+// Service returns the name of the service which recorded the span.
+func (s Span) Service() string {
+	if name := otlp.ServiceName(s.Resource); name != "" {
+		return name
+	}
+
+	return "unknown"
+}
+
+// Summary describes a trace in search results.
+type Summary struct {
+	ID core.TraceID
+	// Root is the name of the earliest span without a known parent.
+	Root string
+	// Service is the service of the root span.
+	Service  string
+	Start    time.Time
+	Duration time.Duration
+	Spans    int
+	// Errors is the number of spans with a status other than OK.
+	Errors int
+	// Services are the services with spans in the trace, sorted by name.
+	Services []string
+}
+
+// Summarize summarizes the spans of a trace.
+func Summarize(id core.TraceID, spans []Span) Summary {
+	spans, ids := dedupe(spans)
+	sum := Summary{ID: id, Spans: len(spans)}
+
+	var end time.Time
+	services := map[string]bool{}
+	var root *Span
+	for i, s := range spans {
+		d := s.Data
+		if i == 0 || d.StartTime.Before(sum.Start) {
+			sum.Start = d.StartTime
+		}
+		if i == 0 || d.EndTime.After(end) {
+			end = d.EndTime
+		}
+		if d.Status != codes.OK {
+			sum.Errors++
+		}
+		services[s.Service()] = true
+		if !ids[d.ParentSpanID] && (root == nil || d.StartTime.Before(root.Data.StartTime)) {
+			root = &spans[i]
+		}
+	}
+	sum.Duration = end.Sub(sum.Start)
+	if root != nil {
+		sum.Root = root.Data.Name
+		sum.Service = root.Service()
+	}
+	for name := range services {
+		sum.Services = append(sum.Services, name)
+	}
+	sort.Strings(sum.Services)
+
+	return sum
+}
+
+// dedupe returns the spans without those whose span ID was seen before, and
+// the IDs of the spans. Spans sharing an ID could otherwise be linked into a
+// cycle, e.g. by a broken exporter.
+func dedupe(spans []Span) ([]Span, map[core.SpanID]bool) {
+	ids := make(map[core.SpanID]bool, len(spans))
+	unique := make([]Span, 0, len(spans))
+	for _, s := range spans {
+		if id := s.Data.SpanContext.SpanID; !ids[id] {
+			ids[id] = true
+			unique = append(unique, s)
+		}
+	}
+
+	return unique, ids
+}
+
+// Query selects traces. Zero fields match any trace.
+type Query struct {
+	// TraceID matches traces whose hex ID starts with it.
+	TraceID string
+	// Service matches traces with a span of the service.
+	Service string
+	// Operation matches traces with a span of the given name. The name of
+	// the tracer, e.g. "frontend/", may be left out.
+	Operation string
+	// Attributes match traces with spans matching all of them.
+	Attributes []Attribute
+	// MinDuration and MaxDuration bound the duration of the trace.
+	MinDuration time.Duration
+	MaxDuration time.Duration
+	// Errors matches traces with at least one failed span.
+	Errors bool
+	// Limit bounds the number of results.
+	Limit int
+}
+
+// Attribute matches spans with a span or resource attribute with the given
+// key and value. An empty value matches any value.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// ParseAttribute parses an attribute filter of the form "key=value" or
+// "key".
+func ParseAttribute(s string) (Attribute, error) {
+	kv := strings.SplitN(s, "=", 2)
+	a := Attribute{Key: strings.TrimSpace(kv[0])}
+	if a.Key == "" {
+		return Attribute{}, fmt.Errorf("attribute filter %q has no key", s)
+	}
+	if len(kv) == 2 {
+		a.Value = strings.TrimSpace(kv[1])
+	}
+
+	return a, nil
+}
+
+// String returns the attribute filter in the form parsed by ParseAttribute.
+func (a Attribute) String() string {
+	if a.Value == "" {
+		return a.Key
+	}
+
+	return a.Key + "=" + a.Value
+}
+
+// Match reports whether the trace summarized by s matches q.
+func (q Query) Match(s Summary) bool {
+	if q.TraceID != "" && !strings.HasPrefix(hex.EncodeToString(s.ID[:]), strings.ToLower(q.TraceID)) {
+		return false
+	}
+	if q.Service != "" && !contains(s.Services, q.Service) {
+		return false
+	}
+	if q.MinDuration > 0 && s.Duration < q.MinDuration {
+		return false
+	}
+	if q.MaxDuration > 0 && s.Duration > q.MaxDuration {
+		return false
+	}
+	if q.Errors && s.Errors == 0 {
+		return false
+	}
+
+	return true
+}
+
+// MatchSpans reports whether the spans of a trace match the operation and the
+// attributes of q.
+func (q Query) MatchSpans(spans []Span) bool {
+	if q.Operation != "" && !hasOperation(spans, q.Operation) {
+		return false
+	}
+	for _, a := range q.Attributes {
+		if !hasAttribute(spans, a) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasOperation(spans []Span, op string) bool {
+	for _, s := range spans {
+		if s.Data.Name == op || strings.HasSuffix(s.Data.Name, "/"+op) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasAttribute(spans []Span, a Attribute) bool {
+	for _, s := range spans {
+		for _, attrs := range [][]core.KeyValue{s.Data.Attributes, s.Resource} {
+			for _, kv := range attrs {
+				if string(kv.Key) == a.Key && (a.Value == "" || kv.Value.Emit() == a.Value) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Row is a span in the waterfall of a trace.
+type Row struct {
+	Span
+	// Depth is the number of ancestors of the span in the trace.
+	Depth int
+	// Offset is the time between the start of the trace and the start of the
+	// span.
+	Offset time.Duration
+}
+
+// Waterfall orders the spans of a trace depth first, children after their
+// parent and siblings by start time. Spans whose parent is unknown are
+// treated as roots. Of several spans with the same ID only the first is
+// kept.
+func Waterfall(spans []Span) []Row {
+	spans, ids := dedupe(spans)
+	children := map[core.SpanID][]Span{}
+	var roots []Span
+	for _, s := range spans {
+		if ids[s.Data.ParentSpanID] {
+			children[s.Data.ParentSpanID] = append(children[s.Data.ParentSpanID], s)
+		} else {
+			roots = append(roots, s)
+		}
+	}
+
+	byStart := func(ss []Span) {
+		sort.SliceStable(ss, func(i, j int) bool { return ss[i].Data.StartTime.Before(ss[j].Data.StartTime) })
+	}
+	byStart(roots)
+	var start time.Time
+	for i, s := range spans {
+		if i == 0 || s.Data.StartTime.Before(start) {
+			start = s.Data.StartTime
+		}
+	}
+
+	rows := make([]Row, 0, len(spans))
+	var walk func(s Span, depth int)
+	walk = func(s Span, depth int) {
+		rows = append(rows, Row{Span: s, Depth: depth, Offset: s.Data.StartTime.Sub(start)})
+		cs := children[s.Data.SpanContext.SpanID]
+		byStart(cs)
+		for _, c := range cs {
+			walk(c, depth+1)
+		}
+	}
+	for _, r := range roots {
+		walk(r, 0)
+	}
+
+	return rows
+}
@@ -0,0 +1,210 @@
+package traceview
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/trace"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/codes"
+)
+
+// record records a trace of a parent span of frontend and a child span of
+// seniority in ring and returns its ID.
+func record(t *testing.T, ring *Ring, d time.Duration, status codes.Code) core.TraceID {
+	tracer := func(service string) trace.Tracer {
+		tp, err := sdktrace.NewProvider(
+			sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.AlwaysSample()}),
+			sdktrace.WithSyncer(NewSyncer(ring, []core.KeyValue{key.String("service.name", service)})),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tp.Tracer(service)
+	}
+
+	start := time.Now()
+	ctx, parent := tracer("frontend").Start(context.Background(), "serve", trace.WithStartTime(start))
+	_, child := tracer("seniority").Start(ctx, "get-seniority", trace.WithStartTime(start.Add(d/4)))
+	child.SetStatus(status)
+	child.End(trace.WithEndTime(start.Add(d / 2)))
+	parent.End(trace.WithEndTime(start.Add(d)))
+
+	return parent.SpanContext().TraceID
+}
+
+func TestRing(t *testing.T) {
+	ring := NewRing(6)
+	fast := record(t, ring, 10*time.Millisecond, codes.OK)
+	slow := record(t, ring, 300*time.Millisecond, codes.OK)
+	failed := record(t, ring, 20*time.Millisecond, codes.Unavailable)
+
+	for _, tc := range []struct {
+		name string
+		q    Query
+		want []core.TraceID
+	}{
+		{"all", Query{}, []core.TraceID{failed, slow, fast}},
+		{"trace ID", Query{TraceID: hex.EncodeToString(slow[:])[:8]}, []core.TraceID{slow}},
+		{"service", Query{Service: "seniority", Limit: 1}, []core.TraceID{failed}},
+		{"unknown service", Query{Service: "role"}, nil},
+		{"min duration", Query{MinDuration: 100 * time.Millisecond}, []core.TraceID{slow}},
+		{"max duration", Query{MaxDuration: 15 * time.Millisecond}, []core.TraceID{fast}},
+		{"errors", Query{Errors: true}, []core.TraceID{failed}},
+	} {
+		found := ring.Find(tc.q)
+		if len(found) != len(tc.want) {
+			t.Errorf("%s: got %d traces, want %d", tc.name, len(found), len(tc.want))
+			continue
+		}
+		for i, s := range found {
+			if s.ID != tc.want[i] {
+				t.Errorf("%s: got trace %x at %d, want %x", tc.name, s.ID, i, tc.want[i])
+			}
+		}
+	}
+
+	sum := ring.Find(Query{Errors: true})[0]
+	if sum.Root != "frontend/serve" || sum.Service != "frontend" || sum.Spans != 2 || sum.Errors != 1 || sum.Duration != 20*time.Millisecond {
+		t.Errorf("got summary %+v", sum)
+	}
+
+	rows := Waterfall(ring.Trace(slow))
+	if len(rows) != 2 || rows[1].Depth != 1 || rows[1].Offset != 75*time.Millisecond {
+		t.Errorf("got waterfall %+v", rows)
+	}
+
+	// The ring is full, so the spans of the oldest trace are evicted first.
+	record(t, ring, time.Millisecond, codes.OK)
+	if spans := ring.Trace(fast); spans != nil {
+		t.Errorf("got %d spans of the oldest trace, want it evicted", len(spans))
+	}
+	if n := len(ring.Find(Query{})); n != 3 {
+		t.Errorf("got %d traces after eviction, want 3", n)
+	}
+}
+
+func TestDuplicateSpanIDs(t *testing.T) {
+	id := core.TraceID{1}
+	x, y := core.SpanID{1}, core.SpanID{2}
+	start := time.Now()
+	span := func(id core.TraceID, span, parent core.SpanID, name string) *export.SpanData {
+		return &export.SpanData{
+			SpanContext:  core.SpanContext{TraceID: id, SpanID: span},
+			ParentSpanID: parent,
+			Name:         name,
+			StartTime:    start,
+			EndTime:      start.Add(time.Millisecond),
+		}
+	}
+	// The second span X is the child of Y, which is the child of X, so
+	// walking the spans from X would never end.
+	spans := []*export.SpanData{span(id, x, core.SpanID{}, "root"), span(id, y, x, "child"), span(id, x, y, "duplicate")}
+	resource := []core.KeyValue{key.String("service.name", "frontend")}
+
+	rows := Waterfall([]Span{{resource, spans[0]}, {resource, spans[1]}, {resource, spans[2]}})
+	if len(rows) != 2 || rows[0].Data.Name != "root" || rows[1].Data.Name != "child" {
+		t.Errorf("got waterfall %+v, want the root and its child", rows)
+	}
+
+	ring := NewRing(10)
+	ring.Add(resource, spans)
+	ring.Add(resource, spans[:1])
+	if n := len(ring.Trace(id)); n != 2 {
+		t.Errorf("got %d spans, want the duplicates dropped", n)
+	}
+	// Spans with the ID of a span of another trace are kept.
+	ring.Add(resource, []*export.SpanData{span(core.TraceID{2}, x, core.SpanID{}, "root")})
+	if n, m := len(ring.Trace(core.TraceID{2})), len(ring.Trace(id)); n != 1 || m != 2 {
+		t.Errorf("got %d and %d spans, want 1 and 2", n, m)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	ring := NewRing(100)
+	id := record(t, ring, 10*time.Millisecond, codes.Unavailable)
+	hexID := hex.EncodeToString(id[:])
+	h := Handler(ring)
+
+	for _, tc := range []struct {
+		path   string
+		status int
+		want   string
+	}{
+		{Path, http.StatusOK, hexID},
+		{Path + "?service=role", http.StatusOK, "0 traces"},
+		{Path + "?min=1x", http.StatusBadRequest, ""},
+		{Path + "?trace_id=" + hexID, http.StatusFound, ""},
+		{Path + "/" + hexID, http.StatusOK, "seniority: seniority/get-seniority"},
+		{Path + "/" + strings.Repeat("ab", 16), http.StatusNotFound, ""},
+		{Path + "/nothex", http.StatusBadRequest, ""},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", tc.path, nil))
+		if rec.Code != tc.status {
+			t.Errorf("%s: got status %d, want %d", tc.path, rec.Code, tc.status)
+			continue
+		}
+		body := rec.Body.String()
+		if !strings.Contains(body, tc.want) {
+			t.Errorf("%s: body doesn't contain %q", tc.path, tc.want)
+		}
+		if strings.Contains(body, "ZgotmplZ") {
+			t.Errorf("%s: body contains unsafe template output", tc.path)
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	ring := NewRing(100)
+	record(t, ring, 10*time.Millisecond, codes.OK)
+	slow := record(t, ring, 300*time.Millisecond, codes.Unavailable)
+	srv := httptest.NewServer(Handler(ring))
+	defer srv.Close()
+	c := NewClient(srv.URL)
+
+	q := Query{
+		Service:     "seniority",
+		Operation:   "get-seniority",
+		Attributes:  []Attribute{{Key: "service.name", Value: "frontend"}},
+		MinDuration: 100 * time.Millisecond,
+		Errors:      true,
+	}
+	found, err := c.Find(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].ID != slow || found[0].Duration != 300*time.Millisecond {
+		t.Fatalf("got %+v, want the slow trace", found)
+	}
+	q.Attributes = []Attribute{{Key: "service.name", Value: "role"}}
+	if found, err := c.Find(q); err != nil || len(found) != 0 {
+		t.Errorf("got %d traces, %v with an attribute of no span, want none", len(found), err)
+	}
+
+	spans, err := c.Trace(slow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ring.Trace(slow)
+	if len(spans) != len(want) {
+		t.Fatalf("got %d spans, want %d", len(spans), len(want))
+	}
+	for i, s := range spans {
+		if s.Service() != want[i].Service() || s.Data.Name != want[i].Data.Name || s.Data.Status != want[i].Data.Status || !s.Data.EndTime.Equal(want[i].Data.EndTime) {
+			t.Errorf("got span %+v, want %+v", s.Data, want[i].Data)
+		}
+	}
+
+	if spans, err := c.Trace(core.TraceID{1}); err != nil || spans != nil {
+		t.Errorf("got %d spans, %v for an unknown trace, want none", len(spans), err)
+	}
+}
@@ -0,0 +1,102 @@
+package words
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+)
+
+// DefaultLocale is used when no word list exists for the requested locale.
+const DefaultLocale = "en"
+
+// ErrNoMatch is returned when no word matches the requested tags.
+var ErrNoMatch = errors.New("no word matches the requested tags")
+
+// Word is a single selectable word.
+type Word struct {
+	// Text is the word itself.
+	Text string
+	// Weight is the relative probability of the word being selected. Words
+	// with a weight of 0 are never selected.
+	Weight int
+	// Tags describe the word, e.g. "serious", "silly" or "tech".
+	Tags []string
+}
+
+// HasAnyTag returns true if the word carries at least one of the given tags.
+func (w Word) HasAnyTag(tags []string) bool {
+	for _, want := range tags {
+		for _, t := range w.Tags {
+			if strings.EqualFold(t, want) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// List holds word lists keyed by locale.
+type List map[string][]Word
+
+// ForLocale returns the word list for the given locale along with the locale
+// which was actually used. A regional locale such as "de-AT" falls back to its
+// base language and unknown locales fall back to DefaultLocale.
+func (l List) ForLocale(locale string) (string, []Word) {
+	locale = strings.ToLower(locale)
+	if words, ok := l[locale]; ok {
+		return locale, words
+	}
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		if words, ok := l[locale[:i]]; ok {
+			return locale[:i], words
+		}
+	}
+
+	return DefaultLocale, l[DefaultLocale]
+}
+
+// Pick selects a random word honouring the word weights. If tags are given,
+// only words carrying at least one of them are considered. ErrNoMatch is
+// returned if there is nothing to select from.
+func Pick(words []Word, tags []string) (Word, error) {
+	return PickRand(nil, words, tags)
+}
+
+// PickRand works like Pick but draws from the given source of randomness,
+// which allows reproducible selections. A nil rnd uses the global source.
+func PickRand(rnd *rand.Rand, words []Word, tags []string) (Word, error) {
+	candidates := words
+	if len(tags) > 0 {
+		candidates = nil
+		for _, w := range words {
+			if w.HasAnyTag(tags) {
+				candidates = append(candidates, w)
+			}
+		}
+	}
+
+	total := 0
+	for _, w := range candidates {
+		total += w.Weight
+	}
+	if total <= 0 {
+		return Word{}, ErrNoMatch
+	}
+
+	var n int
+	if rnd != nil {
+		n = rnd.Intn(total)
+	} else {
+		n = rand.Intn(total)
+	}
+	for _, w := range candidates {
+		if n < w.Weight {
+			return w, nil
+		}
+		n -= w.Weight
+	}
+
+	// Unreachable as long as the weights don't change while iterating.
+	return candidates[len(candidates)-1], nil
+}
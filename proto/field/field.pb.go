@@ -7,6 +7,7 @@ import (
 	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -25,7 +26,14 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type FieldRequest struct {
-	Slow                 bool     `protobuf:"varint,1,opt,name=slow,proto3" json:"slow,omitempty"`
+	Slow   bool     `protobuf:"varint,1,opt,name=slow,proto3" json:"slow,omitempty"`
+	Locale string   `protobuf:"bytes,2,opt,name=locale,proto3" json:"locale,omitempty"`
+	Tags   []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Seed for the word selection. 0 selects a random word.
+	Seed int64 `protobuf:"varint,4,opt,name=seed,proto3" json:"seed,omitempty"`
+	// Latency profile spec, e.g. "pareto:20ms,1.5". Overrides the service
+	// default profile which is otherwise only applied to slow requests.
+	Latency              string   `protobuf:"bytes,5,opt,name=latency,proto3" json:"latency,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -63,6 +71,34 @@ func (m *FieldRequest) GetSlow() bool {
 	return false
 }
 
+func (m *FieldRequest) GetLocale() string {
+	if m != nil {
+		return m.Locale
+	}
+	return ""
+}
+
+func (m *FieldRequest) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *FieldRequest) GetSeed() int64 {
+	if m != nil {
+		return m.Seed
+	}
+	return 0
+}
+
+func (m *FieldRequest) GetLatency() string {
+	if m != nil {
+		return m.Latency
+	}
+	return ""
+}
+
 type FieldReply struct {
 	Field                string   `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -102,24 +138,219 @@ func (m *FieldReply) GetField() string {
 	return ""
 }
 
+type FieldsRequest struct {
+	Slow   bool     `protobuf:"varint,1,opt,name=slow,proto3" json:"slow,omitempty"`
+	Locale string   `protobuf:"bytes,2,opt,name=locale,proto3" json:"locale,omitempty"`
+	Tags   []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	Count  int32    `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	// Latency profile spec, e.g. "pareto:20ms,1.5". Overrides the service
+	// default profile which is otherwise only applied to slow requests.
+	Latency              string   `protobuf:"bytes,5,opt,name=latency,proto3" json:"latency,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FieldsRequest) Reset()         { *m = FieldsRequest{} }
+func (m *FieldsRequest) String() string { return proto.CompactTextString(m) }
+func (*FieldsRequest) ProtoMessage()    {}
+func (*FieldsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7a9a86c1ff13175e, []int{2}
+}
+
+func (m *FieldsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FieldsRequest.Unmarshal(m, b)
+}
+func (m *FieldsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FieldsRequest.Marshal(b, m, deterministic)
+}
+func (m *FieldsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FieldsRequest.Merge(m, src)
+}
+func (m *FieldsRequest) XXX_Size() int {
+	return xxx_messageInfo_FieldsRequest.Size(m)
+}
+func (m *FieldsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FieldsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FieldsRequest proto.InternalMessageInfo
+
+func (m *FieldsRequest) GetSlow() bool {
+	if m != nil {
+		return m.Slow
+	}
+	return false
+}
+
+func (m *FieldsRequest) GetLocale() string {
+	if m != nil {
+		return m.Locale
+	}
+	return ""
+}
+
+func (m *FieldsRequest) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *FieldsRequest) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *FieldsRequest) GetLatency() string {
+	if m != nil {
+		return m.Latency
+	}
+	return ""
+}
+
+type FieldsReply struct {
+	Fields               []string `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FieldsReply) Reset()         { *m = FieldsReply{} }
+func (m *FieldsReply) String() string { return proto.CompactTextString(m) }
+func (*FieldsReply) ProtoMessage()    {}
+func (*FieldsReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7a9a86c1ff13175e, []int{3}
+}
+
+func (m *FieldsReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FieldsReply.Unmarshal(m, b)
+}
+func (m *FieldsReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FieldsReply.Marshal(b, m, deterministic)
+}
+func (m *FieldsReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FieldsReply.Merge(m, src)
+}
+func (m *FieldsReply) XXX_Size() int {
+	return xxx_messageInfo_FieldsReply.Size(m)
+}
+func (m *FieldsReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_FieldsReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FieldsReply proto.InternalMessageInfo
+
+func (m *FieldsReply) GetFields() []string {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+type FieldStreamRequest struct {
+	Locale string   `protobuf:"bytes,1,opt,name=locale,proto3" json:"locale,omitempty"`
+	Tags   []string `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Delay between two consecutive fields in milliseconds.
+	IntervalMs int32 `protobuf:"varint,3,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"`
+	// Number of fields to send before closing the stream. 0 means unlimited.
+	Count                int32    `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FieldStreamRequest) Reset()         { *m = FieldStreamRequest{} }
+func (m *FieldStreamRequest) String() string { return proto.CompactTextString(m) }
+func (*FieldStreamRequest) ProtoMessage()    {}
+func (*FieldStreamRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7a9a86c1ff13175e, []int{4}
+}
+
+func (m *FieldStreamRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FieldStreamRequest.Unmarshal(m, b)
+}
+func (m *FieldStreamRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FieldStreamRequest.Marshal(b, m, deterministic)
+}
+func (m *FieldStreamRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FieldStreamRequest.Merge(m, src)
+}
+func (m *FieldStreamRequest) XXX_Size() int {
+	return xxx_messageInfo_FieldStreamRequest.Size(m)
+}
+func (m *FieldStreamRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FieldStreamRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FieldStreamRequest proto.InternalMessageInfo
+
+func (m *FieldStreamRequest) GetLocale() string {
+	if m != nil {
+		return m.Locale
+	}
+	return ""
+}
+
+func (m *FieldStreamRequest) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *FieldStreamRequest) GetIntervalMs() int32 {
+	if m != nil {
+		return m.IntervalMs
+	}
+	return 0
+}
+
+func (m *FieldStreamRequest) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*FieldRequest)(nil), "field.FieldRequest")
 	proto.RegisterType((*FieldReply)(nil), "field.FieldReply")
+	proto.RegisterType((*FieldsRequest)(nil), "field.FieldsRequest")
+	proto.RegisterType((*FieldsReply)(nil), "field.FieldsReply")
+	proto.RegisterType((*FieldStreamRequest)(nil), "field.FieldStreamRequest")
 }
 
 func init() { proto.RegisterFile("proto/field/field.proto", fileDescriptor_7a9a86c1ff13175e) }
 
 var fileDescriptor_7a9a86c1ff13175e = []byte{
-	// 131 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x2f, 0x28, 0xca, 0x2f,
-	0xc9, 0xd7, 0x4f, 0xcb, 0x4c, 0xcd, 0x49, 0x81, 0x90, 0x7a, 0x60, 0x11, 0x21, 0x56, 0x30, 0x47,
-	0x49, 0x89, 0x8b, 0xc7, 0x0d, 0xc4, 0x08, 0x4a, 0x2d, 0x2c, 0x4d, 0x2d, 0x2e, 0x11, 0x12, 0xe2,
-	0x62, 0x29, 0xce, 0xc9, 0x2f, 0x97, 0x60, 0x54, 0x60, 0xd4, 0xe0, 0x08, 0x02, 0xb3, 0x95, 0x94,
-	0xb8, 0xb8, 0xa0, 0x6a, 0x0a, 0x72, 0x2a, 0x85, 0x44, 0xb8, 0x20, 0x5a, 0xc1, 0x4a, 0x38, 0x83,
-	0x20, 0x1c, 0x23, 0x5b, 0x2e, 0x56, 0xb0, 0x1a, 0x21, 0x13, 0x2e, 0x0e, 0xf7, 0xd4, 0x12, 0x08,
-	0x5b, 0x58, 0x0f, 0x62, 0x23, 0xb2, 0x0d, 0x52, 0x82, 0xa8, 0x82, 0x05, 0x39, 0x95, 0x4a, 0x0c,
-	0x49, 0x6c, 0x60, 0x47, 0x19, 0x03, 0x02, 0x00, 0x00, 0xff, 0xff, 0xdb, 0xf7, 0x73, 0xf9, 0xaf,
-	0x00, 0x00, 0x00,
+	// 371 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x92, 0xcf, 0x4a, 0xeb, 0x40,
+	0x14, 0xc6, 0x99, 0xb6, 0xe9, 0x6d, 0x4e, 0x7b, 0x17, 0x3d, 0x2d, 0xbd, 0x69, 0xb9, 0x60, 0x08,
+	0x08, 0x59, 0x35, 0xfe, 0x79, 0x06, 0x15, 0x17, 0x6e, 0xe2, 0xca, 0x95, 0x8c, 0xed, 0x58, 0x02,
+	0xd3, 0x4c, 0xec, 0x4c, 0x5b, 0x0a, 0x82, 0xe0, 0x2b, 0xf8, 0x68, 0xbe, 0x82, 0x8f, 0xe0, 0x03,
+	0x48, 0xce, 0x24, 0x36, 0x95, 0xea, 0xca, 0x4d, 0x38, 0xdf, 0xe1, 0x3b, 0xdf, 0xf9, 0x91, 0x39,
+	0xf0, 0x2f, 0x5b, 0x28, 0xa3, 0xa2, 0xfb, 0x44, 0xc8, 0xa9, 0xfd, 0x8e, 0xa9, 0x83, 0x0e, 0x89,
+	0xd1, 0xff, 0x99, 0x52, 0x33, 0x29, 0x22, 0x9e, 0x25, 0x11, 0x4f, 0x53, 0x65, 0xb8, 0x49, 0x54,
+	0xaa, 0xad, 0x29, 0x78, 0x84, 0xce, 0x79, 0x6e, 0x8b, 0xc5, 0xc3, 0x52, 0x68, 0x83, 0x08, 0x0d,
+	0x2d, 0xd5, 0xda, 0x63, 0x3e, 0x0b, 0x5b, 0x31, 0xd5, 0x38, 0x80, 0xa6, 0x54, 0x13, 0x2e, 0x85,
+	0x57, 0xf3, 0x59, 0xe8, 0xc6, 0x85, 0xca, 0xbd, 0x86, 0xcf, 0xb4, 0x57, 0xf7, 0xeb, 0xa1, 0x1b,
+	0x53, 0x4d, 0xf3, 0x42, 0x4c, 0xbd, 0x86, 0xcf, 0xc2, 0x7a, 0x4c, 0x35, 0x7a, 0xf0, 0x47, 0x72,
+	0x23, 0xd2, 0xc9, 0xc6, 0x73, 0x28, 0xa0, 0x94, 0x41, 0x00, 0x50, 0x6c, 0xcf, 0xe4, 0x06, 0xfb,
+	0x60, 0x91, 0x69, 0xb9, 0x1b, 0x5b, 0x11, 0x3c, 0xc1, 0x5f, 0xf2, 0xe8, 0xdf, 0x42, 0xec, 0x83,
+	0x33, 0x51, 0xcb, 0xd4, 0x10, 0xa3, 0x13, 0x5b, 0xf1, 0x03, 0xe4, 0x21, 0xb4, 0x4b, 0x80, 0x9c,
+	0x72, 0x00, 0x4d, 0x02, 0xd3, 0x1e, 0xa3, 0xd0, 0x42, 0x05, 0x6b, 0x40, 0xb2, 0x5d, 0x9b, 0x85,
+	0xe0, 0xf3, 0x12, 0x76, 0x0b, 0xc6, 0xf6, 0x82, 0xd5, 0x2a, 0x60, 0x07, 0xd0, 0x4e, 0x52, 0x23,
+	0x16, 0x2b, 0x2e, 0x6f, 0xe7, 0x39, 0x73, 0x8e, 0x07, 0x65, 0xeb, 0xea, 0x1b, 0xf2, 0x93, 0x77,
+	0x06, 0x0e, 0x6d, 0xc6, 0x33, 0x68, 0x5d, 0x08, 0x63, 0xeb, 0xde, 0xd8, 0xde, 0x42, 0xf5, 0x75,
+	0x47, 0xdd, 0xdd, 0x66, 0x26, 0x37, 0x41, 0xf7, 0xf9, 0xf5, 0xed, 0xa5, 0xd6, 0x46, 0x37, 0x5a,
+	0x1d, 0xdb, 0xf3, 0xc1, 0x4b, 0x70, 0xcb, 0x18, 0x8d, 0xfd, 0xea, 0x48, 0xf9, 0x06, 0x23, 0xfc,
+	0xd2, 0xcd, 0x93, 0x90, 0x92, 0x3a, 0x08, 0x9f, 0x49, 0x1a, 0x6f, 0xa0, 0x63, 0xff, 0x47, 0x91,
+	0x36, 0xac, 0xce, 0xed, 0xfc, 0xa9, 0x7d, 0x6c, 0x43, 0x4a, 0xec, 0x61, 0x77, 0x9b, 0x18, 0x69,
+	0x1a, 0x3a, 0x62, 0x77, 0x4d, 0x3a, 0xe0, 0xd3, 0x8f, 0x01, 0x00, 0xd1, 0xe6, 0xfd, 0x04, 0x00,
+	0x03, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -135,6 +366,8 @@ const _ = grpc.SupportPackageIsVersion4
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type FieldClient interface {
 	GetField(ctx context.Context, in *FieldRequest, opts ...grpc.CallOption) (*FieldReply, error)
+	GetFields(ctx context.Context, in *FieldsRequest, opts ...grpc.CallOption) (*FieldsReply, error)
+	StreamFields(ctx context.Context, in *FieldStreamRequest, opts ...grpc.CallOption) (Field_StreamFieldsClient, error)
 }
 
 type fieldClient struct {
@@ -154,9 +387,52 @@ func (c *fieldClient) GetField(ctx context.Context, in *FieldRequest, opts ...gr
 	return out, nil
 }
 
+func (c *fieldClient) GetFields(ctx context.Context, in *FieldsRequest, opts ...grpc.CallOption) (*FieldsReply, error) {
+	out := new(FieldsReply)
+	err := c.cc.Invoke(ctx, "/field.Field/GetFields", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fieldClient) StreamFields(ctx context.Context, in *FieldStreamRequest, opts ...grpc.CallOption) (Field_StreamFieldsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Field_serviceDesc.Streams[0], "/field.Field/StreamFields", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &fieldStreamFieldsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Field_StreamFieldsClient interface {
+	Recv() (*FieldReply, error)
+	grpc.ClientStream
+}
+
+type fieldStreamFieldsClient struct {
+	grpc.ClientStream
+}
+
+func (x *fieldStreamFieldsClient) Recv() (*FieldReply, error) {
+	m := new(FieldReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // FieldServer is the server API for Field service.
 type FieldServer interface {
 	GetField(context.Context, *FieldRequest) (*FieldReply, error)
+	GetFields(context.Context, *FieldsRequest) (*FieldsReply, error)
+	StreamFields(*FieldStreamRequest, Field_StreamFieldsServer) error
 }
 
 // UnimplementedFieldServer can be embedded to have forward compatible implementations.
@@ -166,6 +442,12 @@ type UnimplementedFieldServer struct {
 func (*UnimplementedFieldServer) GetField(ctx context.Context, req *FieldRequest) (*FieldReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetField not implemented")
 }
+func (*UnimplementedFieldServer) GetFields(ctx context.Context, req *FieldsRequest) (*FieldsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFields not implemented")
+}
+func (*UnimplementedFieldServer) StreamFields(req *FieldStreamRequest, srv Field_StreamFieldsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamFields not implemented")
+}
 
 func RegisterFieldServer(s *grpc.Server, srv FieldServer) {
 	s.RegisterService(&_Field_serviceDesc, srv)
@@ -189,6 +471,45 @@ func _Field_GetField_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Field_GetFields_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FieldsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FieldServer).GetFields(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/field.Field/GetFields",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FieldServer).GetFields(ctx, req.(*FieldsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Field_StreamFields_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FieldStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FieldServer).StreamFields(m, &fieldStreamFieldsServer{stream})
+}
+
+type Field_StreamFieldsServer interface {
+	Send(*FieldReply) error
+	grpc.ServerStream
+}
+
+type fieldStreamFieldsServer struct {
+	grpc.ServerStream
+}
+
+func (x *fieldStreamFieldsServer) Send(m *FieldReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Field_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "field.Field",
 	HandlerType: (*FieldServer)(nil),
@@ -197,7 +518,17 @@ var _Field_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetField",
 			Handler:    _Field_GetField_Handler,
 		},
+		{
+			MethodName: "GetFields",
+			Handler:    _Field_GetFields_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamFields",
+			Handler:       _Field_StreamFields_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/field/field.proto",
 }
@@ -7,6 +7,7 @@ import (
 	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -25,7 +26,14 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type RoleRequest struct {
-	Slow                 bool     `protobuf:"varint,1,opt,name=slow,proto3" json:"slow,omitempty"`
+	Slow   bool     `protobuf:"varint,1,opt,name=slow,proto3" json:"slow,omitempty"`
+	Locale string   `protobuf:"bytes,2,opt,name=locale,proto3" json:"locale,omitempty"`
+	Tags   []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Seed for the word selection. 0 selects a random word.
+	Seed int64 `protobuf:"varint,4,opt,name=seed,proto3" json:"seed,omitempty"`
+	// Latency profile spec, e.g. "pareto:20ms,1.5". Overrides the service
+	// default profile which is otherwise only applied to slow requests.
+	Latency              string   `protobuf:"bytes,5,opt,name=latency,proto3" json:"latency,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -63,6 +71,34 @@ func (m *RoleRequest) GetSlow() bool {
 	return false
 }
 
+func (m *RoleRequest) GetLocale() string {
+	if m != nil {
+		return m.Locale
+	}
+	return ""
+}
+
+func (m *RoleRequest) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *RoleRequest) GetSeed() int64 {
+	if m != nil {
+		return m.Seed
+	}
+	return 0
+}
+
+func (m *RoleRequest) GetLatency() string {
+	if m != nil {
+		return m.Latency
+	}
+	return ""
+}
+
 type RoleReply struct {
 	Role                 string   `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -102,24 +138,218 @@ func (m *RoleReply) GetRole() string {
 	return ""
 }
 
+type RolesRequest struct {
+	Slow   bool     `protobuf:"varint,1,opt,name=slow,proto3" json:"slow,omitempty"`
+	Locale string   `protobuf:"bytes,2,opt,name=locale,proto3" json:"locale,omitempty"`
+	Tags   []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	Count  int32    `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	// Latency profile spec, e.g. "pareto:20ms,1.5". Overrides the service
+	// default profile which is otherwise only applied to slow requests.
+	Latency              string   `protobuf:"bytes,5,opt,name=latency,proto3" json:"latency,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RolesRequest) Reset()         { *m = RolesRequest{} }
+func (m *RolesRequest) String() string { return proto.CompactTextString(m) }
+func (*RolesRequest) ProtoMessage()    {}
+func (*RolesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26e011caf756e89c, []int{2}
+}
+
+func (m *RolesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RolesRequest.Unmarshal(m, b)
+}
+func (m *RolesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RolesRequest.Marshal(b, m, deterministic)
+}
+func (m *RolesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RolesRequest.Merge(m, src)
+}
+func (m *RolesRequest) XXX_Size() int {
+	return xxx_messageInfo_RolesRequest.Size(m)
+}
+func (m *RolesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RolesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RolesRequest proto.InternalMessageInfo
+
+func (m *RolesRequest) GetSlow() bool {
+	if m != nil {
+		return m.Slow
+	}
+	return false
+}
+
+func (m *RolesRequest) GetLocale() string {
+	if m != nil {
+		return m.Locale
+	}
+	return ""
+}
+
+func (m *RolesRequest) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *RolesRequest) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *RolesRequest) GetLatency() string {
+	if m != nil {
+		return m.Latency
+	}
+	return ""
+}
+
+type RolesReply struct {
+	Roles                []string `protobuf:"bytes,1,rep,name=roles,proto3" json:"roles,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RolesReply) Reset()         { *m = RolesReply{} }
+func (m *RolesReply) String() string { return proto.CompactTextString(m) }
+func (*RolesReply) ProtoMessage()    {}
+func (*RolesReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26e011caf756e89c, []int{3}
+}
+
+func (m *RolesReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RolesReply.Unmarshal(m, b)
+}
+func (m *RolesReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RolesReply.Marshal(b, m, deterministic)
+}
+func (m *RolesReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RolesReply.Merge(m, src)
+}
+func (m *RolesReply) XXX_Size() int {
+	return xxx_messageInfo_RolesReply.Size(m)
+}
+func (m *RolesReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_RolesReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RolesReply proto.InternalMessageInfo
+
+func (m *RolesReply) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+type RoleStreamRequest struct {
+	Locale string   `protobuf:"bytes,1,opt,name=locale,proto3" json:"locale,omitempty"`
+	Tags   []string `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Delay between two consecutive roles in milliseconds.
+	IntervalMs int32 `protobuf:"varint,3,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"`
+	// Number of roles to send before closing the stream. 0 means unlimited.
+	Count                int32    `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RoleStreamRequest) Reset()         { *m = RoleStreamRequest{} }
+func (m *RoleStreamRequest) String() string { return proto.CompactTextString(m) }
+func (*RoleStreamRequest) ProtoMessage()    {}
+func (*RoleStreamRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26e011caf756e89c, []int{4}
+}
+
+func (m *RoleStreamRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RoleStreamRequest.Unmarshal(m, b)
+}
+func (m *RoleStreamRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RoleStreamRequest.Marshal(b, m, deterministic)
+}
+func (m *RoleStreamRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RoleStreamRequest.Merge(m, src)
+}
+func (m *RoleStreamRequest) XXX_Size() int {
+	return xxx_messageInfo_RoleStreamRequest.Size(m)
+}
+func (m *RoleStreamRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RoleStreamRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RoleStreamRequest proto.InternalMessageInfo
+
+func (m *RoleStreamRequest) GetLocale() string {
+	if m != nil {
+		return m.Locale
+	}
+	return ""
+}
+
+func (m *RoleStreamRequest) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *RoleStreamRequest) GetIntervalMs() int32 {
+	if m != nil {
+		return m.IntervalMs
+	}
+	return 0
+}
+
+func (m *RoleStreamRequest) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*RoleRequest)(nil), "role.RoleRequest")
 	proto.RegisterType((*RoleReply)(nil), "role.RoleReply")
+	proto.RegisterType((*RolesRequest)(nil), "role.RolesRequest")
+	proto.RegisterType((*RolesReply)(nil), "role.RolesReply")
+	proto.RegisterType((*RoleStreamRequest)(nil), "role.RoleStreamRequest")
 }
 
 func init() { proto.RegisterFile("proto/role/role.proto", fileDescriptor_26e011caf756e89c) }
 
 var fileDescriptor_26e011caf756e89c = []byte{
-	// 130 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x2d, 0x28, 0xca, 0x2f,
-	0xc9, 0xd7, 0x2f, 0xca, 0xcf, 0x49, 0x05, 0x13, 0x7a, 0x60, 0xbe, 0x10, 0x0b, 0x88, 0xad, 0xa4,
-	0xc8, 0xc5, 0x1d, 0x94, 0x9f, 0x93, 0x1a, 0x94, 0x5a, 0x58, 0x9a, 0x5a, 0x5c, 0x22, 0x24, 0xc4,
-	0xc5, 0x52, 0x9c, 0x93, 0x5f, 0x2e, 0xc1, 0xa8, 0xc0, 0xa8, 0xc1, 0x11, 0x04, 0x66, 0x2b, 0xc9,
-	0x73, 0x71, 0x42, 0x94, 0x14, 0xe4, 0x54, 0x82, 0x14, 0x80, 0xf4, 0x81, 0x15, 0x70, 0x06, 0x81,
-	0xd9, 0x46, 0xe6, 0x5c, 0x2c, 0x20, 0x05, 0x42, 0xfa, 0x5c, 0xec, 0xee, 0xa9, 0x25, 0x60, 0xa6,
-	0xa0, 0x1e, 0xd8, 0x26, 0x24, 0xa3, 0xa5, 0xf8, 0x91, 0x85, 0x0a, 0x72, 0x2a, 0x95, 0x18, 0x92,
-	0xd8, 0xc0, 0x2e, 0x31, 0x06, 0x04, 0x00, 0x00, 0xff, 0xff, 0xc7, 0x86, 0xc0, 0xd0, 0xa2, 0x00,
-	0x00, 0x00,
+	// 366 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x92, 0x4d, 0x6a, 0xe3, 0x40,
+	0x10, 0x85, 0x69, 0x5b, 0xb2, 0xad, 0xd2, 0xc0, 0xd8, 0x85, 0x67, 0xa6, 0x31, 0x03, 0x16, 0x5a,
+	0x69, 0x65, 0xcd, 0xcf, 0x01, 0xb2, 0xc8, 0x22, 0xab, 0x6c, 0xda, 0x07, 0x08, 0x1d, 0xa7, 0x31,
+	0x86, 0xb6, 0x5a, 0x51, 0xb7, 0x1d, 0x4c, 0x20, 0x8b, 0x5c, 0x21, 0x47, 0xcb, 0x09, 0x02, 0x39,
+	0x48, 0xe8, 0x92, 0x14, 0xdb, 0xe4, 0x67, 0x95, 0x8d, 0x78, 0xaf, 0xa8, 0x7a, 0xf5, 0x51, 0x6a,
+	0xf8, 0x51, 0x56, 0xc6, 0x99, 0xbc, 0x32, 0x5a, 0xd1, 0x67, 0x46, 0x1e, 0x03, 0xaf, 0x27, 0xbf,
+	0x97, 0xc6, 0x2c, 0xb5, 0xca, 0x65, 0xb9, 0xca, 0x65, 0x51, 0x18, 0x27, 0xdd, 0xca, 0x14, 0xb6,
+	0xee, 0x49, 0x6f, 0x21, 0x16, 0x46, 0x2b, 0xa1, 0xae, 0x37, 0xca, 0x3a, 0x44, 0x08, 0xac, 0x36,
+	0x37, 0x9c, 0x25, 0x2c, 0x1b, 0x08, 0xd2, 0xf8, 0x13, 0x7a, 0xda, 0x2c, 0xa4, 0x56, 0xbc, 0x93,
+	0xb0, 0x2c, 0x12, 0x8d, 0xf3, 0xbd, 0x4e, 0x2e, 0x2d, 0xef, 0x26, 0xdd, 0x2c, 0x12, 0xa4, 0x69,
+	0x5e, 0xa9, 0x2b, 0x1e, 0x24, 0x2c, 0xeb, 0x0a, 0xd2, 0xc8, 0xa1, 0xaf, 0xa5, 0x53, 0xc5, 0x62,
+	0xc7, 0x43, 0x0a, 0x68, 0x6d, 0x3a, 0x85, 0xa8, 0x5e, 0x5e, 0xea, 0x9d, 0x1f, 0xf5, 0xbc, 0xb4,
+	0x3a, 0x12, 0xa4, 0xd3, 0x3b, 0xf8, 0xe6, 0x1b, 0xec, 0x57, 0xe1, 0x8d, 0x21, 0x5c, 0x98, 0x4d,
+	0xe1, 0x88, 0x2f, 0x14, 0xb5, 0xf9, 0x04, 0x30, 0x05, 0x68, 0xf6, 0x7b, 0xc2, 0x31, 0x84, 0x9e,
+	0xca, 0x72, 0x46, 0x91, 0xb5, 0x49, 0xb7, 0x30, 0xf2, 0x3d, 0x73, 0x57, 0x29, 0xb9, 0x6e, 0x41,
+	0xf7, 0x50, 0xec, 0x5d, 0xa8, 0xce, 0x01, 0xd4, 0x14, 0xe2, 0x55, 0xe1, 0x54, 0xb5, 0x95, 0xfa,
+	0x62, 0xed, 0x79, 0x3d, 0x1a, 0xb4, 0xa5, 0xf3, 0x0f, 0xa8, 0xff, 0x3d, 0x31, 0x08, 0xfc, 0x62,
+	0x3c, 0x81, 0xfe, 0x99, 0x72, 0x24, 0x47, 0x33, 0xfa, 0xfd, 0x07, 0x7f, 0x74, 0xf2, 0xfd, 0xb0,
+	0x54, 0xea, 0x5d, 0x3a, 0xbc, 0x7f, 0x7c, 0x7e, 0xe8, 0x00, 0x0e, 0xf2, 0xed, 0x5f, 0x7a, 0x2d,
+	0x78, 0x0a, 0x83, 0x26, 0xc0, 0x22, 0xee, 0xdb, 0xdb, 0xab, 0x4f, 0x86, 0x47, 0x35, 0x9f, 0x31,
+	0xa2, 0x8c, 0x18, 0xa3, 0x36, 0xc3, 0xe2, 0x1c, 0xe2, 0xe6, 0x04, 0x64, 0x7f, 0xed, 0x67, 0x8e,
+	0x2e, 0xf3, 0x96, 0x87, 0x53, 0x16, 0xe2, 0xf0, 0x35, 0x2b, 0xb7, 0x34, 0xf1, 0x87, 0x5d, 0xf6,
+	0xe8, 0x91, 0xfe, 0x7f, 0x19, 0x00, 0x61, 0x00, 0x3a, 0x64, 0xe1, 0x02, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -135,6 +365,8 @@ const _ = grpc.SupportPackageIsVersion4
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type RoleClient interface {
 	GetRole(ctx context.Context, in *RoleRequest, opts ...grpc.CallOption) (*RoleReply, error)
+	GetRoles(ctx context.Context, in *RolesRequest, opts ...grpc.CallOption) (*RolesReply, error)
+	StreamRoles(ctx context.Context, in *RoleStreamRequest, opts ...grpc.CallOption) (Role_StreamRolesClient, error)
 }
 
 type roleClient struct {
@@ -154,9 +386,52 @@ func (c *roleClient) GetRole(ctx context.Context, in *RoleRequest, opts ...grpc.
 	return out, nil
 }
 
+func (c *roleClient) GetRoles(ctx context.Context, in *RolesRequest, opts ...grpc.CallOption) (*RolesReply, error) {
+	out := new(RolesReply)
+	err := c.cc.Invoke(ctx, "/role.Role/GetRoles", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *roleClient) StreamRoles(ctx context.Context, in *RoleStreamRequest, opts ...grpc.CallOption) (Role_StreamRolesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Role_serviceDesc.Streams[0], "/role.Role/StreamRoles", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &roleStreamRolesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Role_StreamRolesClient interface {
+	Recv() (*RoleReply, error)
+	grpc.ClientStream
+}
+
+type roleStreamRolesClient struct {
+	grpc.ClientStream
+}
+
+func (x *roleStreamRolesClient) Recv() (*RoleReply, error) {
+	m := new(RoleReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // RoleServer is the server API for Role service.
 type RoleServer interface {
 	GetRole(context.Context, *RoleRequest) (*RoleReply, error)
+	GetRoles(context.Context, *RolesRequest) (*RolesReply, error)
+	StreamRoles(*RoleStreamRequest, Role_StreamRolesServer) error
 }
 
 // UnimplementedRoleServer can be embedded to have forward compatible implementations.
@@ -166,6 +441,12 @@ type UnimplementedRoleServer struct {
 func (*UnimplementedRoleServer) GetRole(ctx context.Context, req *RoleRequest) (*RoleReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetRole not implemented")
 }
+func (*UnimplementedRoleServer) GetRoles(ctx context.Context, req *RolesRequest) (*RolesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRoles not implemented")
+}
+func (*UnimplementedRoleServer) StreamRoles(req *RoleStreamRequest, srv Role_StreamRolesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamRoles not implemented")
+}
 
 func RegisterRoleServer(s *grpc.Server, srv RoleServer) {
 	s.RegisterService(&_Role_serviceDesc, srv)
@@ -189,6 +470,45 @@ func _Role_GetRole_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Role_GetRoles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RolesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RoleServer).GetRoles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/role.Role/GetRoles",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RoleServer).GetRoles(ctx, req.(*RolesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Role_StreamRoles_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RoleStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RoleServer).StreamRoles(m, &roleStreamRolesServer{stream})
+}
+
+type Role_StreamRolesServer interface {
+	Send(*RoleReply) error
+	grpc.ServerStream
+}
+
+type roleStreamRolesServer struct {
+	grpc.ServerStream
+}
+
+func (x *roleStreamRolesServer) Send(m *RoleReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Role_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "role.Role",
 	HandlerType: (*RoleServer)(nil),
@@ -197,7 +517,17 @@ var _Role_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetRole",
 			Handler:    _Role_GetRole_Handler,
 		},
+		{
+			MethodName: "GetRoles",
+			Handler:    _Role_GetRoles_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamRoles",
+			Handler:       _Role_StreamRoles_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/role/role.proto",
 }
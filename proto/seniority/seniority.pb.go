@@ -7,6 +7,7 @@ import (
 	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -25,7 +26,14 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type SeniorityRequest struct {
-	Slow                 bool     `protobuf:"varint,1,opt,name=slow,proto3" json:"slow,omitempty"`
+	Slow   bool     `protobuf:"varint,1,opt,name=slow,proto3" json:"slow,omitempty"`
+	Locale string   `protobuf:"bytes,2,opt,name=locale,proto3" json:"locale,omitempty"`
+	Tags   []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Seed for the word selection. 0 selects a random word.
+	Seed int64 `protobuf:"varint,4,opt,name=seed,proto3" json:"seed,omitempty"`
+	// Latency profile spec, e.g. "pareto:20ms,1.5". Overrides the service
+	// default profile which is otherwise only applied to slow requests.
+	Latency              string   `protobuf:"bytes,5,opt,name=latency,proto3" json:"latency,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -63,6 +71,34 @@ func (m *SeniorityRequest) GetSlow() bool {
 	return false
 }
 
+func (m *SeniorityRequest) GetLocale() string {
+	if m != nil {
+		return m.Locale
+	}
+	return ""
+}
+
+func (m *SeniorityRequest) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *SeniorityRequest) GetSeed() int64 {
+	if m != nil {
+		return m.Seed
+	}
+	return 0
+}
+
+func (m *SeniorityRequest) GetLatency() string {
+	if m != nil {
+		return m.Latency
+	}
+	return ""
+}
+
 type SeniorityReply struct {
 	Seniority            string   `protobuf:"bytes,1,opt,name=seniority,proto3" json:"seniority,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -102,24 +138,219 @@ func (m *SeniorityReply) GetSeniority() string {
 	return ""
 }
 
+type SenioritiesRequest struct {
+	Slow   bool     `protobuf:"varint,1,opt,name=slow,proto3" json:"slow,omitempty"`
+	Locale string   `protobuf:"bytes,2,opt,name=locale,proto3" json:"locale,omitempty"`
+	Tags   []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	Count  int32    `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	// Latency profile spec, e.g. "pareto:20ms,1.5". Overrides the service
+	// default profile which is otherwise only applied to slow requests.
+	Latency              string   `protobuf:"bytes,5,opt,name=latency,proto3" json:"latency,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SenioritiesRequest) Reset()         { *m = SenioritiesRequest{} }
+func (m *SenioritiesRequest) String() string { return proto.CompactTextString(m) }
+func (*SenioritiesRequest) ProtoMessage()    {}
+func (*SenioritiesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_487578669ad9a9c0, []int{2}
+}
+
+func (m *SenioritiesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SenioritiesRequest.Unmarshal(m, b)
+}
+func (m *SenioritiesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SenioritiesRequest.Marshal(b, m, deterministic)
+}
+func (m *SenioritiesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SenioritiesRequest.Merge(m, src)
+}
+func (m *SenioritiesRequest) XXX_Size() int {
+	return xxx_messageInfo_SenioritiesRequest.Size(m)
+}
+func (m *SenioritiesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SenioritiesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SenioritiesRequest proto.InternalMessageInfo
+
+func (m *SenioritiesRequest) GetSlow() bool {
+	if m != nil {
+		return m.Slow
+	}
+	return false
+}
+
+func (m *SenioritiesRequest) GetLocale() string {
+	if m != nil {
+		return m.Locale
+	}
+	return ""
+}
+
+func (m *SenioritiesRequest) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *SenioritiesRequest) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *SenioritiesRequest) GetLatency() string {
+	if m != nil {
+		return m.Latency
+	}
+	return ""
+}
+
+type SenioritiesReply struct {
+	Seniorities          []string `protobuf:"bytes,1,rep,name=seniorities,proto3" json:"seniorities,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SenioritiesReply) Reset()         { *m = SenioritiesReply{} }
+func (m *SenioritiesReply) String() string { return proto.CompactTextString(m) }
+func (*SenioritiesReply) ProtoMessage()    {}
+func (*SenioritiesReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_487578669ad9a9c0, []int{3}
+}
+
+func (m *SenioritiesReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SenioritiesReply.Unmarshal(m, b)
+}
+func (m *SenioritiesReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SenioritiesReply.Marshal(b, m, deterministic)
+}
+func (m *SenioritiesReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SenioritiesReply.Merge(m, src)
+}
+func (m *SenioritiesReply) XXX_Size() int {
+	return xxx_messageInfo_SenioritiesReply.Size(m)
+}
+func (m *SenioritiesReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_SenioritiesReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SenioritiesReply proto.InternalMessageInfo
+
+func (m *SenioritiesReply) GetSeniorities() []string {
+	if m != nil {
+		return m.Seniorities
+	}
+	return nil
+}
+
+type SeniorityStreamRequest struct {
+	Locale string   `protobuf:"bytes,1,opt,name=locale,proto3" json:"locale,omitempty"`
+	Tags   []string `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Delay between two consecutive seniorities in milliseconds.
+	IntervalMs int32 `protobuf:"varint,3,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"`
+	// Number of seniorities to send before closing the stream. 0 means unlimited.
+	Count                int32    `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SeniorityStreamRequest) Reset()         { *m = SeniorityStreamRequest{} }
+func (m *SeniorityStreamRequest) String() string { return proto.CompactTextString(m) }
+func (*SeniorityStreamRequest) ProtoMessage()    {}
+func (*SeniorityStreamRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_487578669ad9a9c0, []int{4}
+}
+
+func (m *SeniorityStreamRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SeniorityStreamRequest.Unmarshal(m, b)
+}
+func (m *SeniorityStreamRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SeniorityStreamRequest.Marshal(b, m, deterministic)
+}
+func (m *SeniorityStreamRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SeniorityStreamRequest.Merge(m, src)
+}
+func (m *SeniorityStreamRequest) XXX_Size() int {
+	return xxx_messageInfo_SeniorityStreamRequest.Size(m)
+}
+func (m *SeniorityStreamRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SeniorityStreamRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SeniorityStreamRequest proto.InternalMessageInfo
+
+func (m *SeniorityStreamRequest) GetLocale() string {
+	if m != nil {
+		return m.Locale
+	}
+	return ""
+}
+
+func (m *SeniorityStreamRequest) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *SeniorityStreamRequest) GetIntervalMs() int32 {
+	if m != nil {
+		return m.IntervalMs
+	}
+	return 0
+}
+
+func (m *SeniorityStreamRequest) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*SeniorityRequest)(nil), "seniority.SeniorityRequest")
 	proto.RegisterType((*SeniorityReply)(nil), "seniority.SeniorityReply")
+	proto.RegisterType((*SenioritiesRequest)(nil), "seniority.SenioritiesRequest")
+	proto.RegisterType((*SenioritiesReply)(nil), "seniority.SenioritiesReply")
+	proto.RegisterType((*SeniorityStreamRequest)(nil), "seniority.SeniorityStreamRequest")
 }
 
 func init() { proto.RegisterFile("proto/seniority/seniority.proto", fileDescriptor_487578669ad9a9c0) }
 
 var fileDescriptor_487578669ad9a9c0 = []byte{
-	// 136 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x92, 0x2f, 0x28, 0xca, 0x2f,
-	0xc9, 0xd7, 0x2f, 0x4e, 0xcd, 0xcb, 0xcc, 0x2f, 0xca, 0x2c, 0xa9, 0x44, 0xb0, 0xf4, 0xc0, 0x32,
-	0x42, 0x9c, 0x70, 0x01, 0x25, 0x35, 0x2e, 0x81, 0x60, 0x18, 0x27, 0x28, 0xb5, 0xb0, 0x34, 0xb5,
-	0xb8, 0x44, 0x48, 0x88, 0x8b, 0xa5, 0x38, 0x27, 0xbf, 0x5c, 0x82, 0x51, 0x81, 0x51, 0x83, 0x23,
-	0x08, 0xcc, 0x56, 0xd2, 0xe3, 0xe2, 0x43, 0x52, 0x57, 0x90, 0x53, 0x29, 0x24, 0xc3, 0x85, 0x30,
-	0x06, 0xac, 0x94, 0x33, 0x08, 0x21, 0x60, 0x14, 0xca, 0xc5, 0x09, 0x57, 0x2f, 0xe4, 0xc1, 0xc5,
-	0xe3, 0x9e, 0x5a, 0x82, 0xe0, 0x4b, 0xeb, 0x21, 0x5c, 0x84, 0x6e, 0xbb, 0x94, 0x24, 0x76, 0xc9,
-	0x82, 0x9c, 0x4a, 0x25, 0x86, 0x24, 0x36, 0xb0, 0x07, 0x8c, 0x01, 0x01, 0x00, 0x00, 0xff, 0xff,
-	0x02, 0xb7, 0x43, 0xcc, 0xe3, 0x00, 0x00, 0x00,
+	// 382 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x93, 0x4b, 0x4e, 0xf3, 0x30,
+	0x10, 0xc7, 0xe5, 0xf4, 0xf1, 0x7d, 0x99, 0x42, 0x1f, 0x16, 0x14, 0xd3, 0x16, 0x1a, 0xb2, 0xca,
+	0xaa, 0xe1, 0x75, 0x07, 0x56, 0x6c, 0xd2, 0x35, 0x42, 0xa6, 0x58, 0x55, 0x24, 0x37, 0x0e, 0xb1,
+	0x5b, 0x14, 0xb1, 0x41, 0x88, 0x1b, 0x70, 0x27, 0x2e, 0xc0, 0x15, 0x38, 0x08, 0x8a, 0x4b, 0xea,
+	0xb4, 0x4a, 0xbb, 0x62, 0x37, 0x33, 0x1e, 0xff, 0xff, 0xbf, 0x71, 0x26, 0x30, 0x8c, 0x13, 0xa1,
+	0x84, 0x2f, 0x59, 0x14, 0x8a, 0x24, 0x54, 0xa9, 0x89, 0x46, 0xfa, 0x04, 0xdb, 0xab, 0x42, 0x6f,
+	0x30, 0x15, 0x62, 0xca, 0x99, 0x4f, 0xe3, 0xd0, 0xa7, 0x51, 0x24, 0x14, 0x55, 0xa1, 0x88, 0xe4,
+	0xb2, 0xd1, 0x7d, 0x45, 0xd0, 0x1e, 0xe7, 0xbd, 0x01, 0x7b, 0x9a, 0x33, 0xa9, 0x30, 0x86, 0xaa,
+	0xe4, 0xe2, 0x99, 0x20, 0x07, 0x79, 0xff, 0x03, 0x1d, 0xe3, 0x2e, 0xd4, 0xb9, 0x98, 0x50, 0xce,
+	0x88, 0xe5, 0x20, 0xcf, 0x0e, 0x7e, 0xb3, 0xac, 0x57, 0xd1, 0xa9, 0x24, 0x15, 0xa7, 0xe2, 0xd9,
+	0x81, 0x8e, 0xf5, 0x7d, 0xc6, 0x1e, 0x49, 0xd5, 0x41, 0x5e, 0x25, 0xd0, 0x31, 0x26, 0xf0, 0x8f,
+	0x53, 0xc5, 0xa2, 0x49, 0x4a, 0x6a, 0x5a, 0x20, 0x4f, 0xdd, 0x11, 0x34, 0x0b, 0x04, 0x31, 0x4f,
+	0xf1, 0x00, 0x0c, 0xbf, 0x86, 0xb0, 0x03, 0x53, 0x70, 0xdf, 0x11, 0xe0, 0xfc, 0x42, 0xc8, 0xe4,
+	0x5f, 0x41, 0x1f, 0x40, 0x6d, 0x22, 0xe6, 0x91, 0xd2, 0xd4, 0xb5, 0x60, 0x99, 0xec, 0xc0, 0xbe,
+	0x86, 0xf6, 0x1a, 0x45, 0x06, 0xee, 0x40, 0x43, 0x9a, 0x1a, 0x41, 0x5a, 0xbe, 0x58, 0x72, 0x5f,
+	0xa0, 0xbb, 0x1a, 0x76, 0xac, 0x12, 0x46, 0x67, 0x39, 0xbf, 0x61, 0x45, 0xa5, 0xac, 0x56, 0x81,
+	0x75, 0x08, 0x8d, 0x30, 0x52, 0x2c, 0x59, 0x50, 0x7e, 0x3f, 0xcb, 0xc6, 0xc8, 0x88, 0x21, 0x2f,
+	0xdd, 0x6e, 0x19, 0xe6, 0xf2, 0xd3, 0x02, 0x7b, 0xe5, 0x8e, 0xef, 0x60, 0xef, 0x86, 0x29, 0x93,
+	0xf7, 0x47, 0x66, 0x8b, 0x36, 0x57, 0xa2, 0x77, 0x5c, 0x7e, 0x18, 0xf3, 0xd4, 0x3d, 0x7c, 0xfb,
+	0xfa, 0xfe, 0xb0, 0x5a, 0x78, 0xdf, 0x5f, 0x5c, 0x98, 0x45, 0xc4, 0x0c, 0x9a, 0x05, 0xf9, 0x90,
+	0x49, 0x7c, 0x52, 0xa2, 0x61, 0x3e, 0x60, 0xaf, 0xbf, 0xed, 0x38, 0x33, 0x39, 0xd2, 0x26, 0x1d,
+	0xdc, 0x2a, 0x9a, 0x64, 0xa2, 0x12, 0x3a, 0xcb, 0x77, 0x2c, 0x3a, 0x9d, 0x95, 0xd1, 0xae, 0x3d,
+	0xf7, 0xae, 0x81, 0x4e, 0xb5, 0x17, 0xc1, 0xdd, 0x0d, 0x2f, 0x5f, 0x6a, 0x85, 0x73, 0xf4, 0x50,
+	0xd7, 0x3f, 0xcf, 0xd5, 0xcf, 0x00, 0xc3, 0x9e, 0x71, 0xce, 0x88, 0x03, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -135,6 +366,8 @@ const _ = grpc.SupportPackageIsVersion4
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type SeniorityClient interface {
 	GetSeniority(ctx context.Context, in *SeniorityRequest, opts ...grpc.CallOption) (*SeniorityReply, error)
+	GetSeniorities(ctx context.Context, in *SenioritiesRequest, opts ...grpc.CallOption) (*SenioritiesReply, error)
+	StreamSeniorities(ctx context.Context, in *SeniorityStreamRequest, opts ...grpc.CallOption) (Seniority_StreamSenioritiesClient, error)
 }
 
 type seniorityClient struct {
@@ -154,9 +387,52 @@ func (c *seniorityClient) GetSeniority(ctx context.Context, in *SeniorityRequest
 	return out, nil
 }
 
+func (c *seniorityClient) GetSeniorities(ctx context.Context, in *SenioritiesRequest, opts ...grpc.CallOption) (*SenioritiesReply, error) {
+	out := new(SenioritiesReply)
+	err := c.cc.Invoke(ctx, "/seniority.Seniority/GetSeniorities", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *seniorityClient) StreamSeniorities(ctx context.Context, in *SeniorityStreamRequest, opts ...grpc.CallOption) (Seniority_StreamSenioritiesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Seniority_serviceDesc.Streams[0], "/seniority.Seniority/StreamSeniorities", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &seniorityStreamSenioritiesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Seniority_StreamSenioritiesClient interface {
+	Recv() (*SeniorityReply, error)
+	grpc.ClientStream
+}
+
+type seniorityStreamSenioritiesClient struct {
+	grpc.ClientStream
+}
+
+func (x *seniorityStreamSenioritiesClient) Recv() (*SeniorityReply, error) {
+	m := new(SeniorityReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // SeniorityServer is the server API for Seniority service.
 type SeniorityServer interface {
 	GetSeniority(context.Context, *SeniorityRequest) (*SeniorityReply, error)
+	GetSeniorities(context.Context, *SenioritiesRequest) (*SenioritiesReply, error)
+	StreamSeniorities(*SeniorityStreamRequest, Seniority_StreamSenioritiesServer) error
 }
 
 // UnimplementedSeniorityServer can be embedded to have forward compatible implementations.
@@ -166,6 +442,12 @@ type UnimplementedSeniorityServer struct {
 func (*UnimplementedSeniorityServer) GetSeniority(ctx context.Context, req *SeniorityRequest) (*SeniorityReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetSeniority not implemented")
 }
+func (*UnimplementedSeniorityServer) GetSeniorities(ctx context.Context, req *SenioritiesRequest) (*SenioritiesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSeniorities not implemented")
+}
+func (*UnimplementedSeniorityServer) StreamSeniorities(req *SeniorityStreamRequest, srv Seniority_StreamSenioritiesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamSeniorities not implemented")
+}
 
 func RegisterSeniorityServer(s *grpc.Server, srv SeniorityServer) {
 	s.RegisterService(&_Seniority_serviceDesc, srv)
@@ -189,6 +471,45 @@ func _Seniority_GetSeniority_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Seniority_GetSeniorities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SenioritiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SeniorityServer).GetSeniorities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/seniority.Seniority/GetSeniorities",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SeniorityServer).GetSeniorities(ctx, req.(*SenioritiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Seniority_StreamSeniorities_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SeniorityStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SeniorityServer).StreamSeniorities(m, &seniorityStreamSenioritiesServer{stream})
+}
+
+type Seniority_StreamSenioritiesServer interface {
+	Send(*SeniorityReply) error
+	grpc.ServerStream
+}
+
+type seniorityStreamSenioritiesServer struct {
+	grpc.ServerStream
+}
+
+func (x *seniorityStreamSenioritiesServer) Send(m *SeniorityReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Seniority_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "seniority.Seniority",
 	HandlerType: (*SeniorityServer)(nil),
@@ -197,7 +518,17 @@ var _Seniority_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetSeniority",
 			Handler:    _Seniority_GetSeniority_Handler,
 		},
+		{
+			MethodName: "GetSeniorities",
+			Handler:    _Seniority_GetSeniorities_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamSeniorities",
+			Handler:       _Seniority_StreamSeniorities_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/seniority/seniority.proto",
 }
@@ -0,0 +1,431 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: proto/title/title.proto
+
+package title
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type TitleRequest struct {
+	Slow   bool     `protobuf:"varint,1,opt,name=slow,proto3" json:"slow,omitempty"`
+	Locale string   `protobuf:"bytes,2,opt,name=locale,proto3" json:"locale,omitempty"`
+	Tags   []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Seed for the word selection. 0 selects random words.
+	Seed int64 `protobuf:"varint,4,opt,name=seed,proto3" json:"seed,omitempty"`
+	// Latency profile spec, e.g. "pareto:20ms,1.5". Overrides the service
+	// default profile which is otherwise only applied to slow requests.
+	Latency              string   `protobuf:"bytes,5,opt,name=latency,proto3" json:"latency,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TitleRequest) Reset()         { *m = TitleRequest{} }
+func (m *TitleRequest) String() string { return proto.CompactTextString(m) }
+func (*TitleRequest) ProtoMessage()    {}
+func (*TitleRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb61ae0ec380958d, []int{0}
+}
+
+func (m *TitleRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TitleRequest.Unmarshal(m, b)
+}
+func (m *TitleRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TitleRequest.Marshal(b, m, deterministic)
+}
+func (m *TitleRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TitleRequest.Merge(m, src)
+}
+func (m *TitleRequest) XXX_Size() int {
+	return xxx_messageInfo_TitleRequest.Size(m)
+}
+func (m *TitleRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TitleRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TitleRequest proto.InternalMessageInfo
+
+func (m *TitleRequest) GetSlow() bool {
+	if m != nil {
+		return m.Slow
+	}
+	return false
+}
+
+func (m *TitleRequest) GetLocale() string {
+	if m != nil {
+		return m.Locale
+	}
+	return ""
+}
+
+func (m *TitleRequest) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *TitleRequest) GetSeed() int64 {
+	if m != nil {
+		return m.Seed
+	}
+	return 0
+}
+
+func (m *TitleRequest) GetLatency() string {
+	if m != nil {
+		return m.Latency
+	}
+	return ""
+}
+
+type TitleReply struct {
+	Seniority            string   `protobuf:"bytes,1,opt,name=seniority,proto3" json:"seniority,omitempty"`
+	Field                string   `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"`
+	Role                 string   `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"`
+	Locale               string   `protobuf:"bytes,4,opt,name=locale,proto3" json:"locale,omitempty"`
+	Title                string   `protobuf:"bytes,5,opt,name=title,proto3" json:"title,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TitleReply) Reset()         { *m = TitleReply{} }
+func (m *TitleReply) String() string { return proto.CompactTextString(m) }
+func (*TitleReply) ProtoMessage()    {}
+func (*TitleReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb61ae0ec380958d, []int{1}
+}
+
+func (m *TitleReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TitleReply.Unmarshal(m, b)
+}
+func (m *TitleReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TitleReply.Marshal(b, m, deterministic)
+}
+func (m *TitleReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TitleReply.Merge(m, src)
+}
+func (m *TitleReply) XXX_Size() int {
+	return xxx_messageInfo_TitleReply.Size(m)
+}
+func (m *TitleReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_TitleReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TitleReply proto.InternalMessageInfo
+
+func (m *TitleReply) GetSeniority() string {
+	if m != nil {
+		return m.Seniority
+	}
+	return ""
+}
+
+func (m *TitleReply) GetField() string {
+	if m != nil {
+		return m.Field
+	}
+	return ""
+}
+
+func (m *TitleReply) GetRole() string {
+	if m != nil {
+		return m.Role
+	}
+	return ""
+}
+
+func (m *TitleReply) GetLocale() string {
+	if m != nil {
+		return m.Locale
+	}
+	return ""
+}
+
+func (m *TitleReply) GetTitle() string {
+	if m != nil {
+		return m.Title
+	}
+	return ""
+}
+
+type TitlesRequest struct {
+	Slow   bool     `protobuf:"varint,1,opt,name=slow,proto3" json:"slow,omitempty"`
+	Locale string   `protobuf:"bytes,2,opt,name=locale,proto3" json:"locale,omitempty"`
+	Tags   []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	Count  int32    `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	// Latency profile spec, e.g. "pareto:20ms,1.5". Overrides the service
+	// default profile which is otherwise only applied to slow requests.
+	Latency              string   `protobuf:"bytes,5,opt,name=latency,proto3" json:"latency,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TitlesRequest) Reset()         { *m = TitlesRequest{} }
+func (m *TitlesRequest) String() string { return proto.CompactTextString(m) }
+func (*TitlesRequest) ProtoMessage()    {}
+func (*TitlesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb61ae0ec380958d, []int{2}
+}
+
+func (m *TitlesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TitlesRequest.Unmarshal(m, b)
+}
+func (m *TitlesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TitlesRequest.Marshal(b, m, deterministic)
+}
+func (m *TitlesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TitlesRequest.Merge(m, src)
+}
+func (m *TitlesRequest) XXX_Size() int {
+	return xxx_messageInfo_TitlesRequest.Size(m)
+}
+func (m *TitlesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TitlesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TitlesRequest proto.InternalMessageInfo
+
+func (m *TitlesRequest) GetSlow() bool {
+	if m != nil {
+		return m.Slow
+	}
+	return false
+}
+
+func (m *TitlesRequest) GetLocale() string {
+	if m != nil {
+		return m.Locale
+	}
+	return ""
+}
+
+func (m *TitlesRequest) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *TitlesRequest) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *TitlesRequest) GetLatency() string {
+	if m != nil {
+		return m.Latency
+	}
+	return ""
+}
+
+type TitlesReply struct {
+	Titles               []*TitleReply `protobuf:"bytes,1,rep,name=titles,proto3" json:"titles,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *TitlesReply) Reset()         { *m = TitlesReply{} }
+func (m *TitlesReply) String() string { return proto.CompactTextString(m) }
+func (*TitlesReply) ProtoMessage()    {}
+func (*TitlesReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb61ae0ec380958d, []int{3}
+}
+
+func (m *TitlesReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TitlesReply.Unmarshal(m, b)
+}
+func (m *TitlesReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TitlesReply.Marshal(b, m, deterministic)
+}
+func (m *TitlesReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TitlesReply.Merge(m, src)
+}
+func (m *TitlesReply) XXX_Size() int {
+	return xxx_messageInfo_TitlesReply.Size(m)
+}
+func (m *TitlesReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_TitlesReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TitlesReply proto.InternalMessageInfo
+
+func (m *TitlesReply) GetTitles() []*TitleReply {
+	if m != nil {
+		return m.Titles
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*TitleRequest)(nil), "title.TitleRequest")
+	proto.RegisterType((*TitleReply)(nil), "title.TitleReply")
+	proto.RegisterType((*TitlesRequest)(nil), "title.TitlesRequest")
+	proto.RegisterType((*TitlesReply)(nil), "title.TitlesReply")
+}
+
+func init() { proto.RegisterFile("proto/title/title.proto", fileDescriptor_cb61ae0ec380958d) }
+
+var fileDescriptor_cb61ae0ec380958d = []byte{
+	// 306 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x52, 0x41, 0x4b, 0xf3, 0x40,
+	0x10, 0xfd, 0xf6, 0x4b, 0x13, 0xcd, 0xd4, 0x0a, 0x8e, 0x41, 0x97, 0xe2, 0x21, 0xe4, 0x14, 0x2f,
+	0x15, 0xea, 0x45, 0xc1, 0xbb, 0xf7, 0xd5, 0x3f, 0x10, 0xd3, 0x51, 0x02, 0x4b, 0xb6, 0x66, 0xb7,
+	0x4a, 0x40, 0x10, 0xbc, 0xf8, 0xb7, 0x25, 0x93, 0x0d, 0x6d, 0x14, 0x3c, 0x79, 0x09, 0xef, 0x3d,
+	0xe6, 0xed, 0xbc, 0x7d, 0x1b, 0x38, 0x5d, 0x37, 0xc6, 0x99, 0x0b, 0x57, 0x39, 0x4d, 0xfd, 0x77,
+	0xc1, 0x0a, 0x86, 0x4c, 0xb2, 0x37, 0x38, 0xb8, 0xef, 0x80, 0xa2, 0xe7, 0x0d, 0x59, 0x87, 0x08,
+	0x13, 0xab, 0xcd, 0xab, 0x14, 0xa9, 0xc8, 0xf7, 0x15, 0x63, 0x3c, 0x81, 0x48, 0x9b, 0xb2, 0xd0,
+	0x24, 0xff, 0xa7, 0x22, 0x8f, 0x95, 0x67, 0xdd, 0xac, 0x2b, 0x9e, 0xac, 0x0c, 0xd2, 0x20, 0x8f,
+	0x15, 0x63, 0xf6, 0x13, 0xad, 0xe4, 0x24, 0x15, 0x79, 0xa0, 0x18, 0xa3, 0x84, 0x3d, 0x5d, 0x38,
+	0xaa, 0xcb, 0x56, 0x86, 0x7c, 0xc0, 0x40, 0xb3, 0x0f, 0x01, 0xe0, 0xd7, 0xaf, 0x75, 0x8b, 0x67,
+	0x10, 0x5b, 0xaa, 0x2b, 0xd3, 0x54, 0xae, 0xe5, 0x04, 0xb1, 0xda, 0x0a, 0x98, 0x40, 0xf8, 0x58,
+	0x91, 0x5e, 0xf9, 0x14, 0x3d, 0xe9, 0x16, 0x36, 0x46, 0x93, 0x0c, 0x58, 0x64, 0xbc, 0x13, 0x78,
+	0x32, 0x0a, 0x9c, 0x40, 0x7f, 0x6b, 0x1f, 0xc3, 0x57, 0xf0, 0x0e, 0x33, 0xce, 0x60, 0xff, 0xaa,
+	0x83, 0x04, 0xc2, 0xd2, 0x6c, 0x6a, 0xc7, 0xdb, 0x43, 0xd5, 0x93, 0x5f, 0x5a, 0xb8, 0x82, 0xe9,
+	0x10, 0xa0, 0x6b, 0xe1, 0x1c, 0x22, 0x0e, 0x66, 0xa5, 0x48, 0x83, 0x7c, 0xba, 0x3c, 0x5a, 0xf4,
+	0xef, 0xb6, 0x2d, 0x4a, 0xf9, 0x81, 0xe5, 0xa7, 0xf0, 0xcf, 0x77, 0x47, 0xcd, 0x4b, 0x55, 0x12,
+	0x5e, 0xc3, 0xec, 0x96, 0x6a, 0x6a, 0x0a, 0x47, 0xac, 0xe3, 0xf1, 0xd8, 0xcc, 0x17, 0x9c, 0xff,
+	0x3c, 0x31, 0xfb, 0x87, 0x37, 0x70, 0x38, 0xb2, 0x5a, 0x4c, 0x76, 0xc7, 0x86, 0x76, 0xe6, 0xf8,
+	0x4d, 0x65, 0xf7, 0x43, 0xc4, 0x7f, 0xd5, 0xe5, 0xd7, 0x00, 0x13, 0x7c, 0xcf, 0x4c, 0x70, 0x02,
+	0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// TitleServiceClient is the client API for TitleService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TitleServiceClient interface {
+	GenerateTitle(ctx context.Context, in *TitleRequest, opts ...grpc.CallOption) (*TitleReply, error)
+	GenerateTitles(ctx context.Context, in *TitlesRequest, opts ...grpc.CallOption) (*TitlesReply, error)
+}
+
+type titleServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTitleServiceClient(cc *grpc.ClientConn) TitleServiceClient {
+	return &titleServiceClient{cc}
+}
+
+func (c *titleServiceClient) GenerateTitle(ctx context.Context, in *TitleRequest, opts ...grpc.CallOption) (*TitleReply, error) {
+	out := new(TitleReply)
+	err := c.cc.Invoke(ctx, "/title.TitleService/GenerateTitle", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *titleServiceClient) GenerateTitles(ctx context.Context, in *TitlesRequest, opts ...grpc.CallOption) (*TitlesReply, error) {
+	out := new(TitlesReply)
+	err := c.cc.Invoke(ctx, "/title.TitleService/GenerateTitles", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TitleServiceServer is the server API for TitleService service.
+type TitleServiceServer interface {
+	GenerateTitle(context.Context, *TitleRequest) (*TitleReply, error)
+	GenerateTitles(context.Context, *TitlesRequest) (*TitlesReply, error)
+}
+
+// UnimplementedTitleServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedTitleServiceServer struct {
+}
+
+func (*UnimplementedTitleServiceServer) GenerateTitle(ctx context.Context, req *TitleRequest) (*TitleReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenerateTitle not implemented")
+}
+func (*UnimplementedTitleServiceServer) GenerateTitles(ctx context.Context, req *TitlesRequest) (*TitlesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenerateTitles not implemented")
+}
+
+func RegisterTitleServiceServer(s *grpc.Server, srv TitleServiceServer) {
+	s.RegisterService(&_TitleService_serviceDesc, srv)
+}
+
+func _TitleService_GenerateTitle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TitleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TitleServiceServer).GenerateTitle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/title.TitleService/GenerateTitle",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TitleServiceServer).GenerateTitle(ctx, req.(*TitleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TitleService_GenerateTitles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TitlesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TitleServiceServer).GenerateTitles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/title.TitleService/GenerateTitles",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TitleServiceServer).GenerateTitles(ctx, req.(*TitlesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _TitleService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "title.TitleService",
+	HandlerType: (*TitleServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GenerateTitle",
+			Handler:    _TitleService_GenerateTitle_Handler,
+		},
+		{
+			MethodName: "GenerateTitles",
+			Handler:    _TitleService_GenerateTitles_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/title/title.proto",
+}
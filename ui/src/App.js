@@ -11,6 +11,11 @@ class Display extends React.Component {
       );
     } else if (!this.props.data) {
       return (<h1>&nbsp;</h1>);
+    } else if (this.props.data.title) {
+      // The title is assembled by the backend according to the locale.
+      return (
+        <h1 lang={this.props.data.locale}>{this.props.data.title}</h1>
+      );
     } else {
       // Capitalize first letter of each word.
       const seniority = this.props.data.seniority.charAt(0).toUpperCase()
@@ -61,13 +66,37 @@ class MyApp extends React.Component {
     this.handler = this.handler.bind(this)
   }
 
+  componentDidMount() {
+    // Prefer the WebSocket API and fall back to plain HTTP requests if the
+    // connection can't be established.
+    this.ws = new WebSocket("ws://localhost:8080/ws");
+    this.ws.onmessage = (event) => {
+      const msg = JSON.parse(event.data);
+      if (msg.type === "title") {
+        this.setState({ isLoaded: true, data: msg.title, traceId: msg.trace_id });
+      } else {
+        this.setState({ isLoaded: true, error: new Error(msg.error) });
+      }
+    };
+  }
+
+  componentWillUnmount() {
+    this.ws.close();
+  }
+
   handler(slow) {
+    this.setState({ isLoaded: false });
+
+    if (this.ws && this.ws.readyState === WebSocket.OPEN) {
+      this.ws.send(JSON.stringify({ type: "generate", slow: !!slow }));
+      return;
+    }
+
     let url = "http://localhost:8080/api"
     if (slow) {
       url += "?slow=true"
     }
 
-    this.setState({ isLoaded: false });
     fetch(url)
       .then(res => res.json())
       .then(
@@ -87,7 +116,7 @@ class MyApp extends React.Component {
   }
 
   render() {
-    const { error, isLoaded, data } = this.state;
+    const { error, isLoaded, data, traceId } = this.state;
     if (error) {
       return <div>Error: {error.message}</div>;
     } else {
@@ -96,6 +125,9 @@ class MyApp extends React.Component {
           <div className="display-container">
             <Display data={data} isLoaded={isLoaded} />
           </div>
+          <div className="trace-container my-1">
+            <small className="text-muted">{traceId ? "Trace ID: " + traceId : "\u00a0"}</small>
+          </div>
           <div className="button-container my-1">
             <Button handler={this.handler} />
           </div>